@@ -52,6 +52,14 @@ module AI
         x, y, c, s = next_line
         log "  - (#{x}, #{y}) : #{c} (seen=#{s})"
       end
+
+      n = next_line.first
+      log " #{n} facts on the blackboard"
+
+      n.times do
+        ai, key, value = $stdin.readline.chomp.split(" ", 3)
+        log "  - #{key} = #{value} (from AI #{ai})"
+      end
     end
 
     # play a turn
@@ -37,6 +37,14 @@ turn() {
     done
   fi
 
+  # Blackboard
+  read N
+  if [ x"$N" != x0 ]; then
+    for i in $(seq $N); do
+      read
+    done
+  fi
+
   # get a random number in (0,1,2,3)
   r=${RANDOM}
   let "r %= 4"
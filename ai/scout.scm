@@ -66,6 +66,18 @@
                                                          (li c s) ) )
                                                  MAP ) ) ) ) ) )
 
+;; Read the header line containing [B] and then the [B] facts of the
+;; blackboard, filling [BLACKBOARD] with (key value AI) lists.
+(define (read-blackboard)
+  (let ((b (string->number (read-line) ) ))
+    ( set! BLACKBOARD '() ) ;; reset BLACKBOARD
+    ( do-times
+      _ b (match-let (( (ai key . value) (input-line) ))
+                     (set! BLACKBOARD
+                       (cons (li key (string-intersperse value " ")
+                                 (string->number ai) )
+                             BLACKBOARD) ) ) ) ) )
+
 ;; Helpers to test a cell content
 (define (rock? c) (= 2 c))
 (define (water? c) (= 4 c))
@@ -125,5 +137,6 @@
             (read-ants)
             (read-enemies)
             (read-map)
+            (read-blackboard)
             (print (choose-move ANT-ID))
             )
@@ -1,50 +1,169 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/bfontaine/antroid/api"
+	"github.com/bfontaine/antroid/tui"
+	"github.com/bfontaine/antroid/web"
 	"gopkg.in/alecthomas/kingpin.v1"
+	"net"
+	"net/http"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 )
 
-// exitErr logs an error and exit
+// exitErr logs an error and exit. All the problems of an invalid game spec
+// are printed on their own line. With `--output json` the error is printed as
+// a JSON object on stdout.
 func exitErr(e error) {
+	if *outFmt == jsonOutput {
+		printError(e)
+		os.Exit(1)
+	}
+
+	if errs, ok := e.(api.SpecErrors); ok {
+		fmt.Fprintln(os.Stderr, "Error: invalid game spec:")
+		for _, se := range errs {
+			fmt.Fprintf(os.Stderr, "  - %v\n", se)
+		}
+		os.Exit(1)
+	}
+
 	fmt.Fprintf(os.Stderr, "Error: %v\n", e)
 	os.Exit(1)
 }
 
-// gameServer starts a local game server
-func gameServer(login, password string, ais []string, listeners []string,
-	gs api.GameSpec, debug bool) {
+// serverOptions are the options of the local game server
+type serverOptions struct {
+	// the AIs and plugin listeners commands. AIs can also be remote
+	// endpoints.
+	ais, listeners []string
+	// if not empty, wait for `wait` AIs to connect on this endpoint
+	listen string
+	wait   int
+	// if not nil, this actor is added before the AIs and takes precedence
+	// over them, e.g. the manual mode of `play --interactive`
+	manual api.ActorInterface
+	// if not empty, join this game instead of creating a new one
+	join api.GameID
+	// if not nil, look for an open game to join before creating one
+	autoJoin *api.AutoJoinOptions
+	// the journal file, if any
+	journal string
+	// if true, show the game in the terminal
+	tui bool
+	// if not empty, serve a web viewer on this address
+	http string
+
+	debug bool
+}
+
+// gameServer starts a local game server and plays a game until its end
+func gameServer(login, password string, gs api.GameSpec, opts serverOptions) error {
 
 	// create the server
 	p := api.NewPlayer(login, password)
 
-	p.SetDebug(debug)
+	// stop the AIs and log out even if we fail
+	defer p.Quit()
+
+	p.SetDebug(opts.debug)
+
+	// record the game in a journal
+	if opts.journal != "" {
+		f, err := os.Create(opts.journal)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		p.SetJournal(f)
+	}
+
+	// load the manual mode before the AIs
+	if opts.manual != nil {
+		p.AIs.AddActor(opts.manual)
+	}
 
 	// load the AIs
-	for _, ai := range ais {
-		words := strings.Split(ai, " ")
-		p.AIs.AddAI(words[0], words[1:]...)
+	if err := p.AIs.Load(opts.ais...); err != nil {
+		return err
+	}
+
+	// wait for the remote AIs
+	if opts.listen != "" {
+		l, err := api.Listen(opts.listen)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Waiting for %d AI(s) on %s...\n", opts.wait, l.Addr())
+
+		err = p.AIs.Accept(l, opts.wait, func(ai *api.RemoteAI) {
+			fmt.Printf("AI connected from %s\n", ai)
+		})
+		l.Close()
+
+		if err != nil {
+			return err
+		}
 	}
 
 	// load the plugin listeners
-	for _, l := range listeners {
+	for _, l := range opts.listeners {
 		words := strings.Split(l, " ")
 		p.Listeners.AddListener(words[0], words[1:]...)
 	}
 
+	// load the built-in listeners
+	if opts.tui {
+		p.Listeners.AddObserver(tui.New(os.Stdout))
+	}
+
+	if opts.http != "" {
+		l, err := net.Listen("tcp", opts.http)
+		if err != nil {
+			return err
+		}
+
+		v := web.NewViewer()
+		p.Listeners.AddObserver(v)
+
+		go http.Serve(l, v)
+
+		fmt.Printf("Web viewer on http://%s/\n", l.Addr())
+	}
+
 	// connect to the remote server
 	if err := p.Connect(); err != nil {
-		fmt.Printf("%s\n", err)
-		return
+		return err
 	}
 
-	// create a game
-	if err := p.CreateAndJoinGame(&gs); err != nil {
-		fmt.Printf("%s\n", err)
-		return
+	// join or create a game
+	if opts.join != "" {
+		if err := p.JoinGame(opts.join); err != nil {
+			return err
+		}
+	} else if opts.autoJoin != nil {
+		fmt.Printf("Looking for a game with %s...\n", opts.autoJoin.Constraints)
+
+		created, err := p.AutoJoinGame(*opts.autoJoin, &gs)
+		if err != nil {
+			return err
+		}
+
+		if created {
+			fmt.Printf("No open game with %s after %s, created game %s\n",
+				opts.autoJoin.Constraints, opts.autoJoin.Timeout, p.GameID())
+		} else {
+			fmt.Printf("Joined game %s\n", p.GameID())
+		}
+	} else if err := p.CreateAndJoinGame(&gs); err != nil {
+		return err
 	}
 
 	var err error
@@ -54,14 +173,15 @@ func gameServer(login, password string, ais []string, listeners []string,
 	// game loop
 	for !done {
 		if done, err = p.PlayTurn(); err != nil {
-			fmt.Printf("%s\n", err)
-			return
+			return err
 		}
 	}
 
 	fmt.Println("End of game.")
 	fmt.Println("Scores:")
 	p.PrintScores()
+
+	return nil
 }
 
 var (
@@ -69,44 +189,132 @@ var (
 
 	// global flags
 	debug    = app.Flag("debug", "Enable debug mode.").Bool()
-	login    = app.Flag("login", "Login.").Default("ww").String()
-	password = app.Flag("password", "Password.").Default("a").String()
+	login    = app.Flag("login", "Login (default: $ANTROID_LOGIN).").String()
+	password = app.Flag("password", "Password (default: $ANTROID_PASSWORD).").String()
+	credFile = app.Flag("credentials", "Credentials file (JSON).").Default(configPath("credentials.json")).String()
+	baseURL  = app.Flag("url", "Base URL of the remote server.").String()
+	outFmt   = app.Flag("output", "Output format of API commands: text, table or json.").
+			Default(textOutput).Enum(textOutput, tableOutput, jsonOutput)
 
 	// subcommands
-	apiCmd     = app.Command("api", "Show all remote API methods.")
+	apiCmd     = app.Command("api", "Show the documentation of the remote API methods.")
+	callCmd    = app.Command("call", "Call any method of the remote API.")
 	whoCmd     = app.Command("whoami", "Show the logged user's name.")
+	loginCmd   = app.Command("login", "Log in and keep the session for the next commands.")
+	logoutCmd  = app.Command("logout", "Log out of the kept session.")
+	regCmd     = app.Command("register", "Create an account.")
 	gamesCmd   = app.Command("games", "Show all visible games.")
 	createCmd  = app.Command("create", "Create a new game.")
 	statusCmd  = app.Command("status", "Get a game status.")
+	watchCmd   = app.Command("watch", "Follow a game's status and scores until it's over.")
 	destroyCmd = app.Command("destroy", "Destroy a game.")
 	joinCmd    = app.Command("join", "Join a game.")
 	playCmd    = app.Command("play", "Play a turn in a game.")
 	serverCmd  = app.Command("server", "Start the local game server.")
-
-	// play/server flags
-	gameDesc = app.Flag("description", "Game description.").Default("a test").String()
-	pace     = app.Flag("pace", "Game pace.").Default("1").Int()
-	turns    = app.Flag("turns", "Number of turns.").Default("10").Int()
-	ants     = app.Flag("ants", "Number of ants per player.").Default("1").Int()
-	maxP     = app.Flag("max-players", "Max number of players.").Default("1").Int()
-	minP     = app.Flag("min-players", "Min number of players.").Default("1").Int()
-	energy   = app.Flag("energy", "Initial energy.").Default("100").Int()
-	acid     = app.Flag("acid", "Initial acid.").Default("100").Int()
+	renderCmd  = app.Command("render", "Draw a game as a PNG, SVG or animated GIF image.")
+	matchCmd   = app.Command("match", "Play a private game between several accounts.")
+	tourCmd    = app.Command("tournament", "Run a tournament between AIs.")
+	envCmd     = app.Command("env", "Expose a learning environment on stdin and stdout.")
+	profCmd    = app.Command("profiles", "Show all game spec profiles.")
+	tuneCmd    = app.Command("tune", "Search the AIs' parameters which give the best scores.")
+
+	// play/server flags. They override the ones of the profile.
+	profile  = app.Flag("profile", "Game spec profile.").Default(api.DefaultProfile).String()
+	profFile = app.Flag("profiles", "Game spec profiles file (JSON).").Default(configPath("profiles.json")).String()
+	gameDesc = app.Flag("description", "Game description.").String()
+	pace     = app.Flag("pace", "Game pace.").Int()
+	turns    = app.Flag("turns", "Number of turns.").Int()
+	ants     = app.Flag("ants", "Number of ants per player.").Int()
+	maxP     = app.Flag("max-players", "Max number of players.").Int()
+	minP     = app.Flag("min-players", "Min number of players.").Int()
+	energy   = app.Flag("energy", "Initial energy.").Int()
+	acid     = app.Flag("acid", "Initial acid.").Int()
 	players  = app.Flag("player", "Restrict games to this player "+
 		"(can be used multiple times).").Strings()
 
 	// subcommands args
+	apiMethod = apiCmd.Arg("method", "Show only this method.").String()
+	callName  = callCmd.Arg("method", "Method to call, e.g. status.").Required().String()
+	callArgs  = callCmd.Arg("params", "Its parameters, as key=value.").Strings()
 	statusID  = statusCmd.Arg("id", "game ID").Required().String()
+	watchID   = watchCmd.Arg("id", "game ID").Required().String()
 	destroyID = destroyCmd.Arg("id", "game ID").Required().String()
 	joinID    = joinCmd.Arg("id", "game ID").Required().String()
 	playID    = playCmd.Arg("id", "game ID").Required().String()
-	playCmds  = playCmd.Arg("commands", "Commands to use for this turn.").Required().Strings()
-	serverAIs = serverCmd.Arg("ais", "AIs to use for this game.").Required().Strings()
+	playCmds  = playCmd.Arg("commands", "Commands to use for this turn.").Strings()
+	serverAIs = serverCmd.Arg("ais", "AIs to use for this game: commands or "+
+		"tcp://, unix:// and ws:// endpoints.").Strings()
+	matchPlayers = matchCmd.Arg("players", "Players, as "+
+		"LOGIN[:PASSWORD]=AI[;AI...].").Required().Strings()
+	tourConfig = tourCmd.Arg("config", "Tournament configuration (JSON).").Required().String()
+	tuneConfig = tuneCmd.Arg("config", "Tuning configuration (JSON).").Required().String()
+
+	envWidth  = envCmd.Flag("width", "Width of the observations' planes.").Default("64").Int()
+	envHeight = envCmd.Flag("height", "Height of the observations' planes.").Default("64").Int()
+
+	tuneBest = tuneCmd.Flag("best", "Number of best settings to print.").Default("5").Int()
 
 	// subcommands flags
+	apiCheck = apiCmd.Flag("check", "Compare the remote API with this client and "+
+		"warn about their differences.").Bool()
+
+	gamesMine  = gamesCmd.Flag("mine", "Show only the games we created.").Bool()
+	gamesVis   = gamesCmd.Flag("visibility", "Show only the public or the private games.").Enum(api.PublicGames, api.PrivateGames)
+	gamesOpen  = gamesCmd.Flag("open", "Show only the games players can still join.").Bool()
+	gamesState = gamesCmd.Flag("state", "Show only the games with this status, e.g. playing.").String()
+	gamesAfter = gamesCmd.Flag("after", "Show only the games created after this date, "+
+		"e.g. 2015-03-04.").String()
+	gamesSort = gamesCmd.Flag("sort", "Sort the games by id, created, creator, "+
+		"teaser, status, turn or players.").String()
+	gamesReverse = gamesCmd.Flag("reverse", "Reverse the order of the games.").Bool()
+	gamesStatus  = gamesCmd.Flag("status", "Show the status of each game: its turn, "+
+		"players and scores.").Bool()
+	gamesParallel = gamesCmd.Flag("parallel", "Number of statuses to get at the "+
+		"same time.").Default("4").Int()
+
+	watchEvery = watchCmd.Flag("interval", "Interval between two polls "+
+		"(default: from the game's pace).").Duration()
+	watchHook = watchCmd.Flag("hook", "Shell command to run each time the game's "+
+		"status changes.").String()
+
+	callVerb = callCmd.Flag("verb", "Call the method with this verb without "+
+		"checking its parameters, e.g. for undocumented methods.").String()
+
 	serverCreate = serverCmd.Flag("create", "Create a new game.").Bool()
 	serverGui    = serverCmd.Flag("gui", "Use a GUI.").String()
 	//serverJoin = serverCmd.Flag("join", "Join an existing game.").String()
+	serverJournal = serverCmd.Flag("journal", "Record each turn in this journal file.").String()
+	serverTui     = serverCmd.Flag("tui", "Show the game in the terminal.").Bool()
+	serverHTTP    = serverCmd.Flag("http", "Serve a web viewer on this address (e.g. :8080).").String()
+	serverListen  = serverCmd.Flag("listen", "Wait for AIs to connect on this "+
+		"tcp:// or unix:// endpoint.").String()
+	serverWait = serverCmd.Flag("wait", "Number of AIs to wait for with --listen.").Default("1").Int()
+	serverAuto = serverCmd.Flag("auto-join", "Join an open public game which fits "+
+		"--ants, --pace and --turns before creating one.").Bool()
+	serverAutoTimeout = serverCmd.Flag("auto-join-timeout", "How long to look for a "+
+		"game to join with --auto-join.").Default("30s").Duration()
+
+	playInteractive = playCmd.Flag("interactive", "Control the ants from the keyboard "+
+		"until the end of the game.").Short('i').Bool()
+	playAIs = playCmd.Flag("ai", "With --interactive, an AI playing the ants "+
+		"we don't control (can be used multiple times).").Strings()
+	playAnts = playCmd.Flag("ant", "With --interactive, control only this ant "+
+		"(can be used multiple times).").Strings()
+
+	renderJournalFile = renderCmd.Flag("journal", "Journal to read (default: stdin).").String()
+	renderTurn        = renderCmd.Flag("turn", "Turn to draw (default: the last one).").Int()
+	renderGame        = renderCmd.Flag("game", "Draw the log of this game instead of a journal.").String()
+	renderAs          = renderCmd.Flag("as", "With --game, the player whose ants are ours (default: us).").String()
+	renderLiveMode    = renderCmd.Flag("live", "Read the game server's messages on stdin "+
+		"and draw each turn (use it as a GUI).").Bool()
+	renderOutput   = renderCmd.Flag("file", "Image file.").Short('o').Required().String()
+	renderFormat   = renderCmd.Flag("format", "Image format, png or svg (default: from the filename).").String()
+	renderCellSize = renderCmd.Flag("cell-size", "Size of a cell, in pixels.").Default("8").Int()
+	renderOverlay  = renderCmd.Flag("overlay", "Write the turn number and the scoreboard.").Bool()
+	renderAnimate  = renderCmd.Flag("animate", "Draw the whole journal as an animated GIF.").Bool()
+	renderDelay    = renderCmd.Flag("delay", "Delay between two frames, in milliseconds (at least 10).").Default("200").Int()
+	renderFollow   = renderCmd.Flag("follow", "Follow the ant with this ID.").Default("-1").Int()
+	renderWindow   = renderCmd.Flag("window", "Number of cells shown around the followed ant.").Default("21").Int()
 )
 
 func main() {
@@ -114,122 +322,338 @@ func main() {
 
 	parsed := kingpin.MustParse(app.Parse(os.Args[1:]))
 
-	gs := api.GameSpec{
-		Description:   *gameDesc,
-		Pace:          *pace,
-		Turns:         *turns,
-		AntsPerPlayer: *ants,
-		MaxPlayers:    *maxP,
-		MinPlayers:    *minP,
-		InitialEnergy: *energy,
-		InitialAcid:   *acid,
+	if *baseURL != "" {
+		api.BaseURL = strings.TrimSuffix(*baseURL, "/")
 	}
 
-	// construct the game spec
-	if len(*players) == 0 {
-		gs.Public = true
-	} else {
-		gs.Players = *players
+	if parsed == profCmd.FullCommand() {
+		profiles, err := api.LoadProfiles(*profFile)
+		if err != nil {
+			exitErr(err)
+		}
+
+		printProfiles(profiles)
+		return
 	}
 
 	if parsed == serverCmd.FullCommand() {
-		if len(*serverAIs) == 0 {
+		gs := gameSpec()
+
+		// check the spec before we log in or start the AIs
+		if err := gs.Check(); err != nil {
+			exitErr(err)
+		}
+
+		if len(*serverAIs) == 0 && *serverListen == "" {
 			fmt.Fprintf(os.Stderr, "Expected at least one AI\n")
 			os.Exit(1)
 		}
 
-		var plugins []string
+		opts := serverOptions{
+			ais:     *serverAIs,
+			journal: *serverJournal,
+			tui:     *serverTui,
+			http:    *serverHTTP,
+			listen:  *serverListen,
+			wait:    *serverWait,
+			debug:   *debug,
+		}
 
 		if *serverGui != "" {
-			plugins = append(plugins, *serverGui)
+			opts.listeners = append(opts.listeners, *serverGui)
+		}
+
+		// only the flags are constraints, not the profile
+		if *serverAuto {
+			opts.autoJoin = &api.AutoJoinOptions{
+				Constraints: api.Constraints{
+					AntsPerPlayer: *ants,
+					Pace:          *pace,
+					Turns:         *turns,
+				},
+				Timeout:  *serverAutoTimeout,
+				Parallel: 4,
+			}
+		}
+
+		_, user := userCredentials()
+
+		if err := gameServer(user.Login, user.Password, gs, opts); err != nil {
+			exitErr(err)
+		}
+
+		// keep the viewer open after the end of the game
+		if opts.http != "" {
+			fmt.Println("The web viewer is still running, press Ctrl-C to quit.")
+			select {}
+		}
+
+		return
+	}
+
+	if parsed == playCmd.FullCommand() && *playInteractive {
+		var ants []int
+
+		for _, a := range *playAnts {
+			id, err := strconv.Atoi(a)
+			if err != nil {
+				exitErr(fmt.Errorf("bad ant ID %q", a))
+			}
+			ants = append(ants, id)
+		}
+
+		if len(*playAIs) > 0 && len(ants) == 0 {
+			fmt.Fprintf(os.Stderr, "Use --ant to choose the ants to control\n")
+			os.Exit(1)
+		}
+
+		opts := serverOptions{
+			ais:    *playAIs,
+			manual: tui.NewManual(os.Stdin, os.Stdout, ants),
+			join:   api.GameID(*playID),
+			debug:  *debug,
+		}
+
+		gs := gameSpec()
+		_, user := userCredentials()
+
+		if err := gameServer(user.Login, user.Password, gs, opts); err != nil {
+			exitErr(err)
+		}
+
+		return
+	}
+
+	if parsed == matchCmd.FullCommand() {
+		gs := gameSpec()
+		_, user := userCredentials()
+
+		if err := playMatch(*matchPlayers, user.Password, gs, *debug); err != nil {
+			exitErr(err)
+		}
+
+		return
+	}
+
+	if parsed == envCmd.FullCommand() {
+		gs := gameSpec()
+		_, user := userCredentials()
+
+		e := api.NewEnv(user.Login, user.Password, *envWidth, *envHeight)
+		e.SetDebug(*debug)
+
+		if err := serveEnv(e, gs, os.Stdin, os.Stdout); err != nil {
+			exitErr(err)
+		}
+
+		return
+	}
+
+	if parsed == tourCmd.FullCommand() {
+		gs := gameSpec()
+		_, user := userCredentials()
+
+		if err := runTournament(*tourConfig, user.Password, gs, *debug); err != nil {
+			exitErr(err)
 		}
 
-		gameServer(*login, *password, *serverAIs, plugins, gs, *debug)
+		return
+	}
+
+	if parsed == tuneCmd.FullCommand() {
+		gs := gameSpec()
+		_, user := userCredentials()
+
+		if err := runTuning(*tuneConfig, user.Password, gs, *tuneBest, *debug); err != nil {
+			exitErr(err)
+		}
+
+		return
+	}
+
+	// `render --game` needs to log in to get the log of the game
+	if parsed == renderCmd.FullCommand() && *renderGame == "" {
+		if err := renderCommand(nil); err != nil {
+			exitErr(err)
+		}
 
 		return
 	}
 
+	// only `create` needs a spec; we check it before we log in
+	var gs api.GameSpec
+
+	if parsed == createCmd.FullCommand() {
+		gs = gameSpec()
+		if err := gs.Check(); err != nil {
+			exitErr(err)
+		}
+	}
+
 	cl := api.NewClient()
 	cl.SetDebug(*debug)
 
-	if err := cl.LoginWithCredentials(*login, *password); err != nil {
+	loadErrors(cl)
+
+	if parsed == logoutCmd.FullCommand() {
+		if err := logout(cl); err != nil {
+			exitErr(err)
+		}
+		return
+	}
+
+	creds, user := userCredentials()
+
+	if ok, err := sessionCommand(cl, parsed, user); ok {
+		if err != nil {
+			exitErr(err)
+		}
+		return
+	}
+
+	resumed, err := connect(cl, creds)
+	if err != nil {
 		exitErr(err)
 	}
 
-	switch parsed {
+	err = remoteCommand(cl, parsed, gs)
+
+	if resumed && err == api.ErrNotLogged {
+		os.Remove(sessionPath)
+		err = fmt.Errorf("the session expired, use `antroid login` to log in again")
+	}
+
+	// we stay logged if we resumed a session
+	if !resumed {
+		cl.Logout()
+	}
+
+	if err != nil {
+		exitErr(err)
+	}
+}
+
+// the file in which we cache the error codes of the remote API, and for how
+// long
+var (
+	errorsCachePath = configPath("api-errors.json")
+	errorsCacheAge  = 24 * time.Hour
+)
+
+// loadErrors loads the error codes of the remote API so that the ones we
+// don't know are given with their description. We can live without them, so
+// failures are only shown in debug mode.
+func loadErrors(cl *api.Client) {
+	err := os.MkdirAll(filepath.Dir(errorsCachePath), 0700)
+	if err == nil {
+		err = cl.LoadErrors(errorsCachePath, errorsCacheAge)
+	}
+
+	if err != nil && *debug {
+		fmt.Fprintf(os.Stderr, "Can't load the API error codes: %v\n", err)
+	}
+}
+
+// remoteCommand runs a subcommand which calls the remote API with a logged
+// client
+func remoteCommand(cl *api.Client, cmd string, gs api.GameSpec) error {
+	switch cmd {
 	case apiCmd.FullCommand():
-		if info, err := cl.APIInfo(); err != nil {
-			exitErr(err)
+		info, err := cl.APIInfo()
+		if err != nil {
+			return err
+		}
+
+		if *apiCheck {
+			return printIncompatibilities(info.Incompatibilities())
+		}
+		return printAPIInfo(info, *apiMethod)
+
+	case callCmd.FullCommand():
+		args, err := api.ParseArgs(*callArgs)
+		if err != nil {
+			return err
+		}
+
+		var resp json.RawMessage
+
+		if *callVerb != "" {
+			resp, err = cl.CallVerb(*callVerb, *callName, args)
 		} else {
-			var keys []string
-			for k := range info.Doc {
-				keys = append(keys, k)
+			var info api.APIInfo
+			if info, err = cl.APIInfo(); err != nil {
+				return err
 			}
-			fmt.Printf("API methods: %s\n", strings.Join(keys, ", "))
+			resp, err = cl.Call(info, *callName, args)
+		}
+
+		if err != nil {
+			return err
 		}
+		return printResponse(resp)
+
+	case renderCmd.FullCommand():
+		return renderCommand(cl)
 
 	case whoCmd.FullCommand():
-		if s, err := cl.WhoAmI(); err != nil {
-			exitErr(err)
-		} else {
-			fmt.Printf("Username: %s\n", s)
+		s, err := cl.WhoAmI()
+		if err != nil {
+			return err
 		}
+		return printUsername(s)
 
 	case gamesCmd.FullCommand():
-		if games, err := cl.ListGames(); err != nil {
-			exitErr(err)
-		} else {
-			fmt.Println("Available games:")
-			for _, g := range games {
-				fmt.Printf("- %s\n", g)
-			}
-		}
+		return listGames(cl)
 
 	case statusCmd.FullCommand():
-		gID := api.GameID(*statusID)
-
-		if gs, err := cl.GetGameIdentifierStatus(gID); err != nil {
-			exitErr(err)
-		} else {
-			fmt.Printf("%s\n", gs)
+		status, err := cl.GetGameIdentifierStatus(api.GameID(*statusID))
+		if err != nil {
+			return err
 		}
+		return printStatus(status)
+
+	case watchCmd.FullCommand():
+		return watchGame(cl, api.GameID(*watchID), *watchEvery, *watchHook)
 
 	case destroyCmd.FullCommand():
 		gID := api.GameID(*destroyID)
 
 		if err := cl.DestroyGameIdentifier(gID); err != nil {
-			exitErr(err)
-		} else {
-			fmt.Printf("Game %s successfully destroyed\n", gID)
+			return err
 		}
+		return printGameAction(gID, "destroyed")
 
 	case joinCmd.FullCommand():
 		gID := api.GameID(*joinID)
 
 		if err := cl.JoinGameIdentifier(gID); err != nil {
-			exitErr(err)
-		} else {
-			fmt.Printf("Game %s successfully joined\n", gID)
+			return err
 		}
+		return printGameAction(gID, "joined")
 
 	case createCmd.FullCommand():
-		if g, err := cl.CreateGame(&gs); err != nil {
-			exitErr(err)
-		} else {
-			fmt.Printf("Game %s successfully created\n", g.Identifier)
+		g, err := cl.CreateGame(&gs)
+		if err != nil {
+			return err
 		}
+		return printGameAction(g.Identifier, "created")
 
 	case playCmd.FullCommand():
+		if len(*playCmds) == 0 {
+			return fmt.Errorf("expected some commands, or --interactive")
+		}
+
 		cmds := strings.Join(*playCmds, ",")
 
-		if t, err := cl.PlayIdentifier(api.GameID(*playID), api.Commands(cmds)); err != nil {
-			exitErr(err)
-		} else {
-			fmt.Printf("%s\n", t)
+		t, err := cl.PlayIdentifier(api.GameID(*playID), api.Commands(cmds))
+		if err != nil {
+			return err
 		}
+		return printTurn(t)
+
 	default:
 		app.Usage(os.Stderr)
 	}
 
-	cl.Logout()
+	return nil
 }
@@ -34,6 +34,11 @@ const (
 	stop = "STOP"
 )
 
+// IsStop returns true if a message is the one we send to actors to tell them
+// to stop. Actors which don't use `Actor` or `RemoteAI`, e.g. the manual
+// player of `tui/manual.go`, use it to know when to end.
+func IsStop(msg string) bool { return msg == stop }
+
 // ActorInterface is used to represent generic Actors
 type ActorInterface interface {
 
@@ -61,6 +66,13 @@ type Actor struct {
 	// able to read the external command's output (stdout) and `writable` menas
 	// we'll able to write to it (stdin).
 	readable, writable bool
+
+	// Answers the queries the command sends before its output line, if it's
+	// readable (see `api/queries.go`)
+	queries QueryHandler
+	// Collects the annotations and blackboard facts it sends before its
+	// output line (see `api/annotations.go` and `api/blackboard.go`)
+	annotate func(string)
 }
 
 // NewActor returns a pointer on a new Actor object. `cmd` is the command to
@@ -91,9 +103,17 @@ func (a *Actor) Send(m string) { a.input <- m }
 // Receive a message from this actor (blocking)
 func (a *Actor) Read() string { return <-a.output }
 
+// SetQueryHandler sets the handler used to answer this actor's queries. This
+// should be called before `Start(wg)`.
+func (a *Actor) SetQueryHandler(h QueryHandler) { a.queries = h }
+
+// SetAnnotationHandler sets the function called with each annotation or fact
+// line this actor sends. This should be called before `Start(wg)`.
+func (a *Actor) SetAnnotationHandler(f func(string)) { a.annotate = f }
+
 // errLog takes an error and prints it on stderr along with the actor's command
 func (a *Actor) errLog(err error) {
-	fmt.Fprintf(os.Stderr, "%s: %s", a.cmd.Path, err)
+	fmt.Fprintf(os.Stderr, "%s: %s\n", a.cmd.Path, err)
 }
 
 // This is the main function of an actor. It binds I/O and starts an infinite
@@ -106,24 +126,96 @@ func (a *Actor) errLog(err error) {
 // "stop". This method returns an error but we'll never be able to get it since
 // it's started in the goroutine. This is why we log it using `.errLog` (see
 // above).
+//
+// If the command can't be started or crashes in the middle of a game we log
+// the error and keep answering with empty lines, i.e. no commands, like remote
+// AIs do (see `api/remote.go`). This way a crashed AI doesn't block the game.
 func (a *Actor) start(wg *sync.WaitGroup) (err error) {
 	var stdin io.WriteCloser
-	var stdout io.ReadCloser
+	var stdoutReader *bufio.Reader
 
 	// notify the wait group when we're done
 	if wg != nil {
 		defer wg.Done()
 	}
 
+	running := true
+
+	if stdin, stdoutReader, err = a.run(); err != nil {
+		a.errLog(err)
+		running = false
+	}
+
+	var line, msg string
+
+	// main loop
+	for {
+		// 1. read on our input channel
+		msg = <-a.input
+
+		// if it's the special "stop" message, break the loop
+		if msg == stop {
+			break
+		}
+
+		line = ""
+
+		if running {
+			// 2. if we're writable, send the input on the command's STDIN
+			if a.writable {
+				_, err = io.WriteString(stdin, msg)
+			}
+
+			// 3. if we're readable, read one line on the command's STDOUT,
+			// answering its queries and collecting its annotations and facts
+			// if it sends any
+			if err == nil && a.readable {
+				line, err = readCommand(stdoutReader, stdin, a.queries, a.annotate)
+			}
+
+			if err != nil {
+				a.errLog(err)
+				running = false
+				line = ""
+			}
+		}
+
+		// 4. send the line on our output channel
+		if a.readable {
+			a.output <- line
+		}
+	}
+
+	// close STDIN before waiting for the command to end
+	if stdin != nil && a.writable {
+		stdin.Close()
+	}
+
+	// wait for the command to end
+	if a.cmd.Process != nil {
+		a.cmd.Wait()
+	}
+
+	// close our input/output channels. This means we can't start an actor
+	// twice because the second time its channels will be closed, but we don't
+	// do that anyway.
+	close(a.input)
+	close(a.output)
+
+	return
+}
+
+// run binds the command's I/O and starts it
+func (a *Actor) run() (stdin io.WriteCloser, stdout *bufio.Reader, err error) {
+	var stdoutPipe io.ReadCloser
+
 	// create a pipe for STDIN
 	if stdin, err = a.cmd.StdinPipe(); err != nil {
-		a.errLog(err)
 		return
 	}
 
 	// create a pipe for STDOUT
-	if stdout, err = a.cmd.StdoutPipe(); err != nil {
-		a.errLog(err)
+	if stdoutPipe, err = a.cmd.StdoutPipe(); err != nil {
 		return
 	}
 
@@ -137,68 +229,54 @@ func (a *Actor) start(wg *sync.WaitGroup) (err error) {
 
 	// close STDOUT if we're not readable
 	if !a.readable {
-		stdout.Close()
+		stdoutPipe.Close()
 	}
 
 	// start the underlying command
 	if err = a.cmd.Start(); err != nil {
-		a.errLog(err)
 		return
 	}
 
 	// bufferize our STDOUT pipe to be able to use higher level reading
 	// methods
-	stdoutReader := bufio.NewReader(stdout)
+	stdout = bufio.NewReader(stdoutPipe)
 
-	var buf []byte
-	var msg string
+	return
+}
 
-	// main loop
+// readCommand reads lines from an AI until it gets its command, and returns
+// it. AIs can send queries (lines starting with `?`), annotations (lines
+// starting with `#`) and blackboard facts (lines starting with `!`) before
+// their command: queries are answered by the handler while annotations and
+// facts are given to `annotate`, if it's not nil. It's used by both local and
+// remote AIs.
+func readCommand(r *bufio.Reader, w io.Writer, h QueryHandler, annotate func(string)) (string, error) {
 	for {
-		// 1. read on our input channel
-		msg = <-a.input
-
-		// if it's the special "stop" message, break the loop
-		if msg == stop {
-			break
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return line, err
 		}
 
-		// 2. if we're writable, send the input on the command's STDIN
-		if a.writable {
-			if _, err = io.WriteString(stdin, msg); err != nil {
-				a.errLog(err)
-				break
+		switch {
+		case strings.HasPrefix(line, "#"), strings.HasPrefix(line, "!"):
+			if annotate != nil {
+				annotate(line)
 			}
-		}
 
-		// 3. if we're readable...
-		if a.readable {
-			// 3.1 read one line on the command's STDOUT...
-			if buf, err = stdoutReader.ReadSlice('\n'); err != nil {
-				a.errLog(err)
-				break
+		case strings.HasPrefix(line, "?"):
+			answer := queryError("queries are not supported")
+			if h != nil {
+				answer = h.Query(strings.TrimSpace(line[1:]))
 			}
 
-			// 3.2 ...and send it on our output channel
-			a.output <- string(buf)
-		}
-	}
+			if _, err = io.WriteString(w, answer+"\n"); err != nil {
+				return "", err
+			}
 
-	// close STDIN before waiting for the command to end
-	if a.writable {
-		stdin.Close()
+		default:
+			return line, nil
+		}
 	}
-
-	// wait for the command to end
-	a.cmd.Wait()
-
-	// close our input/output channels. This means we can't start an actor
-	// twice because the second time its channels will be closed, but we don't
-	// do that anyway.
-	close(a.input)
-	close(a.output)
-
-	return
 }
 
 // A Stage is an extensible set of Actors
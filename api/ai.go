@@ -5,8 +5,10 @@ package api
 // `AIPool` is a `Stage` that contains only `AI`s.
 
 import (
+	"net"
 	"os/exec"
 	"strings"
+	"sync"
 )
 
 // An AI is just an Actor
@@ -21,8 +23,21 @@ func NewAI(name string, arg ...string) *AI {
 }
 
 // An AIPool is a pool of multiple AIs. This is just a wrapper around a `Stage`
-// which contains only `AI`s.
-type AIPool struct{ Stage }
+// which contains only `AI`s, and which can answer their queries.
+type AIPool struct {
+	Stage
+
+	queries QueryHandler
+
+	// guards annotations and facts, which AIs send concurrently
+	lock sync.Mutex
+	// the annotations sent by each AI since the last call to
+	// `.Annotations()`, indexed by AI
+	annotations [][]Annotation
+	// the blackboard facts published by each AI since the last call to
+	// `.Facts()`, indexed by AI
+	facts [][]Fact
+}
 
 // NewAIPool returns a pointer on a new, empty, AIPool
 func NewAIPool() *AIPool {
@@ -36,8 +51,153 @@ func (pool *AIPool) AddAI(name string, args ...string) {
 	pool.AddActor(NewAI(name, args...))
 }
 
+// SetQueryHandler sets the handler used to answer the AIs' queries (see
+// `api/queries.go`). This should be called before `Start()`.
+func (pool *AIPool) SetQueryHandler(h QueryHandler) {
+	pool.queries = h
+}
+
+// Start starts all AIs, after giving them the pool's query handler and a
+// function to collect their annotations and facts
+func (pool *AIPool) Start() {
+	pool.annotations = make([][]Annotation, len(pool.actors))
+	pool.facts = make([][]Fact, len(pool.actors))
+
+	for i, a := range pool.actors {
+		if q, ok := a.(queryable); ok {
+			q.SetQueryHandler(pool.queries)
+		}
+
+		if an, ok := a.(annotatable); ok {
+			ai := i
+			an.SetAnnotationHandler(func(line string) { pool.annotate(ai, line) })
+		}
+	}
+
+	pool.Stage.Start()
+}
+
+// annotate saves an annotation or a fact line sent by the AI `ai`. Malformed
+// ones are ignored.
+func (pool *AIPool) annotate(ai int, line string) {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	if strings.HasPrefix(line, "!") {
+		if f, ok := ParseFact(ai, line); ok {
+			pool.facts[ai] = append(pool.facts[ai], f)
+		}
+		return
+	}
+
+	if a, ok := ParseAnnotation(ai, line); ok {
+		pool.annotations[ai] = append(pool.annotations[ai], a)
+	}
+}
+
+// Annotations returns the annotations sent by the AIs since the last call,
+// sorted by AI, and forgets them.
+func (pool *AIPool) Annotations() (annotations []Annotation) {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	for i, as := range pool.annotations {
+		annotations = append(annotations, as...)
+		pool.annotations[i] = nil
+	}
+
+	return
+}
+
+// AddRemoteAI connects to a remote AI (see `api/remote.go`) and adds it to
+// the pool
+func (pool *AIPool) AddRemoteAI(endpoint string) error {
+	ai, err := DialAI(endpoint)
+	if err != nil {
+		return err
+	}
+
+	pool.AddActor(ai)
+	return nil
+}
+
+// Load adds AIs to the pool. Each one is either a command with its
+// space-separated arguments or a remote endpoint (see `IsRemoteEndpoint`).
+func (pool *AIPool) Load(ais ...string) error {
+	for _, ai := range ais {
+		if IsRemoteEndpoint(ai) {
+			if err := pool.AddRemoteAI(ai); err != nil {
+				return err
+			}
+			continue
+		}
+
+		words := strings.Split(ai, " ")
+		pool.AddAI(words[0], words[1:]...)
+	}
+
+	return nil
+}
+
+// Accept waits for `n` AIs to connect on the listener and adds them to the
+// pool. `connected` is called after each connection if it's not nil.
+func (pool *AIPool) Accept(l net.Listener, n int, connected func(*RemoteAI)) error {
+	for i := 0; i < n; i++ {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+
+		ai := NewRemoteAI(conn.RemoteAddr().String(), conn)
+		pool.AddActor(ai)
+
+		if connected != nil {
+			connected(ai)
+		}
+	}
+
+	return nil
+}
+
+// Facts returns the blackboard facts published by the AIs since the last
+// call, sorted by AI, and forgets them.
+func (pool *AIPool) Facts() (facts []Fact) {
+	pool.lock.Lock()
+	defer pool.lock.Unlock()
+
+	for i, fs := range pool.facts {
+		facts = append(facts, fs...)
+		pool.facts[i] = nil
+	}
+
+	return
+}
+
 // GetCommandResponse reads the messages from all AIs and return them all as a
-// Commands object that can be sent to the remote server.
+// Commands object that can be sent to the remote server. AIs which don't
+// send any command are ignored. If several AIs give a command to the same ant
+// only the first one is kept, so an actor added first to the pool (e.g. the
+// manual mode, see `tui/manual.go`) takes precedence over the others.
 func (pool *AIPool) GetCommandResponse() (resp Commands) {
-	return Commands(strings.Join(pool.ReadAll(), ","))
+	var cmds []string
+
+	ants := make(map[string]bool)
+
+	for _, msg := range pool.ReadAll() {
+		if msg == "" {
+			continue
+		}
+
+		for _, cmd := range strings.Split(msg, ",") {
+			ant := strings.TrimSpace(strings.SplitN(cmd, ":", 2)[0])
+			if ants[ant] {
+				continue
+			}
+
+			ants[ant] = true
+			cmds = append(cmds, cmd)
+		}
+	}
+
+	return Commands(strings.Join(cmds, ","))
 }
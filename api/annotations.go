@@ -0,0 +1,149 @@
+package api
+
+// This file describes the annotations AIs can send to explain themselves.
+// Before its command an AI can send lines starting with `#`, like
+// `#mark 3 4 food` or `#path 3 4 4 5`. They're not sent to the remote server
+// but collected by the AIs pool, then forwarded to the listeners in the turn
+// message and saved in the journal so GUIs can show them on the map. See
+// `docs/ai_protocol.md` for the details.
+
+import (
+	"strconv"
+	"strings"
+)
+
+// An Annotation is a note sent by an AI during a turn
+type Annotation struct {
+	// the index of the AI which sent it, in the order they were given to the
+	// game server
+	AI int
+	// "mark", "path" or "log"
+	Kind string
+	// the marked cell or the path's cells
+	Positions []Position
+	// the mark's label or the log's text
+	Text string
+}
+
+// annotatable is implemented by actors which can send annotations
+type annotatable interface {
+	SetAnnotationHandler(func(string))
+}
+
+// ParseAnnotation parses an annotation line sent by an AI, with or without
+// its leading `#`. The boolean is false if it's malformed.
+func ParseAnnotation(ai int, line string) (Annotation, bool) {
+	a := Annotation{AI: ai}
+
+	line = strings.TrimPrefix(strings.TrimSpace(line), "#")
+	words := strings.Fields(line)
+
+	if len(words) == 0 {
+		return a, false
+	}
+
+	a.Kind = words[0]
+	args := words[1:]
+
+	switch a.Kind {
+	case "mark":
+		if len(args) < 2 {
+			return a, false
+		}
+
+		pos, ok := parsePositions(args[:2])
+		if !ok {
+			return a, false
+		}
+
+		a.Positions = pos
+		if len(args) > 2 {
+			a.Text = afterFields(line, 3)
+		}
+
+	case "path":
+		if len(args) == 0 || len(args)%2 != 0 {
+			return a, false
+		}
+
+		pos, ok := parsePositions(args)
+		if !ok {
+			return a, false
+		}
+
+		a.Positions = pos
+
+	case "log":
+		if len(args) > 0 {
+			a.Text = afterFields(line, 1)
+		}
+
+	default:
+		return a, false
+	}
+
+	return a, true
+}
+
+// afterFields returns what's after the first `n` fields of a line, e.g. the
+// text of an annotation after its kind and coordinates.
+func afterFields(line string, n int) string {
+	for i := 0; i < n; i++ {
+		line = strings.TrimLeft(line, " \t")
+
+		j := strings.IndexAny(line, " \t")
+		if j < 0 {
+			return ""
+		}
+
+		line = line[j:]
+	}
+
+	return strings.TrimSpace(line)
+}
+
+// parsePositions parses a list of coordinates `X Y X Y ...`
+func parsePositions(args []string) ([]Position, bool) {
+	var positions []Position
+
+	for i := 0; i+1 < len(args); i += 2 {
+		x, err := strconv.Atoi(args[i])
+		if err != nil {
+			return nil, false
+		}
+
+		y, err := strconv.Atoi(args[i+1])
+		if err != nil {
+			return nil, false
+		}
+
+		positions = append(positions, Position{X: x, Y: y})
+	}
+
+	return positions, true
+}
+
+// protocolLine returns the line describing the annotation in the listeners'
+// turn message: `I mark X Y label`, `I path N X Y ...` or `I log text`.
+func (a Annotation) protocolLine() string {
+	parts := []string{strconv.Itoa(a.AI), a.Kind}
+
+	switch a.Kind {
+	case "mark":
+		if len(a.Positions) > 0 {
+			parts = append(parts, strconv.Itoa(a.Positions[0].X),
+				strconv.Itoa(a.Positions[0].Y))
+		}
+	case "path":
+		parts = append(parts, strconv.Itoa(len(a.Positions)))
+		for _, p := range a.Positions {
+			parts = append(parts, strconv.Itoa(p.X), strconv.Itoa(p.Y))
+		}
+	}
+
+	if a.Text != "" {
+		parts = append(parts, a.Text)
+	}
+
+	return strings.Join(parts, " ")
+}
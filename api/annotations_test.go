@@ -0,0 +1,105 @@
+package api
+
+import (
+	"github.com/franela/goblin"
+	o "github.com/onsi/gomega"
+	"testing"
+)
+
+func TestAnnotations(t *testing.T) {
+
+	g := goblin.Goblin(t)
+
+	o.RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	g.Describe("ParseAnnotation", func() {
+		g.It("Should parse a mark", func() {
+			a, ok := ParseAnnotation(2, "#mark 3 4\n")
+
+			o.Expect(ok).To(o.BeTrue())
+			o.Expect(a).To(o.Equal(Annotation{
+				AI:        2,
+				Kind:      "mark",
+				Positions: []Position{{X: 3, Y: 4}},
+			}))
+		})
+
+		g.It("Should parse a mark with a label", func() {
+			a, ok := ParseAnnotation(0, "#mark 3 4 nearest  food\n")
+
+			o.Expect(ok).To(o.BeTrue())
+			o.Expect(a.Text).To(o.Equal("nearest  food"))
+		})
+
+		g.It("Should parse a path", func() {
+			a, ok := ParseAnnotation(0, "#path 1 1 2 2 3 2")
+
+			o.Expect(ok).To(o.BeTrue())
+			o.Expect(a.Kind).To(o.Equal("path"))
+			o.Expect(a.Positions).To(o.Equal([]Position{
+				{X: 1, Y: 1}, {X: 2, Y: 2}, {X: 3, Y: 2},
+			}))
+		})
+
+		g.It("Should parse a log", func() {
+			a, ok := ParseAnnotation(0, "#log ant 2 is going home\n")
+
+			o.Expect(ok).To(o.BeTrue())
+			o.Expect(a.Kind).To(o.Equal("log"))
+			o.Expect(a.Text).To(o.Equal("ant 2 is going home"))
+		})
+
+		g.It("Should reject malformed annotations", func() {
+			for _, line := range []string{
+				"#",
+				"#foo 1 2",
+				"#mark 3",
+				"#mark a 4",
+				"#path",
+				"#path 1 2 3",
+			} {
+				_, ok := ParseAnnotation(0, line)
+				o.Expect(ok).To(o.BeFalse())
+			}
+		})
+	})
+
+	g.Describe("Annotation.protocolLine", func() {
+		g.It("Should prefix the line with the AI", func() {
+			a, _ := ParseAnnotation(1, "#mark 3 4 food")
+			o.Expect(a.protocolLine()).To(o.Equal("1 mark 3 4 food"))
+		})
+
+		g.It("Should write the length of paths", func() {
+			a, _ := ParseAnnotation(0, "#path 1 1 2 2")
+			o.Expect(a.protocolLine()).To(o.Equal("0 path 2 1 1 2 2"))
+		})
+
+		g.It("Should write the text of logs", func() {
+			a, _ := ParseAnnotation(0, "#log hello")
+			o.Expect(a.protocolLine()).To(o.Equal("0 log hello"))
+		})
+	})
+
+	g.Describe("AIPool", func() {
+		g.It("Should collect the annotations of its AIs", func() {
+			pool := NewAIPool()
+			pool.AddAI("sh", "-c", `read msg; echo "#log hello"; echo "#bad"; echo "0:rest"`)
+			pool.AddAI("sh", "-c", `read msg; echo "#mark 1 2 food"; echo "1:rest"`)
+
+			pool.Start()
+			pool.SendAll("1 2\n")
+			o.Expect(pool.GetCommandResponse()).To(o.Equal(Commands("0:rest,1:rest")))
+
+			o.Expect(pool.Annotations()).To(o.Equal([]Annotation{
+				{AI: 0, Kind: "log", Text: "hello"},
+				{AI: 1, Kind: "mark", Positions: []Position{{X: 1, Y: 2}}, Text: "food"},
+			}))
+
+			// they're forgotten once we got them
+			o.Expect(pool.Annotations()).To(o.BeEmpty())
+
+			pool.Stop()
+		})
+	})
+}
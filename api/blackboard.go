@@ -0,0 +1,95 @@
+package api
+
+// This file describes the blackboard, which AIs use to coordinate. When we run
+// several AIs in the same game (e.g. a scout and a forager) they can publish
+// facts along with their command, like the targets they claimed or the food
+// they found. The player merges them into its blackboard at the end of the
+// turn and sends the whole blackboard to all AIs on the next turn.
+//
+// AIs publish facts with lines like `!food:12:3 found by ant 2` before their
+// command, and remove them with `!food:12:3`. They're collected by the AIs
+// pool like the annotations (see `api/annotations.go`). See
+// `docs/ai_protocol.md` for the details.
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A Fact is a key/value pair published on the blackboard
+type Fact struct {
+	// the index of the AI which published it
+	AI int
+	// the key, which doesn't contain any space
+	Key string
+	// the value. An empty value removes the fact from the blackboard.
+	Value string
+}
+
+// ParseFact parses a fact line sent by an AI, with or without its leading
+// `!`. The boolean is false if it's malformed.
+func ParseFact(ai int, line string) (Fact, bool) {
+	line = strings.TrimPrefix(strings.TrimSpace(line), "!")
+	words := strings.Fields(line)
+
+	if len(words) == 0 {
+		return Fact{}, false
+	}
+
+	return Fact{AI: ai, Key: words[0], Value: afterFields(line, 1)}, true
+}
+
+// protocolLine returns the line describing the fact in the turn message:
+// `I KEY VALUE`.
+func (f Fact) protocolLine() string {
+	return strconv.Itoa(f.AI) + " " + f.Key + " " + f.Value
+}
+
+// A Blackboard is a set of facts, indexed by their key
+type Blackboard struct {
+	facts map[string]Fact
+}
+
+// NewBlackboard returns a pointer on a new, empty, blackboard
+func NewBlackboard() *Blackboard {
+	return &Blackboard{facts: make(map[string]Fact)}
+}
+
+// Merge applies the facts published during a turn, in order. This means that
+// if two AIs publish the same key the last one wins. Facts with an empty value
+// remove their key.
+func (b *Blackboard) Merge(facts []Fact) {
+	for _, f := range facts {
+		if f.Value == "" {
+			delete(b.facts, f.Key)
+		} else {
+			b.facts[f.Key] = f
+		}
+	}
+}
+
+// Get returns the fact with the given key. The boolean is false if there's no
+// such fact.
+func (b *Blackboard) Get(key string) (Fact, bool) {
+	f, ok := b.facts[key]
+	return f, ok
+}
+
+// Facts returns all the facts, sorted by key
+func (b *Blackboard) Facts() []Fact {
+	facts := make([]Fact, 0, len(b.facts))
+
+	for _, f := range b.facts {
+		facts = append(facts, f)
+	}
+
+	sort.Slice(facts, func(i, j int) bool { return facts[i].Key < facts[j].Key })
+
+	return facts
+}
+
+// Len returns the number of facts
+func (b *Blackboard) Len() int {
+	return len(b.facts)
+}
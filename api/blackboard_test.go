@@ -0,0 +1,121 @@
+package api
+
+import (
+	"bufio"
+	"github.com/franela/goblin"
+	o "github.com/onsi/gomega"
+	"strings"
+	"testing"
+)
+
+func TestBlackboard(t *testing.T) {
+
+	g := goblin.Goblin(t)
+
+	o.RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	g.Describe("ParseFact", func() {
+		g.It("Should parse a key and a value", func() {
+			f, ok := ParseFact(1, "!food:3:4 found by  ant 2\n")
+
+			o.Expect(ok).To(o.BeTrue())
+			o.Expect(f).To(o.Equal(Fact{AI: 1, Key: "food:3:4", Value: "found by  ant 2"}))
+		})
+
+		g.It("Should parse a key without value", func() {
+			f, ok := ParseFact(0, "!target\n")
+
+			o.Expect(ok).To(o.BeTrue())
+			o.Expect(f).To(o.Equal(Fact{Key: "target"}))
+		})
+
+		g.It("Should reject lines without key", func() {
+			_, ok := ParseFact(0, "!  \n")
+			o.Expect(ok).To(o.BeFalse())
+		})
+	})
+
+	g.Describe("Blackboard", func() {
+		var b *Blackboard
+
+		g.BeforeEach(func() {
+			b = NewBlackboard()
+		})
+
+		g.It("Should be empty", func() {
+			o.Expect(b.Len()).To(o.Equal(0))
+			o.Expect(b.Facts()).To(o.BeEmpty())
+		})
+
+		g.It("Should sort its facts by key", func() {
+			b.Merge([]Fact{{Key: "b", Value: "1"}, {Key: "a", Value: "2"}})
+
+			o.Expect(b.Facts()).To(o.Equal([]Fact{
+				{Key: "a", Value: "2"},
+				{Key: "b", Value: "1"},
+			}))
+		})
+
+		g.It("Should keep the last value of a key", func() {
+			b.Merge([]Fact{{AI: 0, Key: "a", Value: "1"}, {AI: 1, Key: "a", Value: "2"}})
+
+			f, ok := b.Get("a")
+			o.Expect(ok).To(o.BeTrue())
+			o.Expect(f).To(o.Equal(Fact{AI: 1, Key: "a", Value: "2"}))
+		})
+
+		g.It("Should keep its facts across merges", func() {
+			b.Merge([]Fact{{Key: "a", Value: "1"}})
+			b.Merge([]Fact{{Key: "b", Value: "2"}})
+
+			o.Expect(b.Len()).To(o.Equal(2))
+		})
+
+		g.It("Should remove facts without value", func() {
+			b.Merge([]Fact{{Key: "a", Value: "1"}})
+			b.Merge([]Fact{{Key: "a"}})
+
+			_, ok := b.Get("a")
+			o.Expect(ok).To(o.BeFalse())
+		})
+	})
+
+	g.Describe("AIPool", func() {
+		g.It("Should collect the facts published by its AIs", func() {
+			pool := NewAIPool()
+			pool.AddAI("sh", "-c", `read msg; echo "!target 4 5"; echo "#log hi"; echo "0:rest"`)
+			pool.AddAI("sh", "-c", `read msg; echo "!food"; echo "1:rest"`)
+
+			pool.Start()
+			pool.SendAll("1 2\n")
+			o.Expect(pool.GetCommandResponse()).To(o.Equal(Commands("0:rest,1:rest")))
+
+			o.Expect(pool.Facts()).To(o.Equal([]Fact{
+				{AI: 0, Key: "target", Value: "4 5"},
+				{AI: 1, Key: "food"},
+			}))
+			o.Expect(pool.Annotations()).To(o.HaveLen(1))
+
+			// they're forgotten once we got them
+			o.Expect(pool.Facts()).To(o.BeEmpty())
+
+			pool.Stop()
+		})
+	})
+
+	g.Describe("Player", func() {
+		g.It("Should send its blackboard to its AIs", func() {
+			p := newTestPlayer()
+			ai := &fakeActor{}
+			p.AIs.AddActor(ai)
+
+			p.Blackboard().Merge([]Fact{{AI: 1, Key: "target", Value: "4 4"}})
+			p.sendTurnStatusToAIs()
+
+			m, err := ReadTurnMessage(bufio.NewReader(strings.NewReader(ai.messages[0])))
+
+			o.Expect(err).To(o.BeNil())
+			o.Expect(m.Blackboard).To(o.Equal([]Fact{{AI: 1, Key: "target", Value: "4 4"}}))
+		})
+	})
+}
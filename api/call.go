@@ -0,0 +1,119 @@
+package api
+
+// This file describes generic calls to any method of the API, using its
+// description from /api to know the verb and the parameters. They're an
+// escape hatch for the methods which have no `Call*` method in `api/io.go`
+// yet, e.g. because the server just added them:
+//
+//     info, _ := cl.APIInfo()
+//     args, _ := ParseArgs([]string{"id=42"})
+//     resp, err := cl.Call(info, "status", args)
+//
+// The response is returned as raw JSON since we don't know its structure.
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// ParseArgs parses "key=value" arguments as the parameters of a call. If a
+// key is given several times the last value wins.
+func ParseArgs(args []string) (url.Values, error) {
+	values := url.Values{}
+
+	for _, arg := range args {
+		kv := strings.SplitN(arg, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("bad parameter %q, expected key=value", arg)
+		}
+		values.Set(kv[0], kv[1])
+	}
+
+	return values, nil
+}
+
+// Method returns the description of a method. Its name can start with a
+// slash or not.
+func (info APIInfo) Method(name string) (APIMethod, bool) {
+	name = strings.TrimPrefix(name, "/")
+
+	if m, ok := info.Doc[name]; ok {
+		return m, true
+	}
+
+	m, ok := info.Doc["/"+name]
+	return m, ok
+}
+
+// CheckArgs returns an error if some parameters are not the method's ones, or
+// if some of its parameters are missing
+func (m APIMethod) CheckArgs(args url.Values) error {
+	params := m.Params()
+	known := make(map[string]bool)
+
+	var problems []string
+
+	for _, p := range params {
+		known[p] = true
+		if _, ok := args[p]; !ok {
+			problems = append(problems, fmt.Sprintf("missing parameter %q", p))
+		}
+	}
+
+	var unknown []string
+	for k := range args {
+		if !known[k] {
+			unknown = append(unknown, k)
+		}
+	}
+	sort.Strings(unknown)
+
+	for _, k := range unknown {
+		problems = append(problems, fmt.Sprintf("unknown parameter %q", k))
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	if len(params) > 0 {
+		problems = append(problems, fmt.Sprintf("expected %s",
+			strings.Join(params, ", ")))
+	} else {
+		problems = append(problems, "expected no parameters")
+	}
+
+	return fmt.Errorf("%s", strings.Join(problems, "; "))
+}
+
+// Call calls a method described in `info` with some parameters, and returns
+// the raw JSON of its response. The parameters are checked against the
+// method's description before the call.
+func (cl *Client) Call(info APIInfo, method string, args url.Values) (json.RawMessage, error) {
+	m, ok := info.Method(method)
+	if !ok {
+		return nil, fmt.Errorf("unknown API method %q", method)
+	}
+
+	if err := m.CheckArgs(args); err != nil {
+		return nil, fmt.Errorf("%s: %v", method, err)
+	}
+
+	return cl.CallVerb(m.Verb, method, args)
+}
+
+// CallVerb calls any method with a verb and some parameters without checking
+// them, and returns the raw JSON of its response. Use it for the methods /api
+// doesn't describe.
+func (cl *Client) CallVerb(verb, method string, args url.Values) (json.RawMessage, error) {
+	body := cl.http.call(strings.ToUpper(verb), "/"+strings.TrimPrefix(method, "/"), args)
+
+	if err := body.Error(); err != nil {
+		return nil, err
+	}
+
+	return *body.Content, nil
+}
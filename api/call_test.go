@@ -0,0 +1,131 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/franela/goblin"
+	o "github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestCall(t *testing.T) {
+
+	g := goblin.Goblin(t)
+
+	o.RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	info := APIInfo{Doc: map[string]APIMethod{
+		"/echo":  {Verb: "post", Input: []string{"a : string", "b : int"}},
+		"whoami": {Verb: "get", Input: []string{}},
+	}}
+
+	g.Describe("ParseArgs", func() {
+		g.It("Should parse key=value arguments", func() {
+			args, err := ParseArgs([]string{"a=1", "b=x=y", "a=2", "c="})
+
+			o.Expect(err).To(o.BeNil())
+			o.Expect(args).To(o.Equal(url.Values{"a": {"2"}, "b": {"x=y"}, "c": {""}}))
+		})
+
+		g.It("Should reject arguments without a key", func() {
+			_, err := ParseArgs([]string{"a"})
+			o.Expect(err).NotTo(o.BeNil())
+
+			_, err = ParseArgs([]string{"=a"})
+			o.Expect(err).NotTo(o.BeNil())
+		})
+	})
+
+	g.Describe("APIInfo.Method", func() {
+		g.It("Should find a method with or without its slash", func() {
+			for _, name := range []string{"echo", "/echo", "whoami", "/whoami"} {
+				_, ok := info.Method(name)
+				o.Expect(ok).To(o.BeTrue())
+			}
+
+			_, ok := info.Method("foo")
+			o.Expect(ok).To(o.BeFalse())
+		})
+	})
+
+	g.Describe("APIMethod.CheckArgs", func() {
+		g.It("Should accept the method's parameters", func() {
+			m, _ := info.Method("echo")
+			o.Expect(m.CheckArgs(url.Values{"a": {"1"}, "b": {"2"}})).To(o.BeNil())
+		})
+
+		g.It("Should report missing and unknown parameters", func() {
+			m, _ := info.Method("echo")
+			err := m.CheckArgs(url.Values{"a": {"1"}, "d": {"2"}, "c": {"3"}})
+
+			o.Expect(err).NotTo(o.BeNil())
+			o.Expect(err.Error()).To(o.Equal(`missing parameter "b"; ` +
+				`unknown parameter "c"; unknown parameter "d"; expected a, b`))
+		})
+	})
+
+	g.Describe("Client.Call", func() {
+		var ts *httptest.Server
+		var cl *Client
+
+		g.BeforeEach(func() {
+			ts = httptest.NewTLSServer(http.HandlerFunc(func(
+				w http.ResponseWriter, r *http.Request) {
+
+				r.ParseForm()
+
+				resp, _ := json.Marshal(map[string]string{
+					"verb": r.Method,
+					"path": r.URL.Path,
+					"form": r.Form.Encode(),
+				})
+
+				fmt.Fprintf(w, `{"status": "completed", "response": %s}`, resp)
+			}))
+
+			cl = NewClient()
+			cl.SetBaseURL(ts.URL)
+		})
+
+		g.AfterEach(func() { ts.Close() })
+
+		g.It("Should call a method with its verb and our parameters", func() {
+			resp, err := cl.Call(info, "echo", url.Values{"a": {"x y"}, "b": {"2"}})
+			o.Expect(err).To(o.BeNil())
+
+			var echo map[string]string
+			o.Expect(json.Unmarshal(resp, &echo)).To(o.BeNil())
+			o.Expect(echo).To(o.Equal(map[string]string{
+				"verb": "POST",
+				"path": "/0/echo",
+				"form": "a=x+y&b=2",
+			}))
+		})
+
+		g.It("Should not call a method with wrong parameters", func() {
+			_, err := cl.Call(info, "whoami", url.Values{"a": {"1"}})
+			o.Expect(err).NotTo(o.BeNil())
+		})
+
+		g.It("Should not call an unknown method", func() {
+			_, err := cl.Call(info, "foo", url.Values{})
+			o.Expect(err).NotTo(o.BeNil())
+		})
+
+		g.It("Should call any method with CallVerb", func() {
+			resp, err := cl.CallVerb("get", "/foo", url.Values{"id": {"42"}})
+			o.Expect(err).To(o.BeNil())
+
+			var echo map[string]string
+			o.Expect(json.Unmarshal(resp, &echo)).To(o.BeNil())
+			o.Expect(echo).To(o.Equal(map[string]string{
+				"verb": "GET",
+				"path": "/0/foo",
+				"form": "id=42",
+			}))
+		})
+	})
+}
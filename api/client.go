@@ -16,6 +16,8 @@ package api
 // Most of the code here is just a high-level wrapper around the low-level
 // HTTP(S) client with checks for errors everywhere.
 
+import "strings"
+
 // Client is an API client
 type Client struct {
 	// username and password used for authentication
@@ -41,6 +43,12 @@ func (cl *Client) SetDebug(debug bool) {
 	cl.http.debug = debug
 }
 
+// SetBaseURL sets the base URL of the server this client talks to, e.g.
+// "http://localhost:8080/antroid". The API version is added after it.
+func (cl *Client) SetBaseURL(u string) {
+	cl.http.baseURL = strings.TrimSuffix(u, "/")
+}
+
 // getUserCredentialsParams returns the client's credentials (username and
 // password) as an UserCredentialsParams struct, which can then be passed to
 // the low-level HTTP(S) client.
@@ -57,6 +65,15 @@ func (cl *Client) Authenticated() bool {
 	return cl.authenticated
 }
 
+// Username returns the name of the user the client is logged as, or an empty
+// string if it's not logged
+func (cl *Client) Username() string {
+	if !cl.authenticated {
+		return ""
+	}
+	return cl.username
+}
+
 // APIInfo returns some info about the API. See `api/api_info.go` for the
 // returned struct.
 func (cl *Client) APIInfo() (info APIInfo, err error) {
@@ -148,7 +165,14 @@ func (cl *Client) Logout() (err error) {
 
 // CreateGame creates a new game and returns it. See `api/game_spec.go` for
 // what is a GameSpec struct (spoiler: it describes a game's params).
+//
+// The spec is checked before we call the remote server: an invalid one gives
+// SpecErrors with all its problems.
 func (cl *Client) CreateGame(gs *GameSpec) (g *Game, err error) {
+	if err = gs.Check(); err != nil {
+		return
+	}
+
 	body := cl.http.CallCreate(gs.toParams())
 
 	if err = body.Error(); err != nil {
@@ -238,11 +262,13 @@ func (cl *Client) GetGameIdentifierLog(id GameID) (gl GameLog, err error) {
 		return
 	}
 
-	// TODO
-	// the log was buggy when we wrote this part so this is still not
-	// implemented
+	var resp logResponse
 
-	return GameLog{}, ErrNotImplemented
+	if err = body.DumpTo(&resp); err == nil {
+		gl, err = resp.getLog(id)
+	}
+
+	return
 }
 
 // Play plays a game with a list of commands
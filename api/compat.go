@@ -0,0 +1,156 @@
+package api
+
+// This file describes a compatibility check between the remote API, as
+// described by its /api method, and this package: the methods we call, with
+// their verb and parameters, and the error codes we know.
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// a knownCall is an API method we call from `Httclient`
+type knownCall struct {
+	verb string
+	// the parameters struct we send
+	params interface{}
+}
+
+// knownCalls are all the methods `Httclient` calls, indexed by their name.
+// Keep it in sync with the `Call*` methods in `api/io.go`.
+var knownCalls = map[string]knownCall{
+	"api":      {get, NoParams{}},
+	"auth":     {post, UserCredentialsParams{}},
+	"create":   {get, GameSpecParams{}},
+	"destroy":  {get, GameIDParams{}},
+	"games":    {get, NoParams{}},
+	"join":     {get, GameIDParams{}},
+	"log":      {get, GameIDParams{}},
+	"logout":   {get, NoParams{}},
+	"play":     {get, PlayParams{}},
+	"register": {post, UserCredentialsParams{}},
+	"shutdown": {get, GenericIDParams{}},
+	"status":   {get, GameIDParams{}},
+	"whoami":   {get, NoParams{}},
+}
+
+// paramNames returns the names of the parameters we send, from the `url`
+// tags of a parameters struct, sorted
+func paramNames(params interface{}) []string {
+	names := []string{}
+
+	t := reflect.TypeOf(params)
+	for i := 0; i < t.NumField(); i++ {
+		if name := t.Field(i).Tag.Get("url"); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Params returns the names of the method's parameters, sorted. The API
+// describes each one as "name : type".
+func (m APIMethod) Params() []string {
+	names := []string{}
+
+	for _, in := range m.Input {
+		name := strings.TrimSpace(strings.SplitN(in, ":", 2)[0])
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// KnownError returns the error we return for an API error code, if we know
+// it
+func KnownError(code int) (error, bool) {
+	err, ok := errorCodes[code]
+	return err, ok
+}
+
+// kinds of incompatibilities
+const (
+	// the API has a method we don't call
+	MethodAdded = "added"
+	// we call a method the API doesn't have anymore
+	MethodRemoved = "removed"
+	// the verb or the parameters of a method changed
+	MethodChanged = "changed"
+	// a method can return an error code we don't know
+	UnknownErrorCode = "unknown error code"
+)
+
+// An Incompatibility is a difference between the remote API and this package
+type Incompatibility struct {
+	Method string
+	// one of the constants above
+	Kind string
+	// what's different, if the kind is not enough
+	Details string
+}
+
+func (inc Incompatibility) String() string {
+	if inc.Details == "" {
+		return fmt.Sprintf("%s: %s", inc.Method, inc.Kind)
+	}
+	return fmt.Sprintf("%s: %s (%s)", inc.Method, inc.Kind, inc.Details)
+}
+
+// Incompatibilities compares the remote API with the methods we call and the
+// error codes we know. It returns their differences sorted by method.
+func (info APIInfo) Incompatibilities() []Incompatibility {
+	var incs []Incompatibility
+
+	add := func(method, kind, format string, args ...interface{}) {
+		incs = append(incs, Incompatibility{method, kind, fmt.Sprintf(format, args...)})
+	}
+
+	for name, m := range info.Doc {
+		name = strings.TrimPrefix(name, "/")
+
+		if call, ok := knownCalls[name]; !ok {
+			add(name, MethodAdded, "")
+		} else {
+			if verb := strings.ToUpper(m.Verb); verb != call.verb {
+				add(name, MethodChanged, "verb %s instead of %s", verb, call.verb)
+			}
+
+			ours, theirs := paramNames(call.params), m.Params()
+			if !reflect.DeepEqual(ours, theirs) {
+				add(name, MethodChanged, "parameters %s instead of %s",
+					strings.Join(theirs, ", "), strings.Join(ours, ", "))
+			}
+		}
+
+		for _, e := range m.Errors {
+			if _, ok := errorCodes[e.Code]; !ok {
+				add(name, UnknownErrorCode, "%d %s", e.Code, e.Description)
+			}
+		}
+	}
+
+	for name := range knownCalls {
+		_, ok := info.Doc[name]
+		if _, slash := info.Doc["/"+name]; !ok && !slash {
+			add(name, MethodRemoved, "")
+		}
+	}
+
+	sort.SliceStable(incs, func(i, j int) bool {
+		if incs[i].Method != incs[j].Method {
+			return incs[i].Method < incs[j].Method
+		}
+		return incs[i].Details < incs[j].Details
+	})
+
+	return incs
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"github.com/franela/goblin"
+	o "github.com/onsi/gomega"
+	"testing"
+)
+
+func TestCompat(t *testing.T) {
+
+	g := goblin.Goblin(t)
+
+	o.RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	// liveAPI returns the API we expect, i.e. without incompatibilities
+	liveAPI := func() APIInfo {
+		info := APIInfo{Doc: make(map[string]APIMethod)}
+
+		for name, call := range knownCalls {
+			m := APIMethod{Verb: call.verb, Input: []string{}, Errors: []APIError{}}
+			for _, p := range paramNames(call.params) {
+				m.Input = append(m.Input, p+" : string")
+			}
+			info.Doc[name] = m
+		}
+
+		return info
+	}
+
+	g.Describe("APIMethod.Params", func() {
+		g.It("Should return the names of the parameters", func() {
+			m := APIMethod{Input: []string{"login : string", "id:int"}}
+			o.Expect(m.Params()).To(o.Equal([]string{"id", "login"}))
+		})
+	})
+
+	g.Describe("paramNames", func() {
+		g.It("Should return the url tags of a struct", func() {
+			o.Expect(paramNames(PlayParams{})).To(o.Equal([]string{"cmds", "id"}))
+			o.Expect(paramNames(NoParams{})).To(o.BeEmpty())
+		})
+	})
+
+	g.Describe("APIInfo.Incompatibilities", func() {
+		g.It("Should be empty if the API didn't change", func() {
+			o.Expect(liveAPI().Incompatibilities()).To(o.BeEmpty())
+		})
+
+		g.It("Should find added and removed methods", func() {
+			info := liveAPI()
+			delete(info.Doc, "log")
+			info.Doc["chat"] = APIMethod{Verb: "post"}
+
+			o.Expect(info.Incompatibilities()).To(o.Equal([]Incompatibility{
+				{Method: "chat", Kind: MethodAdded},
+				{Method: "log", Kind: MethodRemoved},
+			}))
+		})
+
+		g.It("Should find changed methods", func() {
+			info := liveAPI()
+			info.Doc["auth"] = APIMethod{Verb: "get", Input: []string{"login : string"}}
+
+			incs := info.Incompatibilities()
+			o.Expect(incs).To(o.HaveLen(2))
+			o.Expect(incs[0].Kind).To(o.Equal(MethodChanged))
+			o.Expect(incs[0].Details).To(o.Equal("parameters login instead of login, password"))
+			o.Expect(incs[1].Details).To(o.Equal("verb GET instead of POST"))
+		})
+
+		g.It("Should find unknown error codes", func() {
+			info := liveAPI()
+			m := info.Doc["play"]
+			m.Errors = []APIError{{Code: 4313039, Description: "INVALID_COMMAND"},
+				{Code: 42, Description: "TOO_FAST"}}
+			info.Doc["play"] = m
+
+			o.Expect(info.Incompatibilities()).To(o.Equal([]Incompatibility{
+				{Method: "play", Kind: UnknownErrorCode, Details: "42 TOO_FAST"},
+			}))
+		})
+	})
+}
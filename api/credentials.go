@@ -0,0 +1,197 @@
+package api
+
+// This file describes where we get the credentials of the command-line tool
+// from, and how we keep its session between two invocations so that it
+// doesn't have to log in and out each time.
+//
+// Credentials can be given in environment variables (ANTROID_LOGIN and
+// ANTROID_PASSWORD) or in a JSON file only its owner can read:
+//
+//     {"login": "ww", "password": "a"}
+//
+// A session is the server's cookies after we logged in. It's saved in a JSON
+// file with the URL of the server and our username, and can be resumed by
+// another client.
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+)
+
+// the environment variables of the credentials
+const (
+	LoginEnvVar    = "ANTROID_LOGIN"
+	PasswordEnvVar = "ANTROID_PASSWORD"
+)
+
+// Credentials are a login and a password. Both can be empty.
+type Credentials struct {
+	Login    string `json:"login"`
+	Password string `json:"password"`
+}
+
+// CredentialsFromEnv returns the credentials given in environment variables
+func CredentialsFromEnv() Credentials {
+	return Credentials{
+		Login:    os.Getenv(LoginEnvVar),
+		Password: os.Getenv(PasswordEnvVar),
+	}
+}
+
+// checkPrivate returns an error if a file can be read or written by other
+// users than its owner
+func checkPrivate(path string, fi os.FileInfo) error {
+	if fi.Mode().Perm()&0077 != 0 {
+		return fmt.Errorf("%s is accessible by other users, "+
+			"run `chmod 600 %s`", path, path)
+	}
+
+	return nil
+}
+
+// LoadCredentials reads credentials from a file. It returns empty
+// credentials if the file doesn't exist, and fails if other users than its
+// owner can access it.
+func LoadCredentials(path string) (c Credentials, err error) {
+	fi, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return c, nil
+	}
+	if err != nil {
+		return
+	}
+
+	if err = checkPrivate(path, fi); err != nil {
+		return
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	err = json.Unmarshal(data, &c)
+	return
+}
+
+// Inherit sets the fields of the credentials which are empty to the ones of
+// other credentials
+func (c *Credentials) Inherit(parent Credentials) {
+	if c.Login == "" {
+		c.Login = parent.Login
+	}
+	if c.Password == "" {
+		c.Password = parent.Password
+	}
+}
+
+// A Session is what we need to resume a logged client
+type Session struct {
+	// the base URL of the server
+	URL string `json:"url"`
+	// the user we're logged as
+	Username string `json:"username"`
+	// the server's cookies, by name
+	Cookies map[string]string `json:"cookies"`
+}
+
+// LoadSession reads a session saved in a file. It returns nil if there's
+// none. Like credentials, it fails if other users than its owner can access
+// the file.
+func LoadSession(path string) (*Session, error) {
+	fi, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkPrivate(path, fi); err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var s Session
+
+	if err = json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// Save writes the session in a file only its owner can read
+func (s *Session) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err = ioutil.WriteFile(path, append(data, '\n'), 0600); err != nil {
+		return err
+	}
+
+	// WriteFile doesn't change the permissions of an existing file
+	return os.Chmod(path, 0600)
+}
+
+// cookiesURL returns the URL we use to get and set the cookies of the API
+func (h *Httclient) cookiesURL() *url.URL {
+	u, _ := url.Parse(h.makeAPIURL("/"))
+	return u
+}
+
+// Session returns the session of a logged client, or nil if it's not logged
+func (cl *Client) Session() *Session {
+	if !cl.authenticated {
+		return nil
+	}
+
+	s := &Session{
+		URL:      cl.http.baseURL,
+		Username: cl.username,
+		Cookies:  make(map[string]string),
+	}
+
+	for _, c := range cl.http.cookies.Cookies(cl.http.cookiesURL()) {
+		s.Cookies[c.Name] = c.Value
+	}
+
+	return s
+}
+
+// Resume resumes a session. It returns false if it can't because it's for
+// another server or another user; `username` can be empty to accept any
+// user. The client is then authenticated, but if the session expired on the
+// server-side API calls will fail with ErrNotLogged.
+func (cl *Client) Resume(s *Session, username string) bool {
+	if s == nil || s.URL != cl.http.baseURL {
+		return false
+	}
+
+	if username != "" && s.Username != username {
+		return false
+	}
+
+	var cookies []*http.Cookie
+
+	for name, value := range s.Cookies {
+		cookies = append(cookies, &http.Cookie{Name: name, Value: value})
+	}
+
+	cl.http.cookies.SetCookies(cl.http.cookiesURL(), cookies)
+
+	cl.username = s.Username
+	cl.authenticated = true
+
+	return true
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"github.com/franela/goblin"
+	o "github.com/onsi/gomega"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCredentials(t *testing.T) {
+
+	g := goblin.Goblin(t)
+
+	o.RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	var dir string
+
+	g.Describe("LoadCredentials", func() {
+		g.BeforeEach(func() {
+			dir, _ = ioutil.TempDir("", "antroid-credentials")
+		})
+
+		g.AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		g.It("Should return empty credentials without file", func() {
+			c, err := LoadCredentials(filepath.Join(dir, "nope.json"))
+
+			o.Expect(err).To(o.BeNil())
+			o.Expect(c).To(o.Equal(Credentials{}))
+		})
+
+		g.It("Should read a private file", func() {
+			path := filepath.Join(dir, "credentials.json")
+			ioutil.WriteFile(path, []byte(`{"login": "foo", "password": "bar"}`), 0600)
+
+			c, err := LoadCredentials(path)
+
+			o.Expect(err).To(o.BeNil())
+			o.Expect(c).To(o.Equal(Credentials{Login: "foo", Password: "bar"}))
+		})
+
+		g.It("Should refuse a file other users can read", func() {
+			path := filepath.Join(dir, "credentials.json")
+			ioutil.WriteFile(path, []byte(`{"login": "foo", "password": "bar"}`), 0644)
+			os.Chmod(path, 0644)
+
+			_, err := LoadCredentials(path)
+			o.Expect(err).NotTo(o.BeNil())
+		})
+	})
+
+	g.Describe("Credentials.Inherit", func() {
+		g.It("Should only set the empty fields", func() {
+			c := Credentials{Login: "foo"}
+			c.Inherit(Credentials{Login: "bar", Password: "qux"})
+
+			o.Expect(c).To(o.Equal(Credentials{Login: "foo", Password: "qux"}))
+		})
+	})
+
+	g.Describe("Session", func() {
+		g.BeforeEach(func() {
+			dir, _ = ioutil.TempDir("", "antroid-credentials")
+		})
+
+		g.AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		g.It("Should be saved in a private file", func() {
+			path := filepath.Join(dir, "session.json")
+			s := &Session{URL: "http://x", Username: "foo",
+				Cookies: map[string]string{"id": "42"}}
+
+			o.Expect(s.Save(path)).To(o.BeNil())
+
+			fi, err := os.Stat(path)
+			o.Expect(err).To(o.BeNil())
+			o.Expect(fi.Mode().Perm()).To(o.Equal(os.FileMode(0600)))
+
+			loaded, err := LoadSession(path)
+			o.Expect(err).To(o.BeNil())
+			o.Expect(loaded).To(o.Equal(s))
+		})
+
+		g.It("Should be nil without file", func() {
+			s, err := LoadSession(filepath.Join(dir, "nope.json"))
+
+			o.Expect(err).To(o.BeNil())
+			o.Expect(s).To(o.BeNil())
+		})
+	})
+
+	g.Describe("Client", func() {
+		var cl *Client
+
+		g.BeforeEach(func() {
+			cl = NewClient()
+			cl.SetBaseURL("http://localhost/antroid")
+		})
+
+		g.It("Should not have a session if it's not logged", func() {
+			o.Expect(cl.Session()).To(o.BeNil())
+		})
+
+		g.It("Should resume a session with its cookies", func() {
+			cl.username = "foo"
+			cl.authenticated = true
+			cl.http.cookies.SetCookies(cl.http.cookiesURL(),
+				[]*http.Cookie{{Name: "id", Value: "42"}})
+
+			s := cl.Session()
+			o.Expect(s.Cookies).To(o.Equal(map[string]string{"id": "42"}))
+
+			other := NewClient()
+			other.SetBaseURL("http://localhost/antroid")
+
+			o.Expect(other.Resume(s, "bar")).To(o.BeFalse())
+			o.Expect(other.Authenticated()).To(o.BeFalse())
+
+			o.Expect(other.Resume(s, "")).To(o.BeTrue())
+			o.Expect(other.Authenticated()).To(o.BeTrue())
+
+			u := other.http.cookiesURL()
+			u.Path += "status"
+			o.Expect(other.http.cookies.Cookies(u)).To(o.HaveLen(1))
+		})
+
+		g.It("Should not resume a session of another server", func() {
+			s := &Session{URL: "http://elsewhere", Username: "foo"}
+			o.Expect(cl.Resume(s, "")).To(o.BeFalse())
+		})
+	})
+}
@@ -0,0 +1,147 @@
+package api
+
+// This file describes a learning environment over the game loop, with the
+// same interface as OpenAI's Gym: `Reset` starts a new game and returns what
+// we observe, then each call to `Step` plays a turn with the given commands
+// and returns the new observation, the reward (how much our score changed)
+// and whether the game is over. Observations are described in
+// `api/observation.go`.
+//
+// The environment is a wrapper around a `Player`: it doesn't have any AI
+// since we give it the commands ourselves, but it can still have listeners or
+// a journal. `antroid env` exposes it as a line-based protocol on stdin and
+// stdout so it can be driven from other languages.
+
+// Default size of the observations' planes
+const (
+	DefaultEnvWidth  = 64
+	DefaultEnvHeight = 64
+)
+
+// An Env is a learning environment playing games on the remote server
+type Env struct {
+	// the credentials of the player
+	username, password string
+	// the size of the observations' planes
+	width, height int
+
+	// the server we use, or an empty string for the default one
+	baseURL string
+	debug   bool
+
+	// the player of the current game, if any
+	player *Player
+	// our score after the last turn
+	score int
+}
+
+// NewEnv returns a pointer on a new environment which plays with the given
+// credentials and returns observations of the given size. The account is
+// registered if it doesn't exist yet.
+func NewEnv(username, password string, width, height int) *Env {
+	return &Env{
+		username: username,
+		password: password,
+		width:    width,
+		height:   height,
+	}
+}
+
+// SetBaseURL sets the URL of the server, e.g. a local one. See
+// `Client.SetBaseURL`. It's used from the next call to `Reset`.
+func (e *Env) SetBaseURL(u string) {
+	e.baseURL = u
+}
+
+// SetDebug enables/disables the debug mode. It's used from the next call to
+// `Reset`.
+func (e *Env) SetDebug(debug bool) {
+	e.debug = debug
+}
+
+// Player returns the player of the current game, or nil if there's none. It
+// can be used to add listeners or a journal before the first turn.
+func (e *Env) Player() *Player {
+	return e.player
+}
+
+// Reset ends the current game, if any, then creates and joins a new one with
+// the given spec. It returns the observation of its first turn.
+func (e *Env) Reset(gs *GameSpec) (*Observation, error) {
+	e.Close()
+
+	p := NewPlayer(e.username, e.password)
+	p.SetDebug(e.debug)
+
+	if e.baseURL != "" {
+		p.Client.SetBaseURL(e.baseURL)
+	}
+
+	if err := p.Connect(); err != nil {
+		return nil, err
+	}
+
+	if err := p.CreateAndJoinGame(gs); err != nil {
+		// don't stay logged in with AIs running until the next reset
+		p.Quit()
+		return nil, err
+	}
+
+	e.player = p
+
+	// this updates the map and the enemies with what we saw at this turn
+	p.sendTurnStatusToAIs()
+
+	o := p.observe(e.width, e.height)
+	e.score = o.Score
+
+	return o, nil
+}
+
+// Step plays one turn with the given commands, e.g. "0:forward,1:rest". It
+// returns the new observation, the reward, i.e. the difference between our
+// score before and after the turn, and true if the game is over. It fails
+// with ErrGameNotPlaying if there's no game or if it's over.
+func (e *Env) Step(cmds Commands) (o *Observation, reward float64, done bool, err error) {
+	p := e.player
+
+	if p == nil || p.Done() {
+		err = ErrGameNotPlaying
+		return
+	}
+
+	if err = p.play(cmds); err != nil {
+		if err != ErrGameNotPlaying {
+			return
+		}
+
+		// the game ended during this turn; we still want the final score
+		p.done = true
+		if err = p.updateStatus(); err != nil {
+			return
+		}
+	} else {
+		p.sendTurnStatusToAIs()
+	}
+
+	o = p.observe(e.width, e.height)
+
+	reward = float64(o.Score - e.score)
+	e.score = o.Score
+
+	done = p.Done()
+
+	return
+}
+
+// Close ends the current game, if any, and logs out
+func (e *Env) Close() error {
+	if e.player == nil {
+		return nil
+	}
+
+	err := e.player.Quit()
+	e.player = nil
+
+	return err
+}
@@ -0,0 +1,226 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/franela/goblin"
+	o "github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newFakeGameServer returns a fake remote server with one game for one
+// player, "foo", and one ant. The ant goes east on a line of grass with sugar
+// at (3, 0) and each "forward" is worth one point. The game has 3 turns.
+func newFakeGameServer() *httptest.Server {
+	turn, x, score := 0, 0, 0
+
+	completed := func(w http.ResponseWriter, resp string) {
+		fmt.Fprintf(w, `{"status": "completed", "response": %s}`, resp)
+	}
+
+	return httptest.NewTLSServer(http.HandlerFunc(func(
+		w http.ResponseWriter, r *http.Request) {
+
+		r.ParseForm()
+
+		switch r.URL.Path {
+		case "/0/register", "/0/auth", "/0/logout":
+			completed(w, "{}")
+
+		case "/0/create":
+			turn, x, score = 0, 0, 0
+			completed(w, `{"identifier": "g1"}`)
+
+		case "/0/join":
+			completed(w, "{}")
+
+		case "/0/status":
+			status := "playing"
+			if turn >= 3 {
+				status = "over"
+			}
+			completed(w, fmt.Sprintf(`{"status": {"creator": "foo",
+				"visibility": "public", "nb_ant_per_player": 1,
+				"players": ["foo"], "score": {"foo": %d},
+				"status": {"status": %q}, "turn": %d}}`, score, status, turn))
+
+		case "/0/play":
+			if turn >= 3 {
+				fmt.Fprint(w, `{"status": "error", "response": {
+					"error_code": 357629463, "error_msg": "over"}}`)
+				return
+			}
+
+			if strings.Contains(r.Form.Get("cmds"), "0:forward") {
+				x++
+				score++
+			}
+			turn++
+
+			var cells []string
+			for cx := x - 1; cx <= x+1; cx++ {
+				kind := `{"kind": "grass"}`
+				if cx == 3 {
+					kind = `{"kind": "food", "level": "sugar"}`
+				}
+				cells = append(cells, fmt.Sprintf(`{"x": %d, "y": 0, "content": %s}`, cx, kind))
+			}
+
+			completed(w, fmt.Sprintf(`{"turn": %d, "observations": [[
+				{"id": 0, "x": %d, "y": 0, "dx": 1, "dy": 0, "energy": 90,
+				 "acid": 80, "brain": "controlled"},
+				[%s],
+				[{"x": %d, "y": 0, "dx": 1, "dy": 0, "brain": "controlled"}]
+			]]}`, turn, x, strings.Join(cells, ", "), x))
+
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+}
+
+// testSpec returns the spec of the fake server's game
+func testSpec() *GameSpec {
+	return &GameSpec{
+		Public:        true,
+		Pace:          1,
+		Turns:         3,
+		AntsPerPlayer: 1,
+		MaxPlayers:    1,
+		MinPlayers:    1,
+		InitialEnergy: 100,
+		InitialAcid:   100,
+	}
+}
+
+func TestEnv(t *testing.T) {
+
+	g := goblin.Goblin(t)
+
+	o.RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	g.Describe("Observation", func() {
+		g.It("Should have fixed sizes", func() {
+			obs := NewObservation(4, 3, 2)
+
+			o.Expect(obs.Shape).To(o.Equal([3]int{Planes, 3, 4}))
+			o.Expect(obs.Planes).To(o.HaveLen(Planes * 12))
+			o.Expect(obs.Ants).To(o.HaveLen(2 * AntFeatures))
+		})
+
+		g.It("Should ignore cells outside of the planes", func() {
+			obs := NewObservation(4, 3, 1)
+			obs.set(PlaneRock, Position{X: 4, Y: 0})
+			obs.set(PlaneRock, Position{X: 3, Y: 2})
+
+			o.Expect(obs.Plane(PlaneRock, 3, 2)).To(o.Equal(float32(1)))
+			o.Expect(obs.Plane(PlaneRock, 4, 0)).To(o.Equal(float32(0)))
+			o.Expect(obs.Planes[(PlaneRock*3+2)*4+3]).To(o.Equal(float32(1)))
+		})
+	})
+
+	g.Describe("Env", func() {
+		var ts *httptest.Server
+		var env *Env
+
+		g.BeforeEach(func() {
+			ts = newFakeGameServer()
+			env = NewEnv("foo", "bar", 8, 4)
+			env.SetBaseURL(ts.URL)
+		})
+
+		g.AfterEach(func() {
+			env.Close()
+			ts.Close()
+		})
+
+		g.It("Should reject invalid specs before creating a game", func() {
+			gs := testSpec()
+			gs.Pace = 0
+
+			_, err := env.Reset(gs)
+			o.Expect(err).To(o.BeAssignableToTypeOf(SpecErrors{}))
+			o.Expect(env.Player()).To(o.BeNil())
+		})
+
+		g.It("Should log out if it can't create the game", func() {
+			logouts := 0
+
+			failing := httptest.NewTLSServer(http.HandlerFunc(func(
+				w http.ResponseWriter, r *http.Request) {
+
+				switch r.URL.Path {
+				case "/0/create":
+					fmt.Fprint(w, `{"status": "error", "response": {
+						"error_code": 677388348, "error_msg": "no"}}`)
+					return
+				case "/0/logout":
+					logouts++
+				}
+
+				fmt.Fprint(w, `{"status": "completed", "response": {}}`)
+			}))
+			defer failing.Close()
+
+			env.SetBaseURL(failing.URL)
+
+			_, err := env.Reset(testSpec())
+			o.Expect(errors.Is(err, ErrInvalidArgument)).To(o.BeTrue())
+			o.Expect(logouts).To(o.Equal(1))
+			o.Expect(env.Player()).To(o.BeNil())
+		})
+
+		g.It("Should fail to step without game", func() {
+			_, _, _, err := env.Step("0:rest")
+			o.Expect(err).To(o.Equal(ErrGameNotPlaying))
+		})
+
+		g.It("Should observe the first turn on reset", func() {
+			obs, err := env.Reset(testSpec())
+
+			o.Expect(err).To(o.BeNil())
+			o.Expect(obs.Turn).To(o.Equal(1))
+			o.Expect(obs.Ant(0, AntAlive)).To(o.Equal(float32(1)))
+			o.Expect(obs.Ant(0, AntEnergy)).To(o.Equal(float32(90)))
+			o.Expect(obs.Ant(0, AntControlled)).To(o.Equal(float32(1)))
+			o.Expect(obs.Plane(PlaneAnts, 0, 0)).To(o.Equal(float32(1)))
+			o.Expect(obs.Plane(PlaneGrass, 1, 0)).To(o.Equal(float32(1)))
+			o.Expect(obs.Plane(PlaneKnown, 2, 0)).To(o.Equal(float32(0)))
+		})
+
+		g.It("Should reward the score changes until the end of the game", func() {
+			_, err := env.Reset(testSpec())
+			o.Expect(err).To(o.BeNil())
+
+			obs, reward, done, err := env.Step("0:forward")
+			o.Expect(err).To(o.BeNil())
+			o.Expect(reward).To(o.Equal(1.0))
+			o.Expect(done).To(o.BeFalse())
+			o.Expect(obs.Ant(0, AntX)).To(o.Equal(float32(1)))
+			o.Expect(obs.Plane(PlaneFood, 2, 0)).To(o.Equal(float32(0)))
+
+			obs, reward, done, err = env.Step("0:forward")
+			o.Expect(err).To(o.BeNil())
+			o.Expect(reward).To(o.Equal(1.0))
+			o.Expect(done).To(o.BeTrue())
+			o.Expect(obs.Score).To(o.Equal(2))
+			o.Expect(obs.Plane(PlaneFood, 3, 0)).To(o.Equal(float32(1)))
+
+			_, _, _, err = env.Step("0:rest")
+			o.Expect(err).To(o.Equal(ErrGameNotPlaying))
+		})
+
+		g.It("Should start a new game on reset", func() {
+			env.Reset(testSpec())
+			env.Step("0:forward")
+
+			obs, err := env.Reset(testSpec())
+			o.Expect(err).To(o.BeNil())
+			o.Expect(obs.Score).To(o.Equal(0))
+			o.Expect(obs.Ant(0, AntX)).To(o.Equal(float32(0)))
+		})
+	})
+}
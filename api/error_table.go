@@ -0,0 +1,205 @@
+package api
+
+// This file describes error tables, which map the API's error codes to their
+// description as given by /api. `errorCodes` in `api/errors.go` only has the
+// codes we knew when we wrote this package; with a table loaded from the
+// server (see `Client.LoadErrors`), the codes it added give a CodeError with
+// their description instead of a bare ErrUnknownCode.
+//
+// A table can be cached in a JSON file so that we don't have to call /api
+// each time.
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"time"
+)
+
+// errorNames maps the names the API gives to its errors to ours, so that a
+// code which changed still gives the error of the same name
+var errorNames = map[string]error{
+	"UNKNOWN_USER":            ErrUnknownUser,
+	"INVALID_COMMAND":         ErrWrongCmd,
+	"ALREADY_JOINED":          ErrAlreadyJoined,
+	"GAME_IS_NOT_PLAYING":     ErrGameNotPlaying,
+	"USER_ALREADY_EXISTS":     ErrUserAlreadyExists,
+	"MUST_BE_LOGGED":          ErrNotLogged,
+	"MUST_JOIN_FIRST":         ErrMustJoin,
+	"INVALID_ANT_IDENTIFIER":  ErrWrongAnt,
+	"GAME_IS_NOT_OVER":        ErrGameNotOver,
+	"INVALID_LOGIN":           ErrInvalidLogin,
+	"NO_MORE_SLOT":            ErrNoMoreSlot,
+	"INVALID_GAME_IDENTIFIER": ErrWrongGame,
+	"INVALID_ARGUMENT":        ErrInvalidArgument,
+	"NO_PERMISSION":           ErrNoPerm,
+}
+
+// A CodeError is an error from the server whose code is not in `errorCodes`
+type CodeError struct {
+	Code int
+	// its name from the error table, e.g. "NO_MORE_SLOT", if we have one
+	Description string
+	// the message the server sent with it, if any
+	Message string
+}
+
+func (e *CodeError) Error() string {
+	desc := e.Description
+	if desc == "" {
+		desc = ErrUnknownCode.Error()
+	}
+
+	if e.Message != "" {
+		return fmt.Sprintf("%s (%d): %s", desc, e.Code, e.Message)
+	}
+
+	return fmt.Sprintf("%s (%d)", desc, e.Code)
+}
+
+// Unwrap returns our error of the same name, if any, or ErrUnknownCode. This
+// makes `errors.Is(err, ErrNoMoreSlot)` work even if the server changed the
+// code of NO_MORE_SLOT.
+func (e *CodeError) Unwrap() error {
+	if err, ok := errorNames[e.Description]; ok {
+		return err
+	}
+
+	return ErrUnknownCode
+}
+
+// An ErrorTable maps error codes to their description
+type ErrorTable map[int]string
+
+// ErrorTableFromAPI returns the table of all the errors the API's methods can
+// return
+func ErrorTableFromAPI(info APIInfo) ErrorTable {
+	t := make(ErrorTable)
+
+	for _, m := range info.Doc {
+		for _, e := range m.Errors {
+			t[e.Code] = e.Description
+		}
+	}
+
+	return t
+}
+
+// errorFor returns the error for a code and the message the server sent with
+// it. Codes in `errorCodes` give the same errors as before; other ones give a
+// CodeError, unless we don't know anything about them.
+func (t ErrorTable) errorFor(code int, msg string) error {
+	if err := errorForCode(code); err != ErrUnknownCode {
+		return err
+	}
+
+	desc, ok := t[code]
+	if !ok && msg == "" {
+		return ErrUnknownCode
+	}
+
+	return &CodeError{Code: code, Description: desc, Message: msg}
+}
+
+// the format of a cached table: JSON objects can only have string keys. Each
+// server has its own codes, so we keep the URL of the one which gave the
+// table.
+type errorTableFile struct {
+	URL    string            `json:"url,omitempty"`
+	Errors map[string]string `json:"errors"`
+}
+
+// LoadErrorTable reads a table cached in a file
+func LoadErrorTable(path string) (ErrorTable, error) {
+	t, _, err := readErrorTable(path)
+	return t, err
+}
+
+// readErrorTable reads a table cached in a file, and the URL of the server it
+// comes from
+func readErrorTable(path string) (ErrorTable, string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var f errorTableFile
+
+	if err = json.Unmarshal(data, &f); err != nil {
+		return nil, "", err
+	}
+
+	t := make(ErrorTable)
+
+	for code, desc := range f.Errors {
+		n, err := strconv.Atoi(code)
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: bad error code %q", path, code)
+		}
+		t[n] = desc
+	}
+
+	return t, f.URL, nil
+}
+
+// Save writes the table in a file
+func (t ErrorTable) Save(path string) error {
+	return t.save(path, "")
+}
+
+// save writes the table in a file with the URL of the server it comes from
+func (t ErrorTable) save(path, url string) error {
+	f := errorTableFile{URL: url, Errors: make(map[string]string)}
+
+	for code, desc := range t {
+		f.Errors[strconv.Itoa(code)] = desc
+	}
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Errors returns the error table of the client. It's nil until it's loaded.
+func (cl *Client) Errors() ErrorTable {
+	return cl.http.errors
+}
+
+// SetErrors sets the error table of the client
+func (cl *Client) SetErrors(t ErrorTable) {
+	cl.http.errors = t
+}
+
+// LoadErrors loads the error table from the server's /api. If `cache` is not
+// empty, the table is read from this file if it was written less than
+// `maxAge` ago for the same server, and written in it otherwise.
+func (cl *Client) LoadErrors(cache string, maxAge time.Duration) error {
+	if cache != "" {
+		if fi, err := os.Stat(cache); err == nil && time.Since(fi.ModTime()) < maxAge {
+			t, u, err := readErrorTable(cache)
+			if err == nil && u == cl.http.baseURL {
+				cl.SetErrors(t)
+				return nil
+			}
+		}
+	}
+
+	info, err := cl.APIInfo()
+	if err != nil {
+		return err
+	}
+
+	t := ErrorTableFromAPI(info)
+	cl.SetErrors(t)
+
+	if cache != "" {
+		return t.save(cache, cl.http.baseURL)
+	}
+
+	return nil
+}
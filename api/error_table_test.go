@@ -0,0 +1,151 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/franela/goblin"
+	o "github.com/onsi/gomega"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestErrorTable(t *testing.T) {
+
+	g := goblin.Goblin(t)
+
+	o.RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	table := ErrorTable{
+		42:        "TOO_FAST",
+		43:        "NO_MORE_SLOT",
+		502441794: "UNKNOWN_USER",
+	}
+
+	g.Describe("ErrorTable.errorFor", func() {
+		g.It("Should return our errors for the codes we know", func() {
+			o.Expect(table.errorFor(502441794, "")).To(o.Equal(ErrUnknownUser))
+			o.Expect(ErrorTable(nil).errorFor(502441794, "")).To(o.Equal(ErrUnknownUser))
+		})
+
+		g.It("Should describe the codes we don't know", func() {
+			err := table.errorFor(42, "slow down")
+
+			o.Expect(err).To(o.Equal(&CodeError{Code: 42, Description: "TOO_FAST",
+				Message: "slow down"}))
+			o.Expect(err.Error()).To(o.Equal("TOO_FAST (42): slow down"))
+			o.Expect(errors.Is(err, ErrUnknownCode)).To(o.BeTrue())
+		})
+
+		g.It("Should match our errors by their name", func() {
+			err := table.errorFor(43, "")
+
+			o.Expect(errors.Is(err, ErrNoMoreSlot)).To(o.BeTrue())
+			o.Expect(errors.Is(err, ErrUnknownCode)).To(o.BeFalse())
+		})
+
+		g.It("Should return ErrUnknownCode if we don't know anything", func() {
+			o.Expect(table.errorFor(-1, "")).To(o.Equal(ErrUnknownCode))
+		})
+	})
+
+	g.Describe("ErrorTableFromAPI", func() {
+		g.It("Should collect the errors of all methods", func() {
+			info := APIInfo{Doc: map[string]APIMethod{
+				"a": {Errors: []APIError{{Code: 1, Description: "ONE"}}},
+				"b": {Errors: []APIError{{Code: 2, Description: "TWO"},
+					{Code: 1, Description: "ONE"}}},
+			}}
+
+			o.Expect(ErrorTableFromAPI(info)).To(o.Equal(ErrorTable{1: "ONE", 2: "TWO"}))
+		})
+	})
+
+	g.Describe("Client", func() {
+		var ts *httptest.Server
+		var cl *Client
+		var dir string
+		var apiCalls int
+
+		g.BeforeEach(func() {
+			apiCalls = 0
+			dir, _ = ioutil.TempDir("", "antroid-errors")
+
+			ts = httptest.NewTLSServer(http.HandlerFunc(func(
+				w http.ResponseWriter, r *http.Request) {
+
+				switch r.URL.Path {
+				case "/0/api":
+					apiCalls++
+					fmt.Fprint(w, `{"status": "completed", "response": {"doc": {
+						"join": {"method": "get", "input": [], "description": "",
+						         "errors": [{"code": 42, "description": "TOO_FAST"}]}}}}`)
+				default:
+					fmt.Fprint(w, `{"status": "error", "response": {
+						"error_code": 42, "error_msg": "slow down"}}`)
+				}
+			}))
+
+			cl = NewClient()
+			cl.SetBaseURL(ts.URL)
+		})
+
+		g.AfterEach(func() {
+			ts.Close()
+			os.RemoveAll(dir)
+		})
+
+		g.It("Should give unknown codes without table", func() {
+			err := cl.JoinGameIdentifier("g1")
+
+			o.Expect(errors.Is(err, ErrUnknownCode)).To(o.BeTrue())
+			o.Expect(err.(*CodeError).Description).To(o.Equal(""))
+		})
+
+		g.It("Should describe the errors with the table of the server", func() {
+			o.Expect(cl.LoadErrors("", 0)).To(o.BeNil())
+
+			err := cl.JoinGameIdentifier("g1")
+			o.Expect(err.Error()).To(o.Equal("TOO_FAST (42): slow down"))
+		})
+
+		g.It("Should cache the table", func() {
+			cache := filepath.Join(dir, "errors.json")
+
+			o.Expect(cl.LoadErrors(cache, time.Hour)).To(o.BeNil())
+			o.Expect(apiCalls).To(o.Equal(1))
+
+			other := NewClient()
+			other.SetBaseURL(ts.URL)
+
+			o.Expect(other.LoadErrors(cache, time.Hour)).To(o.BeNil())
+			o.Expect(apiCalls).To(o.Equal(1))
+			o.Expect(other.Errors()).To(o.Equal(ErrorTable{42: "TOO_FAST"}))
+
+			// the cache is too old
+			o.Expect(other.LoadErrors(cache, 0)).To(o.BeNil())
+			o.Expect(apiCalls).To(o.Equal(2))
+		})
+
+		g.It("Should not use the cache of another server", func() {
+			cache := filepath.Join(dir, "errors.json")
+
+			o.Expect(ErrorTable{1: "OTHER"}.Save(cache)).To(o.BeNil())
+
+			o.Expect(cl.LoadErrors(cache, time.Hour)).To(o.BeNil())
+			o.Expect(apiCalls).To(o.Equal(1))
+			o.Expect(cl.Errors()).To(o.Equal(ErrorTable{42: "TOO_FAST"}))
+
+			// the cache is now the one of this server
+			other := NewClient()
+			other.SetBaseURL(ts.URL)
+
+			o.Expect(other.LoadErrors(cache, time.Hour)).To(o.BeNil())
+			o.Expect(apiCalls).To(o.Equal(1))
+		})
+	})
+}
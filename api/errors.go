@@ -32,6 +32,14 @@ var (
 	ErrEmptyBody      = errors.New("Unexpected empty response body")
 	ErrNotImplemented = errors.New("Not implemented")
 
+	// This error is returned when we can't parse a message from the game
+	// server (see `api/messages.go`).
+	ErrBadMessage = errors.New("Malformed message")
+
+	// This error is returned when we don't know how to connect to or listen
+	// on an AI endpoint (see `api/remote.go`).
+	ErrUnknownEndpoint = errors.New("Unknown endpoint")
+
 	// This error is returned when the remote server returns an error with a
 	// status we don't understand (nor "completed" nor "errors"). This
 	// shouldn't happen in practice.
@@ -37,8 +37,28 @@ type GameStatus struct {
 	Players []string
 }
 
-// A GameLog is a log from a game. We haven't implemented it yet.
-type GameLog struct{}
+// statuses of a game which started
+const (
+	playingStatus = "playing"
+	overStatus    = "over"
+)
+
+// A GameLog is the log of a game: what each player saw at each turn. It can
+// be replayed like a journal, see `GameLog.Journal`.
+type GameLog struct {
+	Game  GameID
+	Turns []LoggedTurn
+}
+
+// A LoggedTurn is one turn of a GameLog
+type LoggedTurn struct {
+	// its number
+	Number int
+	// the scoreboard at this turn (map username => score)
+	Score map[string]int
+	// what each player saw at this turn (map username => turn)
+	Players map[string]*Turn
+}
 
 // This is an internal helpers which constructs a GameStatus struct from a
 // response from the remote server
@@ -49,7 +69,10 @@ func gameStatusFromResponse(id GameID, resp gameStatusResponse) (*GameStatus, er
 	sp := GameSpec{
 		Description:   resp.Teaser,
 		Pace:          resp.Pace,
+		Turns:         resp.NbTurn,
 		AntsPerPlayer: resp.NbAntPerPlayer,
+		MaxPlayers:    resp.NbPlayer,
+		MinPlayers:    resp.MinimalNbPlayer,
 		InitialEnergy: resp.InitialEnergy,
 		InitialAcid:   resp.InitialAcid,
 	}
@@ -1,6 +1,9 @@
 package api
 
-import "strings"
+import (
+	"fmt"
+	"strings"
+)
 
 // GameSpec represents all the parameters needed to define a game
 type GameSpec struct {
@@ -42,6 +45,35 @@ func (gs *GameSpec) toParams() (gsp GameSpecParams) {
 	return
 }
 
+// Inherit sets the fields of the spec which are not set to the ones of
+// another spec, e.g. the one from the command-line flags. A spec which is
+// neither public nor restricted to some players inherits its visibility.
+func (gs *GameSpec) Inherit(parent GameSpec) {
+	if gs.Description == "" {
+		gs.Description = parent.Description
+	}
+
+	if !gs.Public && len(gs.Players) == 0 {
+		gs.Public = parent.Public
+		gs.Players = parent.Players
+	}
+
+	inheritInt(&gs.Pace, parent.Pace)
+	inheritInt(&gs.Turns, parent.Turns)
+	inheritInt(&gs.AntsPerPlayer, parent.AntsPerPlayer)
+	inheritInt(&gs.MaxPlayers, parent.MaxPlayers)
+	inheritInt(&gs.MinPlayers, parent.MinPlayers)
+	inheritInt(&gs.InitialEnergy, parent.InitialEnergy)
+	inheritInt(&gs.InitialAcid, parent.InitialAcid)
+}
+
+// inheritInt sets an integer to its parent's value if it's zero
+func inheritInt(n *int, parent int) {
+	if *n == 0 {
+		*n = parent
+	}
+}
+
 // The code below this comment is only used to validate a `GameSpec`, to ensure
 // all parameters have valid values.
 
@@ -72,29 +104,103 @@ var (
 	initialAcidRange   = IntRange(1, 1000)
 )
 
-// Validate checks that the spec validates against the spec spec (yes, there's
-// a spec for specs).
-func (gs *GameSpec) Validate() bool {
+// A SpecError is a problem with one field of a GameSpec
+type SpecError struct {
+	// the name of the field, e.g. "Pace"
+	Field string
+	// its value
+	Value interface{}
+	// the bounds of the values the field can take, if it's an integer and
+	// there's no Reason
+	Min, Max int
+	// what's wrong, if it's not out of its range
+	Reason string
+}
+
+func (e *SpecError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("%s: %s", e.Field, e.Reason)
+	}
+
+	return fmt.Sprintf("%s: %v is not between %d and %d", e.Field, e.Value,
+		e.Min, e.Max)
+}
+
+// SpecErrors are all the problems of a GameSpec
+type SpecErrors []*SpecError
+
+func (errs SpecErrors) Error() string {
+	var msgs []string
+
+	for _, e := range errs {
+		msgs = append(msgs, e.Error())
+	}
+
+	return "Invalid game spec: " + strings.Join(msgs, "; ")
+}
+
+// Is makes `errors.Is(err, ErrInvalidArgument)` work with an invalid spec,
+// like the error the remote server would have given us
+func (errs SpecErrors) Is(target error) bool {
+	return target == ErrInvalidArgument
+}
+
+// Check checks that the spec validates against the spec spec (yes, there's a
+// spec for specs). It returns nil if it does and SpecErrors with all the
+// problems otherwise.
+func (gs *GameSpec) Check() error {
+	var errs SpecErrors
+
 	nbUsers := len(gs.Players)
 
 	// games are either public or private. In the later case they must have 1+
 	// players.
-	if (gs.Public && nbUsers > 0) || (!gs.Public && nbUsers == 0) {
-		return false
+	if gs.Public && nbUsers > 0 {
+		errs = append(errs, &SpecError{Field: "Players", Value: gs.Players,
+			Reason: "a public game can't be restricted to some players"})
+	} else if !gs.Public && nbUsers == 0 {
+		errs = append(errs, &SpecError{Field: "Players", Value: gs.Players,
+			Reason: "a private game needs at least one player"})
 	}
 
 	// Note: the API accepts empty teasers
 
-	if !paceRange.Include(gs.Pace) ||
-		!turnsRange.Include(gs.Turns) ||
-		!antsPerPlayerRange.Include(gs.AntsPerPlayer) ||
-		!playersRange.Include(gs.MinPlayers) ||
-		!playersRange.Include(gs.MaxPlayers) ||
-		gs.MinPlayers > gs.MaxPlayers ||
-		!initialEnergyRange.Include(gs.InitialEnergy) ||
-		!initialAcidRange.Include(gs.InitialAcid) {
-		return false
+	fields := []struct {
+		name  string
+		value int
+		r     intRange
+	}{
+		{"Pace", gs.Pace, paceRange},
+		{"Turns", gs.Turns, turnsRange},
+		{"AntsPerPlayer", gs.AntsPerPlayer, antsPerPlayerRange},
+		{"MaxPlayers", gs.MaxPlayers, playersRange},
+		{"MinPlayers", gs.MinPlayers, playersRange},
+		{"InitialEnergy", gs.InitialEnergy, initialEnergyRange},
+		{"InitialAcid", gs.InitialAcid, initialAcidRange},
 	}
 
-	return true
+	for _, f := range fields {
+		if !f.r.Include(f.value) {
+			errs = append(errs, &SpecError{Field: f.name, Value: f.value,
+				Min: f.r.min, Max: f.r.max})
+		}
+	}
+
+	if gs.MinPlayers > gs.MaxPlayers {
+		errs = append(errs, &SpecError{Field: "MinPlayers", Value: gs.MinPlayers,
+			Reason: fmt.Sprintf("%d is more than MaxPlayers (%d)",
+				gs.MinPlayers, gs.MaxPlayers)})
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// Validate returns true if the spec validates. See `Check` to know why it
+// doesn't.
+func (gs *GameSpec) Validate() bool {
+	return gs.Check() == nil
 }
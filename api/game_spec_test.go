@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"github.com/franela/goblin"
 	o "github.com/onsi/gomega"
 	"testing"
@@ -18,6 +19,28 @@ func TestGameSpec(t *testing.T) {
 		})
 	})
 
+	g.Describe("GameSpec.Inherit", func() {
+		g.It("Should only set the fields which are not set", func() {
+			gs := GameSpec{Turns: 42, Players: []string{"foo"}}
+			gs.Inherit(GameSpec{Public: true, Turns: 10, Pace: 3, Description: "a"})
+
+			o.Expect(gs).To(o.Equal(GameSpec{
+				Turns:       42,
+				Pace:        3,
+				Players:     []string{"foo"},
+				Description: "a",
+			}))
+		})
+
+		g.It("Should inherit the visibility", func() {
+			gs := GameSpec{}
+			gs.Inherit(GameSpec{Players: []string{"foo", "bar"}})
+
+			o.Expect(gs.Public).To(o.BeFalse())
+			o.Expect(gs.Players).To(o.Equal([]string{"foo", "bar"}))
+		})
+	})
+
 	g.Describe("intRange", func() {
 		g.Describe(".Include(n)", func() {
 			var r intRange
@@ -74,6 +97,11 @@ func TestGameSpec(t *testing.T) {
 				gs := GameSpec{Public: true, Pace: -12}
 				o.Expect(gs.Validate()).To(o.BeFalse())
 			})
+			g.It("Should return false if there are more min players than max players", func() {
+				gs := GameSpec{Public: true, Pace: 1, Turns: 1, AntsPerPlayer: 1,
+					MaxPlayers: 2, MinPlayers: 3, InitialEnergy: 1, InitialAcid: 1}
+				o.Expect(gs.Validate()).To(o.BeFalse())
+			})
 			g.It("Should return true all parameters are in the range", func() {
 				gs := GameSpec{
 					Public:        true,
@@ -88,5 +116,37 @@ func TestGameSpec(t *testing.T) {
 				o.Expect(gs.Validate()).To(o.BeTrue())
 			})
 		})
+
+		g.Describe(".Check()", func() {
+			g.It("Should return nil if the spec is valid", func() {
+				gs := GameSpec{Players: []string{"a"}, Pace: 1, Turns: 1,
+					AntsPerPlayer: 1, MaxPlayers: 1, MinPlayers: 1,
+					InitialEnergy: 1, InitialAcid: 1}
+				o.Expect(gs.Check()).To(o.BeNil())
+			})
+
+			g.It("Should return all the problems", func() {
+				gs := GameSpec{Public: true, Players: []string{"a"}, Pace: 500,
+					Turns: 1, AntsPerPlayer: 1, MaxPlayers: 1, MinPlayers: 1,
+					InitialEnergy: 1}
+
+				errs, ok := gs.Check().(SpecErrors)
+				o.Expect(ok).To(o.BeTrue())
+				o.Expect(errs).To(o.HaveLen(3))
+
+				o.Expect(errs[0].Field).To(o.Equal("Players"))
+				o.Expect(errs[1].Field).To(o.Equal("Pace"))
+				o.Expect(errs[1].Value).To(o.Equal(500))
+				o.Expect(errs[1].Min).To(o.Equal(paceRange.min))
+				o.Expect(errs[1].Max).To(o.Equal(paceRange.max))
+				o.Expect(errs[1].Error()).To(o.Equal("Pace: 500 is not between 1 and 100"))
+				o.Expect(errs[2].Field).To(o.Equal("InitialAcid"))
+			})
+
+			g.It("Should be an invalid argument", func() {
+				err := (&GameSpec{}).Check()
+				o.Expect(errors.Is(err, ErrInvalidArgument)).To(o.BeTrue())
+			})
+		})
 	})
 }
@@ -0,0 +1,327 @@
+package api
+
+// This file describes how we filter and sort the list of games from /games.
+// This list only has the ID, the creator, the creation date and the teaser
+// of each game; anything else needs the status of the game, which we get from
+// /status for all games at the same time:
+//
+//     games, _ := cl.ListGames()
+//     listed, errs := cl.FetchStatuses(games, 4)
+//     listed = FilterGames(listed, GameFilter{Open: true})
+//     SortGames(listed, "turn", false)
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// visibilities of a game, for GameFilter
+const (
+	PublicGames  = "public"
+	PrivateGames = "private"
+)
+
+// the layouts we try to parse the creation date of a game. We don't know the
+// one the server uses.
+var creationDateLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// ParseDate parses a date in one of the formats the server may use for the
+// creation dates of the games, e.g. "2015-03-04" or "2015-03-04 05:06:07"
+func ParseDate(date string) (time.Time, error) {
+	for _, layout := range creationDateLayouts {
+		if t, err := time.Parse(layout, date); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unknown date format: %q", date)
+}
+
+// Created returns the creation date of the game
+func (g Game) Created() (time.Time, error) {
+	return ParseDate(g.CreationDate)
+}
+
+// OpenSlots returns the number of players who can still join the game. It
+// returns false if we can't know it, i.e. for a public game which didn't
+// start if the server didn't tell us its maximum number of players.
+func (g *GameStatus) OpenSlots() (int, bool) {
+	if g.Status == playingStatus || g.Status == overStatus {
+		return 0, true
+	}
+
+	if g.Spec == nil {
+		return 0, false
+	}
+
+	joined := make(map[string]bool)
+	for _, p := range g.Players {
+		joined[p] = true
+	}
+
+	// a private game has a slot for each invited player
+	if !g.Spec.Public {
+		n := 0
+		for _, p := range g.Spec.Players {
+			if !joined[p] {
+				n++
+			}
+		}
+		return n, true
+	}
+
+	if g.Spec.MaxPlayers == 0 {
+		return 0, false
+	}
+
+	if n := g.Spec.MaxPlayers - len(g.Players); n > 0 {
+		return n, true
+	}
+
+	return 0, true
+}
+
+// MissingPlayers returns the number of players the game needs to start. It
+// returns false if we can't know it, i.e. if the server didn't tell us its
+// minimum number of players.
+func (g *GameStatus) MissingPlayers() (int, bool) {
+	if g.Status == playingStatus || g.Status == overStatus {
+		return 0, true
+	}
+
+	if g.Spec == nil || g.Spec.MinPlayers == 0 {
+		return 0, false
+	}
+
+	if n := g.Spec.MinPlayers - len(g.Players); n > 0 {
+		return n, true
+	}
+
+	return 0, true
+}
+
+// createdBefore returns true if a game was created before another one
+func createdBefore(a, b Game) bool {
+	ta, erra := a.Created()
+	tb, errb := b.Created()
+	if erra != nil || errb != nil {
+		return a.CreationDate < b.CreationDate
+	}
+	return ta.Before(tb)
+}
+
+// A ListedGame is a game from /games with its status, if we got it
+type ListedGame struct {
+	Game
+	Status *GameStatus
+}
+
+// FetchStatuses gets the statuses of some games, `parallel` at a time. The
+// games whose status we couldn't get have none; their errors are returned
+// too.
+func (cl *Client) FetchStatuses(games []Game, parallel int) ([]ListedGame, []error) {
+	listed := make([]ListedGame, len(games))
+	errs := make([]error, len(games))
+
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, parallel)
+
+	for i, g := range games {
+		listed[i].Game = g
+
+		wg.Add(1)
+		go func(i int, id GameID) {
+			defer wg.Done()
+
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			status, err := cl.GetGameIdentifierStatus(id)
+			if err != nil {
+				errs[i] = fmt.Errorf("game %s: %v", id, err)
+				return
+			}
+
+			// keep what /games told us if /status doesn't
+			g := listed[i].Game
+			if status.CreationDate == "" {
+				status.CreationDate = g.CreationDate
+			}
+			if status.Creator == "" {
+				status.Creator = g.Creator
+			}
+			if status.Teaser == "" {
+				status.Teaser = g.Teaser
+			}
+
+			listed[i].Status = status
+		}(i, g.Identifier)
+	}
+
+	wg.Wait()
+
+	var failed []error
+	for _, err := range errs {
+		if err != nil {
+			failed = append(failed, err)
+		}
+	}
+
+	return listed, failed
+}
+
+// A GameFilter selects games. Its empty fields select all games.
+type GameFilter struct {
+	// the user who created the games
+	Creator string
+	// keep only the games created after this date
+	After time.Time
+	// PublicGames or PrivateGames
+	Visibility string
+	// keep only the games some players can still join
+	Open bool
+	// the status of the games, e.g. "playing"
+	Status string
+}
+
+// NeedsStatus returns true if we need the statuses of the games to filter
+// them
+func (f GameFilter) NeedsStatus() bool {
+	return f.Visibility != "" || f.Open || f.Status != ""
+}
+
+// Match returns true if the filter selects a game. The games without status
+// don't match a filter which needs it, nor the ones without a creation date
+// we can parse if the filter has one.
+func (f GameFilter) Match(g ListedGame) bool {
+	if f.Creator != "" && g.Creator != f.Creator {
+		return false
+	}
+
+	if !f.After.IsZero() {
+		created, err := g.Created()
+		if err != nil || !created.After(f.After) {
+			return false
+		}
+	}
+
+	if !f.NeedsStatus() {
+		return true
+	}
+
+	s := g.Status
+	if s == nil {
+		return false
+	}
+
+	switch f.Visibility {
+	case PublicGames:
+		if s.Spec == nil || !s.Spec.Public {
+			return false
+		}
+	case PrivateGames:
+		if s.Spec == nil || s.Spec.Public {
+			return false
+		}
+	}
+
+	if f.Status != "" && s.Status != f.Status {
+		return false
+	}
+
+	if f.Open {
+		// we don't exclude the games which may have slots
+		if n, known := s.OpenSlots(); known && n == 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// FilterGames returns the games a filter selects
+func FilterGames(games []ListedGame, f GameFilter) []ListedGame {
+	var selected []ListedGame
+
+	for _, g := range games {
+		if f.Match(g) {
+			selected = append(selected, g)
+		}
+	}
+
+	return selected
+}
+
+// gameKeys are the keys we can sort games by. "status", "turn" and "players"
+// need the statuses of the games; the ones without status are sorted as if
+// they had zero values.
+var gameKeys = map[string]func(a, b ListedGame) bool{
+	"id":      func(a, b ListedGame) bool { return a.Identifier < b.Identifier },
+	"created": func(a, b ListedGame) bool { return createdBefore(a.Game, b.Game) },
+	"creator": func(a, b ListedGame) bool { return a.Creator < b.Creator },
+	"teaser":  func(a, b ListedGame) bool { return a.Teaser < b.Teaser },
+	"status": func(a, b ListedGame) bool {
+		return a.statusField().Status < b.statusField().Status
+	},
+	"turn": func(a, b ListedGame) bool {
+		return a.statusField().Turn < b.statusField().Turn
+	},
+	"players": func(a, b ListedGame) bool {
+		return len(a.statusField().Players) < len(b.statusField().Players)
+	},
+}
+
+// SortNeedsStatus returns true if we need the statuses of the games to sort
+// them by a key
+func SortNeedsStatus(key string) bool {
+	return key == "status" || key == "turn" || key == "players"
+}
+
+// the status of a game, or an empty one if we don't have it
+func (g ListedGame) statusField() *GameStatus {
+	if g.Status == nil {
+		return &GameStatus{}
+	}
+	return g.Status
+}
+
+// GameSortKeys returns the keys we can sort games by, sorted
+func GameSortKeys() []string {
+	var keys []string
+	for k := range gameKeys {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// SortGames sorts games by a key, keeping the order of /games for the games
+// with the same value
+func SortGames(games []ListedGame, key string, reverse bool) error {
+	less, ok := gameKeys[key]
+	if !ok {
+		return fmt.Errorf("can't sort games by %q, expected one of %s", key,
+			strings.Join(GameSortKeys(), ", "))
+	}
+
+	sort.SliceStable(games, func(i, j int) bool {
+		if reverse {
+			return less(games[j], games[i])
+		}
+		return less(games[i], games[j])
+	})
+
+	return nil
+}
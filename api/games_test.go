@@ -0,0 +1,196 @@
+package api
+
+import (
+	"fmt"
+	"github.com/franela/goblin"
+	o "github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGames(t *testing.T) {
+
+	g := goblin.Goblin(t)
+
+	o.RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	public := func(status string, max int, players ...string) *GameStatus {
+		return &GameStatus{
+			Status:  status,
+			Players: players,
+			Game:    Game{Spec: &GameSpec{Public: true, MaxPlayers: max}},
+		}
+	}
+
+	g.Describe("Game.Created", func() {
+		g.It("Should parse the creation date", func() {
+			for _, date := range []string{"2015-03-04T05:06:07Z", "2015-03-04 05:06:07"} {
+				created, err := Game{CreationDate: date}.Created()
+				o.Expect(err).To(o.BeNil())
+				o.Expect(created).To(o.Equal(time.Date(2015, 3, 4, 5, 6, 7, 0, time.UTC)))
+			}
+		})
+
+		g.It("Should fail on unknown formats", func() {
+			_, err := Game{CreationDate: "yesterday"}.Created()
+			o.Expect(err).NotTo(o.BeNil())
+		})
+	})
+
+	g.Describe("GameStatus.OpenSlots", func() {
+		g.It("Should return 0 for games which started", func() {
+			for _, status := range []string{"playing", "over"} {
+				n, known := public(status, 4, "a").OpenSlots()
+				o.Expect(n).To(o.Equal(0))
+				o.Expect(known).To(o.BeTrue())
+			}
+		})
+
+		g.It("Should count the free slots of public games", func() {
+			n, known := public("waiting", 4, "a").OpenSlots()
+			o.Expect(n).To(o.Equal(3))
+			o.Expect(known).To(o.BeTrue())
+
+			n, _ = public("waiting", 1, "a").OpenSlots()
+			o.Expect(n).To(o.Equal(0))
+		})
+
+		g.It("Should count the invited players who didn't join", func() {
+			s := &GameStatus{
+				Status:  "waiting",
+				Players: []string{"a"},
+				Game:    Game{Spec: &GameSpec{Players: []string{"a", "b", "c"}}},
+			}
+
+			n, known := s.OpenSlots()
+			o.Expect(n).To(o.Equal(2))
+			o.Expect(known).To(o.BeTrue())
+		})
+
+		g.It("Should not know without the max number of players", func() {
+			_, known := public("waiting", 0, "a").OpenSlots()
+			o.Expect(known).To(o.BeFalse())
+		})
+	})
+
+	g.Describe("FilterGames", func() {
+		games := []ListedGame{
+			{Game{Identifier: "1", Creator: "a", CreationDate: "2015-01-01"},
+				public("waiting", 2)},
+			{Game{Identifier: "2", Creator: "b", CreationDate: "2015-02-01"},
+				public("playing", 2, "a", "b")},
+			{Game{Identifier: "3", Creator: "a", CreationDate: "2015-03-01"},
+				&GameStatus{Status: "waiting",
+					Game: Game{Spec: &GameSpec{Players: []string{"a"}}}}},
+			{Game{Identifier: "4", Creator: "a", CreationDate: "?"}, nil},
+		}
+
+		ids := func(f GameFilter) []GameID {
+			var ids []GameID
+			for _, g := range FilterGames(games, f) {
+				ids = append(ids, g.Identifier)
+			}
+			return ids
+		}
+
+		g.It("Should select all games with an empty filter", func() {
+			o.Expect(ids(GameFilter{})).To(o.Equal([]GameID{"1", "2", "3", "4"}))
+		})
+
+		g.It("Should filter games by creator and creation date", func() {
+			o.Expect(ids(GameFilter{Creator: "a"})).To(o.Equal([]GameID{"1", "3", "4"}))
+
+			after := time.Date(2015, 1, 15, 0, 0, 0, 0, time.UTC)
+			o.Expect(ids(GameFilter{After: after})).To(o.Equal([]GameID{"2", "3"}))
+		})
+
+		g.It("Should filter games by their status", func() {
+			o.Expect(ids(GameFilter{Visibility: PublicGames})).To(o.Equal([]GameID{"1", "2"}))
+			o.Expect(ids(GameFilter{Visibility: PrivateGames})).To(o.Equal([]GameID{"3"}))
+			o.Expect(ids(GameFilter{Status: "waiting"})).To(o.Equal([]GameID{"1", "3"}))
+			o.Expect(ids(GameFilter{Open: true})).To(o.Equal([]GameID{"1", "3"}))
+		})
+	})
+
+	g.Describe("SortGames", func() {
+		var games []ListedGame
+
+		g.BeforeEach(func() {
+			games = []ListedGame{
+				{Game{Identifier: "1", Creator: "b"}, &GameStatus{Turn: 3}},
+				{Game{Identifier: "2", Creator: "a"}, nil},
+				{Game{Identifier: "3", Creator: "b"}, &GameStatus{Turn: 1}},
+			}
+		})
+
+		ids := func() []GameID {
+			var ids []GameID
+			for _, g := range games {
+				ids = append(ids, g.Identifier)
+			}
+			return ids
+		}
+
+		g.It("Should sort games by a key", func() {
+			o.Expect(SortGames(games, "creator", false)).To(o.BeNil())
+			o.Expect(ids()).To(o.Equal([]GameID{"2", "1", "3"}))
+
+			o.Expect(SortGames(games, "turn", false)).To(o.BeNil())
+			o.Expect(ids()).To(o.Equal([]GameID{"2", "3", "1"}))
+		})
+
+		g.It("Should sort games in reverse order", func() {
+			o.Expect(SortGames(games, "id", true)).To(o.BeNil())
+			o.Expect(ids()).To(o.Equal([]GameID{"3", "2", "1"}))
+		})
+
+		g.It("Should know which keys need the statuses", func() {
+			o.Expect(SortNeedsStatus("turn")).To(o.BeTrue())
+			o.Expect(SortNeedsStatus("creator")).To(o.BeFalse())
+		})
+
+		g.It("Should reject unknown keys", func() {
+			o.Expect(SortGames(games, "foo", false)).NotTo(o.BeNil())
+		})
+	})
+
+	g.Describe("Client.FetchStatuses", func() {
+		g.It("Should get the status of each game", func() {
+			ts := httptest.NewTLSServer(http.HandlerFunc(func(
+				w http.ResponseWriter, r *http.Request) {
+
+				r.ParseForm()
+
+				id := r.Form.Get("id")
+				if id == "2" {
+					fmt.Fprint(w, `{"status": "error", "response": {
+						"error_code": 502441794, "error_msg": ""}}`)
+					return
+				}
+
+				fmt.Fprintf(w, `{"status": "completed", "response": {"status": {
+					"visibility": "public", "nb_player": 4, "players": [],
+					"status": {"status": "waiting"}, "turn": %s}}}`, id)
+			}))
+			defer ts.Close()
+
+			cl := NewClient()
+			cl.SetBaseURL(ts.URL)
+
+			listed, errs := cl.FetchStatuses([]Game{
+				{Identifier: "1", Creator: "foo"}, {Identifier: "2"}, {Identifier: "3"},
+			}, 2)
+
+			o.Expect(len(listed)).To(o.Equal(3))
+			o.Expect(listed[0].Status.Turn).To(o.Equal(1))
+			o.Expect(listed[0].Status.Creator).To(o.Equal("foo"))
+			o.Expect(listed[0].Status.Spec.MaxPlayers).To(o.Equal(4))
+			o.Expect(listed[1].Status).To(o.BeNil())
+			o.Expect(listed[2].Status.Turn).To(o.Equal(3))
+
+			o.Expect(len(errs)).To(o.Equal(1))
+		})
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/franela/goreq"
 	"github.com/google/go-querystring/query"
 	"net/http/cookiejar"
+	"net/url"
 	"os"
 	"strings"
 )
@@ -15,6 +16,10 @@ import (
 // The base URL of all API calls
 const defaultBaseURL = "https://yann.regis-gianas.org/antroid"
 
+// BaseURL is the base URL of the clients we create. It can be changed to use
+// another server, e.g. a local one.
+var BaseURL = defaultBaseURL
+
 // The API version we support
 const defaultAPIVersion = "0"
 
@@ -30,6 +35,9 @@ type Httclient struct {
 
 	cookies *cookiejar.Jar
 
+	// the API's error codes, if we loaded them
+	errors ErrorTable
+
 	debug bool
 }
 
@@ -39,7 +47,7 @@ func NewHTTClient() *Httclient {
 
 	return &Httclient{
 		UserAgent:  defaultUserAgent,
-		baseURL:    defaultBaseURL,
+		baseURL:    BaseURL,
 		apiVersion: defaultAPIVersion,
 		cookies:    jar,
 	}
@@ -81,6 +89,10 @@ func (h *Httclient) call(method, call string, data interface{}) (b *Body) {
 	if method == "GET" {
 		// goreq will encode everything for us
 		req.QueryString = data
+	} else if values, ok := data.(url.Values); ok {
+		// values from `Client.Call`, already encoded
+		req.ContentType = "application/x-www-form-urlencoded"
+		req.Body = values.Encode()
 	} else if data != nil {
 		// we need to encode our values because the server doesn't accept JSON
 		// in requests.
@@ -148,7 +160,7 @@ func (h *Httclient) call(method, call string, data interface{}) (b *Body) {
 		var errorResp errorResponse
 
 		if b.err = b.DumpTo(&errorResp); b.err == nil {
-			b.err = errorResp.Error()
+			b.err = errorResp.Error(h.errors)
 		}
 
 	default:
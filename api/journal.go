@@ -0,0 +1,192 @@
+package api
+
+// This file describes the turn journal. The game server can record what it
+// knows at each turn in a journal, which can then be replayed later, e.g. to
+// render the game (see `render/`).
+//
+// A journal is a list of JSON objects, one per line, each one describing a
+// turn. To keep it small we don't save the whole map at each turn but only
+// the cells that changed since the previous one. A `JournalReader` replays
+// these changes to rebuild the map as we read the journal.
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// A JournalEntry describes one turn of a game
+type JournalEntry struct {
+	// the game's identifier
+	Game GameID
+	// the turn number
+	Turn int
+	// the game status ("playing", "over", etc)
+	Status string
+	// the scoreboard at this turn (map username => score)
+	Score map[string]int
+	// the players in the game
+	Players []string
+
+	// our ants. Their vision is not saved since it's already in the map.
+	Ants []AntStatus
+	// all the enemy ants we know, including the ones we lost sight of
+	Enemies []TrackedAnt
+	// the map cells that changed since the previous turn
+	Cells []Cell
+	// the annotations our AIs sent while playing this turn (see
+	// `api/annotations.go`)
+	Annotations []Annotation
+}
+
+// A JournalWriter writes journal entries on an io.Writer
+type JournalWriter struct {
+	enc *json.Encoder
+}
+
+// NewJournalWriter returns a pointer on a new JournalWriter which writes on
+// the given writer.
+func NewJournalWriter(w io.Writer) *JournalWriter {
+	return &JournalWriter{enc: json.NewEncoder(w)}
+}
+
+// Write writes an entry in the journal
+func (j *JournalWriter) Write(e *JournalEntry) error {
+	return j.enc.Encode(e)
+}
+
+// A JournalReader reads a journal entry by entry and rebuilds the map as it
+// goes.
+type JournalReader struct {
+	dec *json.Decoder
+
+	// The map as it is after the last entry we read
+	Map *PartialMap
+}
+
+// NewJournalReader returns a pointer on a new JournalReader which reads from
+// the given reader.
+func NewJournalReader(r io.Reader) *JournalReader {
+	return &JournalReader{
+		dec: json.NewDecoder(r),
+		Map: NewPartialMap(),
+	}
+}
+
+// Next reads the next entry in the journal and applies its changes to the
+// reader's map. It returns io.EOF at the end of the journal.
+func (j *JournalReader) Next() (*JournalEntry, error) {
+	var e JournalEntry
+
+	if err := j.dec.Decode(&e); err != nil {
+		return nil, err
+	}
+
+	for _, c := range e.Cells {
+		cell := c
+		j.Map.SetCell(&cell)
+	}
+
+	return &e, nil
+}
+
+// newJournalEntry returns the journal entry for the current turn
+func (p *Player) newJournalEntry(dirty []*Cell) *JournalEntry {
+	e := &JournalEntry{
+		Game:    p.status.Identifier,
+		Turn:    p.turn.Number,
+		Status:  p.status.Status,
+		Score:   p.status.Score,
+		Players: p.status.Players,
+	}
+
+	for _, ant := range p.turn.AntsStatuses {
+		ant.Vision = nil
+		e.Ants = append(e.Ants, ant)
+	}
+
+	for _, ant := range p.enemies.Ants() {
+		e.Enemies = append(e.Enemies, *ant)
+	}
+
+	for _, c := range dirty {
+		e.Cells = append(e.Cells, *c)
+	}
+
+	return e
+}
+
+// Journal returns the journal of a game log as one of its players saw it: its
+// ants are ours and the ones of the other players are enemies. Unlike a
+// journal recorded while playing, the map has the cells any player saw and
+// the enemies are all the ants of the other players. The log doesn't give the
+// status of the game, so the entries don't have one.
+func (gl *GameLog) Journal(player string) []*JournalEntry {
+	var entries []*JournalEntry
+
+	// the cells as they are after the previous entry
+	known := make(map[Position]Cell)
+
+	for _, lt := range gl.Turns {
+		e := &JournalEntry{Game: gl.Game, Turn: lt.Number, Score: lt.Score}
+
+		for name := range lt.Players {
+			e.Players = append(e.Players, name)
+		}
+		sort.Strings(e.Players)
+
+		seen := make(map[Position]Cell)
+
+		for _, name := range e.Players {
+			for _, ant := range lt.Players[name].AntsStatuses {
+				if ant.Vision != nil {
+					for _, c := range ant.Vision.Cells {
+						cell := *c
+						cell.Visibility = true
+						seen[cell.Pos] = cell
+					}
+				}
+
+				if name == player {
+					ant.Vision = nil
+					e.Ants = append(e.Ants, ant)
+					continue
+				}
+
+				e.Enemies = append(e.Enemies, TrackedAnt{
+					BasicAntStatus: ant.BasicAntStatus,
+					ID:             len(e.Enemies),
+					Owner:          name,
+					LastSeen:       lt.Number,
+					Visible:        true,
+				})
+			}
+		}
+
+		// like a recorded journal, we only keep the cells which changed:
+		// the ones nobody sees anymore and the new or different ones
+		for pos, c := range known {
+			if _, ok := seen[pos]; !ok && c.Visibility {
+				c.Visibility = false
+				known[pos] = c
+				e.Cells = append(e.Cells, c)
+			}
+		}
+
+		for pos, c := range seen {
+			if old, ok := known[pos]; !ok || old != c {
+				known[pos] = c
+				e.Cells = append(e.Cells, c)
+			}
+		}
+
+		sort.Slice(e.Cells, func(i, j int) bool {
+			a, b := e.Cells[i].Pos, e.Cells[j].Pos
+			return a.Y < b.Y || (a.Y == b.Y && a.X < b.X)
+		})
+
+		entries = append(entries, e)
+	}
+
+	return entries
+}
@@ -0,0 +1,199 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/franela/goblin"
+	o "github.com/onsi/gomega"
+	"io"
+	"testing"
+)
+
+// a fakeObserver remembers all the entries it observes
+type fakeObserver struct {
+	entries []*JournalEntry
+	cells   int
+}
+
+func (f *fakeObserver) Observe(e *JournalEntry, m *PartialMap) {
+	f.entries = append(f.entries, e)
+	f.cells = len(m.Cells)
+}
+
+func TestJournal(t *testing.T) {
+
+	g := goblin.Goblin(t)
+
+	o.RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	g.Describe("JournalReader", func() {
+		var buf *bytes.Buffer
+
+		g.BeforeEach(func() {
+			buf = &bytes.Buffer{}
+			w := NewJournalWriter(buf)
+
+			w.Write(&JournalEntry{
+				Turn: 1,
+				Cells: []Cell{
+					{Pos: Position{X: 1, Y: 1}, Content: "grass", Visibility: true},
+					{Pos: Position{X: 2, Y: 1}, Content: "rock", Visibility: true},
+				},
+			})
+
+			w.Write(&JournalEntry{
+				Turn: 2,
+				Cells: []Cell{
+					{Pos: Position{X: 1, Y: 1}, Content: "grass"},
+				},
+			})
+		})
+
+		g.It("Should read all entries", func() {
+			r := NewJournalReader(buf)
+
+			e, err := r.Next()
+			o.Expect(err).To(o.BeNil())
+			o.Expect(e.Turn).To(o.Equal(1))
+
+			e, err = r.Next()
+			o.Expect(err).To(o.BeNil())
+			o.Expect(e.Turn).To(o.Equal(2))
+
+			_, err = r.Next()
+			o.Expect(err).To(o.Equal(io.EOF))
+		})
+
+		g.It("Should rebuild the map", func() {
+			r := NewJournalReader(buf)
+
+			r.Next()
+			o.Expect(r.Map.Cells).To(o.HaveLen(2))
+			o.Expect(r.Map.Cell(1, 1).Visibility).To(o.BeTrue())
+
+			r.Next()
+			o.Expect(r.Map.Cells).To(o.HaveLen(2))
+			o.Expect(r.Map.Cell(1, 1).Visibility).To(o.BeFalse())
+			o.Expect(r.Map.Cell(2, 1).Content).To(o.Equal("rock"))
+		})
+	})
+
+	g.Describe("GameLog", func() {
+		var gl GameLog
+
+		g.BeforeEach(func() {
+			// foo's ant sees (0, 0) and (1, 0) then only (1, 0) and (2, 0);
+			// bar's ant always sees (5, 0)
+			var resp logResponse
+
+			o.Expect(json.Unmarshal([]byte(`{"log": [
+				{"turn": 1, "score": {"foo": 0, "bar": 0}, "observations": {
+					"foo": [[{"id": 0, "x": 0, "y": 0, "dx": 1, "dy": 0, "brain": "controlled"},
+						[{"x": 0, "y": 0, "content": {"kind": "grass"}},
+						 {"x": 1, "y": 0, "content": {"kind": "food", "level": "sugar"}}], []]],
+					"bar": [[{"id": 0, "x": 5, "y": 0, "dx": -1, "dy": 0, "brain": "controlled"},
+						[{"x": 5, "y": 0, "content": {"kind": "grass"}}], []]]}},
+				{"turn": 2, "score": {"foo": 1, "bar": 0}, "observations": {
+					"foo": [[{"id": 0, "x": 1, "y": 0, "dx": 1, "dy": 0, "brain": "controlled"},
+						[{"x": 1, "y": 0, "content": {"kind": "grass"}},
+						 {"x": 2, "y": 0, "content": {"kind": "grass"}}], []]],
+					"bar": [[{"id": 0, "x": 5, "y": 0, "dx": -1, "dy": 0, "brain": "controlled"},
+						[{"x": 5, "y": 0, "content": {"kind": "grass"}}], []]]}}
+			]}`), &resp)).To(o.BeNil())
+
+			var err error
+			gl, err = resp.getLog("g1")
+			o.Expect(err).To(o.BeNil())
+		})
+
+		g.It("Should parse each turn of each player", func() {
+			o.Expect(gl.Turns).To(o.HaveLen(2))
+			o.Expect(gl.Turns[1].Number).To(o.Equal(2))
+			o.Expect(gl.Turns[1].Score["foo"]).To(o.Equal(1))
+			o.Expect(gl.Turns[1].Players["foo"].AntsStatuses[0].Pos).
+				To(o.Equal(Position{X: 1, Y: 0}))
+		})
+
+		g.It("Should give a journal from a player's point of view", func() {
+			entries := gl.Journal("foo")
+			o.Expect(entries).To(o.HaveLen(2))
+
+			e := entries[0]
+			o.Expect(e.Game).To(o.Equal(GameID("g1")))
+			o.Expect(e.Players).To(o.Equal([]string{"bar", "foo"}))
+			o.Expect(e.Ants).To(o.HaveLen(1))
+			o.Expect(e.Ants[0].Vision).To(o.BeNil())
+			o.Expect(e.Enemies).To(o.HaveLen(1))
+			o.Expect(e.Enemies[0].Owner).To(o.Equal("bar"))
+			o.Expect(e.Cells).To(o.HaveLen(3))
+		})
+
+		g.It("Should only keep the cells which changed", func() {
+			var buf bytes.Buffer
+			w := NewJournalWriter(&buf)
+
+			for _, e := range gl.Journal("foo") {
+				w.Write(e)
+			}
+
+			r := NewJournalReader(&buf)
+			r.Next()
+			e, err := r.Next()
+			o.Expect(err).To(o.BeNil())
+
+			// (0, 0) isn't seen anymore, (1, 0) changed and (2, 0) is new
+			o.Expect(e.Cells).To(o.HaveLen(3))
+			o.Expect(r.Map.Cells).To(o.HaveLen(4))
+			o.Expect(r.Map.Cell(0, 0).Visibility).To(o.BeFalse())
+			o.Expect(r.Map.Cell(1, 0).Content).To(o.Equal("grass"))
+			o.Expect(r.Map.Cell(5, 0).Visibility).To(o.BeTrue())
+		})
+	})
+
+	g.Describe("Player", func() {
+		g.It("Should send each turn to the observers", func() {
+			obs := &fakeObserver{}
+
+			p := newTestPlayer()
+			p.Listeners.AddObserver(obs)
+			p.sendTurnStatusToAIs()
+			p.sendTurnStatusToListeners(nil)
+
+			o.Expect(obs.entries).To(o.HaveLen(1))
+			o.Expect(obs.entries[0].Turn).To(o.Equal(3))
+			o.Expect(obs.cells).To(o.Equal(2))
+		})
+
+		g.It("Should record each turn in its journal", func() {
+			var buf bytes.Buffer
+
+			p := newTestPlayer()
+			p.SetJournal(&buf)
+			p.sendTurnStatusToAIs()
+			p.sendTurnStatusToListeners(nil)
+
+			e, err := NewJournalReader(&buf).Next()
+
+			o.Expect(err).To(o.BeNil())
+			o.Expect(e.Turn).To(o.Equal(3))
+			o.Expect(e.Ants).To(o.HaveLen(1))
+			o.Expect(e.Ants[0].Vision).To(o.BeNil())
+			o.Expect(e.Enemies).To(o.HaveLen(1))
+			o.Expect(e.Cells).To(o.HaveLen(2))
+		})
+
+		g.It("Should record the AIs' annotations in its journal", func() {
+			var buf bytes.Buffer
+
+			p := newTestPlayer()
+			p.SetJournal(&buf)
+			p.sendTurnStatusToAIs()
+			p.sendTurnStatusToListeners([]Annotation{{AI: 0, Kind: "log", Text: "hi"}})
+
+			e, err := NewJournalReader(&buf).Next()
+
+			o.Expect(err).To(o.BeNil())
+			o.Expect(e.Annotations).To(o.Equal([]Annotation{{AI: 0, Kind: "log", Text: "hi"}}))
+		})
+	})
+}
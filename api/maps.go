@@ -5,9 +5,16 @@ package api
 // but when we're playing a game we only have a partial view of the full map,
 // and not their dimensions. PartialMap is here to represent a partial map.
 //
-// Maps are not stored as 2-dimensional arrays because for most of them we
-// don't have all the cells in it. We instead use a slice of `Cell`s, described
-// below.
+// Partial maps are not stored as 2-dimensional arrays because for most of them
+// we don't have all the cells in it. We instead use a map of `Cell`s indexed
+// by their position, described below. Full maps know their dimensions so they
+// also keep a dense grid of their cells for fast lookups.
+//
+// Maps can get big (120x80 and more) and we update them for every ant on every
+// turn, so we try hard not to scan all their cells. They remember their
+// bounds, the cells that are currently visible, and the cells that changed
+// since the last time we looked at them (the "dirty" cells). This way the
+// per-turn work only depends on what our ants saw.
 
 // Position is a map position
 type Position struct {
@@ -41,6 +48,11 @@ type Cell struct {
 	Visibility bool
 }
 
+// IsFood returns true if a cell content is food
+func IsFood(content string) bool {
+	return content == "sugar" || content == "mill" || content == "meat"
+}
+
 // MapInterface is used for all things that represent maps, i.e. Map (full map)
 // and PartialMap
 type MapInterface interface {
@@ -52,6 +64,17 @@ type MapInterface interface {
 // PartialMap is a part of a map
 type PartialMap struct {
 	Cells map[Position]*Cell
+
+	// the known dimensions, cached. They're only valid if `counted` is equal
+	// to the number of cells. If it's not, someone modified `Cells` directly
+	// and we need to scan it again.
+	width, height int
+	counted       int
+
+	// the cells we set to visible and didn't reset yet
+	visible map[Position]*Cell
+	// the cells that changed since the last call to `.ClearDirty()`
+	dirty map[Position]*Cell
 }
 
 // A Map is like a PartialMap but we know its dimensions and all of its content
@@ -60,70 +83,206 @@ type Map struct {
 	PartialMap
 
 	width, height int
+
+	// dense storage of the cells, indexed by `y*width+x`. It may be nil if the
+	// map wasn't created with `NewMap`.
+	grid []*Cell
 }
 
 // NewPartialMap returns a new, empty, partial map
 func NewPartialMap() *PartialMap {
-	return &PartialMap{Cells: make(map[Position]*Cell)}
+	return &PartialMap{
+		Cells:   make(map[Position]*Cell),
+		visible: make(map[Position]*Cell),
+		dirty:   make(map[Position]*Cell),
+	}
 }
 
-// Width returns the known width of the partial map
-func (pm PartialMap) Width() int {
-	maxX := -1
+// NewMap returns a new, empty, map with the given dimensions
+func NewMap(width, height int) *Map {
+	return &Map{
+		PartialMap: *NewPartialMap(),
+		width:      width,
+		height:     height,
+		grid:       make([]*Cell, width*height),
+	}
+}
+
+// bounds returns the known dimensions of the partial map, recomputing them
+// only if some cells were added behind our back.
+func (pm *PartialMap) bounds() (width, height int) {
+	if pm.counted != len(pm.Cells) {
+		pm.width, pm.height = 0, 0
 
-	for p := range pm.Cells {
-		if p.X > maxX {
-			maxX = p.X
+		for p := range pm.Cells {
+			pm.extend(p)
 		}
+
+		pm.counted = len(pm.Cells)
 	}
 
-	return maxX + 1
+	return pm.width, pm.height
 }
 
-// Height returns the known height of the partial map
-func (pm PartialMap) Height() int {
-	maxY := -1
+// extend updates the cached dimensions to include the given position
+func (pm *PartialMap) extend(p Position) {
+	if p.X >= pm.width {
+		pm.width = p.X + 1
+	}
 
-	for p := range pm.Cells {
-		if p.Y > maxY {
-			maxY = p.Y
-		}
+	if p.Y >= pm.height {
+		pm.height = p.Y + 1
 	}
+}
+
+// Width returns the known width of the partial map
+func (pm *PartialMap) Width() int {
+	w, _ := pm.bounds()
+	return w
+}
 
-	return maxY + 1
+// Height returns the known height of the partial map
+func (pm *PartialMap) Height() int {
+	_, h := pm.bounds()
+	return h
 }
 
 // Cell returns the cell at (x,y) in the map, or nil if we don't know it
-func (pm PartialMap) Cell(x, y int) *Cell {
+func (pm *PartialMap) Cell(x, y int) *Cell {
 	return pm.Cells[Position{X: x, Y: y}]
 }
 
+// SetCell adds a cell to the partial map, replacing any previous cell at the
+// same position. The cell is marked as dirty.
+func (pm *PartialMap) SetCell(c *Cell) {
+	if pm.Cells == nil {
+		pm.Cells = make(map[Position]*Cell)
+	}
+
+	_, known := pm.Cells[c.Pos]
+	synced := pm.counted == len(pm.Cells)
+
+	pm.Cells[c.Pos] = c
+
+	// we only update the cached bounds if they were valid before
+	if synced && !known {
+		pm.counted++
+		pm.extend(c.Pos)
+	}
+
+	pm.markVisibility(c)
+	pm.markDirty(c)
+}
+
+// markVisibility remembers if a cell is visible or not
+func (pm *PartialMap) markVisibility(c *Cell) {
+	if c.Visibility {
+		if pm.visible == nil {
+			pm.visible = make(map[Position]*Cell)
+		}
+		pm.visible[c.Pos] = c
+	} else {
+		delete(pm.visible, c.Pos)
+	}
+}
+
+// markDirty remembers that a cell changed
+func (pm *PartialMap) markDirty(c *Cell) {
+	if pm.dirty == nil {
+		pm.dirty = make(map[Position]*Cell)
+	}
+	pm.dirty[c.Pos] = c
+}
+
+// DirtyCells returns all cells that changed since the last call to
+// `.ClearDirty()`, in no particular order.
+func (pm *PartialMap) DirtyCells() []*Cell {
+	cells := make([]*Cell, 0, len(pm.dirty))
+
+	for _, c := range pm.dirty {
+		cells = append(cells, c)
+	}
+
+	return cells
+}
+
+// ClearDirty forgets about all the dirty cells
+func (pm *PartialMap) ClearDirty() {
+	pm.dirty = make(map[Position]*Cell)
+}
+
 // Width returns the width of the map
-func (m Map) Width() int { return m.width }
+func (m *Map) Width() int { return m.width }
 
 // Height returns the width of the map
-func (m Map) Height() int { return m.height }
+func (m *Map) Height() int { return m.height }
+
+// Cell returns the cell at (x,y) in the map, or nil if we don't know it
+func (m *Map) Cell(x, y int) *Cell {
+	if m.grid != nil {
+		if x < 0 || y < 0 || x >= m.width || y >= m.height {
+			return nil
+		}
+		return m.grid[y*m.width+x]
+	}
+
+	return m.PartialMap.Cell(x, y)
+}
+
+// SetCell adds a cell to the map, replacing any previous cell at the same
+// position.
+func (m *Map) SetCell(c *Cell) {
+	m.PartialMap.SetCell(c)
+
+	p := c.Pos
+	if m.grid != nil && p.X >= 0 && p.Y >= 0 && p.X < m.width && p.Y < m.height {
+		m.grid[p.Y*m.width+p.X] = c
+	}
+}
+
+// Combine modifies the current map in-place by adding partial maps to it
+func (m *Map) Combine(maps ...PartialMap) {
+	for _, other := range maps {
+		for _, c := range other.Cells {
+			m.SetCell(c)
+		}
+	}
+}
 
 // Combine modifies the current partial map in-place by adding other partial
 // maps to it
 func (pm *PartialMap) Combine(maps ...PartialMap) {
 	for _, m := range maps {
-		for p, c := range m.Cells {
-			pm.Cells[p] = c
+		for _, c := range m.Cells {
+			pm.SetCell(c)
 		}
 	}
 }
 
-// SetVisibility changes the visibility of all cells in the partial map
+// SetVisibility changes the visibility of all cells in the partial map. This
+// has to scan the whole map; use `.ResetVisibility()` to only hide the cells
+// that are currently visible.
 func (pm *PartialMap) SetVisibility(v bool) {
 	for _, c := range pm.Cells {
+		if c.Visibility != v {
+			pm.markDirty(c)
+		}
 		c.Visibility = v
+		pm.markVisibility(c)
 	}
 }
 
 // ResetVisibility changes the visibility of all cells in the partial map to
-// "false"
-func (pm *PartialMap) ResetVisibility() { pm.SetVisibility(false) }
+// "false". Only the cells that were made visible through this map are
+// updated, so this is proportional to the number of visible cells.
+func (pm *PartialMap) ResetVisibility() {
+	for _, c := range pm.visible {
+		c.Visibility = false
+		pm.markDirty(c)
+	}
+
+	pm.visible = make(map[Position]*Cell)
+}
 
 // CombinePartialMaps returns a new PartialMap that is the combination of the
 // first one and all the others
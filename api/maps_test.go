@@ -12,6 +12,19 @@ func TestMaps(t *testing.T) {
 
 	o.RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
 
+	g.Describe("IsFood", func() {
+		g.It("Should return true for food", func() {
+			o.Expect(IsFood("sugar")).To(o.BeTrue())
+			o.Expect(IsFood("mill")).To(o.BeTrue())
+			o.Expect(IsFood("meat")).To(o.BeTrue())
+		})
+
+		g.It("Should return false for other contents", func() {
+			o.Expect(IsFood("grass")).To(o.BeFalse())
+			o.Expect(IsFood("")).To(o.BeFalse())
+		})
+	})
+
 	g.Describe("NewPartialMap", func() {
 		g.It("Should not return nil", func() {
 			o.Expect(NewPartialMap()).NotTo(o.BeNil())
@@ -82,6 +95,94 @@ func TestMaps(t *testing.T) {
 				o.Expect(pm.Cell(0, 0)).To(o.BeNil())
 			})
 		})
+
+		g.Describe(".SetCell(c)", func() {
+			g.It("Should add the cell", func() {
+				c := &Cell{Pos: Position{X: 3, Y: 4}}
+				pm.SetCell(c)
+				o.Expect(pm.Cell(3, 4)).To(o.Equal(c))
+			})
+
+			g.It("Should update the dimensions", func() {
+				pm.SetCell(&Cell{Pos: Position{X: 3, Y: 4}})
+				pm.SetCell(&Cell{Pos: Position{X: 1, Y: 7}})
+				o.Expect(pm.Width()).To(o.Equal(4))
+				o.Expect(pm.Height()).To(o.Equal(8))
+			})
+
+			g.It("Should mark the cell as dirty", func() {
+				c := &Cell{Pos: Position{X: 3, Y: 4}}
+				pm.SetCell(c)
+				o.Expect(pm.DirtyCells()).To(o.Equal([]*Cell{c}))
+			})
+		})
+
+		g.Describe(".ClearDirty()", func() {
+			g.It("Should forget all dirty cells", func() {
+				pm.SetCell(&Cell{Pos: Position{X: 3, Y: 4}})
+				pm.ClearDirty()
+				o.Expect(pm.DirtyCells()).To(o.BeEmpty())
+			})
+		})
+
+		g.Describe(".ResetVisibility()", func() {
+			g.It("Should hide visible cells", func() {
+				c := &Cell{Pos: Position{X: 1, Y: 2}, Visibility: true}
+				pm.SetCell(c)
+				pm.ResetVisibility()
+				o.Expect(c.Visibility).To(o.BeFalse())
+			})
+
+			g.It("Should mark hidden cells as dirty", func() {
+				c := &Cell{Pos: Position{X: 1, Y: 2}, Visibility: true}
+				pm.SetCell(c)
+				pm.ClearDirty()
+				pm.ResetVisibility()
+				o.Expect(pm.DirtyCells()).To(o.Equal([]*Cell{c}))
+			})
+
+			g.It("Should not touch cells that weren't visible", func() {
+				pm.SetCell(&Cell{Pos: Position{X: 1, Y: 2}})
+				pm.ClearDirty()
+				pm.ResetVisibility()
+				o.Expect(pm.DirtyCells()).To(o.BeEmpty())
+			})
+		})
+
+		g.Describe(".Combine(maps...)", func() {
+			g.It("Should add all cells of the other maps", func() {
+				other := NewPartialMap()
+				other.SetCell(&Cell{Pos: Position{X: 5, Y: 1}})
+				other.SetCell(&Cell{Pos: Position{X: 2, Y: 6}})
+
+				pm.Combine(*other)
+				o.Expect(pm.Cells).To(o.HaveLen(2))
+				o.Expect(pm.Width()).To(o.Equal(6))
+				o.Expect(pm.Height()).To(o.Equal(7))
+			})
+		})
+	})
+
+	g.Describe("NewMap", func() {
+		g.It("Should return a map with the given dimensions", func() {
+			m := NewMap(12, 8)
+			o.Expect(m.Width()).To(o.Equal(12))
+			o.Expect(m.Height()).To(o.Equal(8))
+		})
+
+		g.It("Should store cells in its grid", func() {
+			m := NewMap(12, 8)
+			c := &Cell{Pos: Position{X: 11, Y: 7}}
+			m.SetCell(c)
+			o.Expect(m.Cell(11, 7)).To(o.Equal(c))
+			o.Expect(m.Cells).To(o.HaveLen(1))
+		})
+
+		g.It("Should return nil for cells out of the map", func() {
+			m := NewMap(12, 8)
+			o.Expect(m.Cell(12, 0)).To(o.BeNil())
+			o.Expect(m.Cell(-1, 0)).To(o.BeNil())
+		})
 	})
 
 	g.Describe("Map", func() {
@@ -0,0 +1,151 @@
+package api
+
+// This file describes matches, in which several of our players play against
+// each other from the same process. This is useful to test competitive AIs:
+// each player has its own account, client and AIs, and they all join the same
+// private game. They're then driven concurrently until the end of the game.
+
+import "sync"
+
+// A Match is a private game between several local players
+type Match struct {
+	players []*Player
+	// the last error of each player, if any
+	errors []error
+}
+
+// NewMatch returns a pointer on a new match between the given players. Each
+// one should have its own credentials.
+func NewMatch(players ...*Player) *Match {
+	return &Match{players: players, errors: make([]error, len(players))}
+}
+
+// Players returns the players of this match
+func (m *Match) Players() []*Player {
+	return m.players
+}
+
+// spec returns a copy of the given spec for a private game restricted to our
+// players
+func (m *Match) spec(gs *GameSpec) *GameSpec {
+	spec := *gs
+
+	spec.Public = false
+	spec.Players = make([]string, len(m.players))
+	spec.MinPlayers = len(m.players)
+	spec.MaxPlayers = len(m.players)
+
+	for i, p := range m.players {
+		spec.Players[i] = p.username
+	}
+
+	return &spec
+}
+
+// Start connects all players, creates a private game for them with the
+// given spec and make them join it. The first player creates the game.
+func (m *Match) Start(gs *GameSpec) (err error) {
+	if len(m.players) == 0 {
+		return ErrInvalidArgument
+	}
+
+	for i, p := range m.players {
+		if err = p.Connect(); err != nil {
+			m.errors[i] = err
+			return
+		}
+	}
+
+	spec := m.spec(gs)
+
+	var g *Game
+
+	if g, err = m.players[0].Client.CreateGame(spec); err != nil {
+		return
+	}
+
+	for i, p := range m.players {
+		p.turns = spec.Turns
+
+		if err = p.join(g.Identifier); err != nil {
+			m.errors[i] = err
+			return
+		}
+	}
+
+	// the players' status was fetched when they joined, i.e. before all the
+	// others did
+	for _, p := range m.players {
+		if err = p.updateStatus(); err != nil {
+			return
+		}
+	}
+
+	// the remote server waits for all players before playing a turn
+	return m.each((*Player).start)
+}
+
+// Play plays all turns until the end of the game. All players play at the
+// same time. It returns the first error a player got; the others keep playing.
+func (m *Match) Play() error {
+	return m.each(func(p *Player) (err error) {
+		for !p.Done() {
+			if _, err = p.PlayTurn(); err != nil {
+				return
+			}
+		}
+		return
+	})
+}
+
+// Errors returns the last error each player got, in the same order as the
+// players. It's nil for players which didn't get any error. This is useful to
+// know which player crashed when `Play()` fails.
+func (m *Match) Errors() []error {
+	return m.errors
+}
+
+// Scores returns the current scores, or nil if the game didn't start
+func (m *Match) Scores() map[string]int {
+	for _, p := range m.players {
+		if p.status != nil && p.status.Score != nil {
+			return p.status.Score
+		}
+	}
+
+	return nil
+}
+
+// PrintScores prints the final scores
+func (m *Match) PrintScores() {
+	if len(m.players) > 0 {
+		m.players[0].PrintScores()
+	}
+}
+
+// each calls a function on all players at the same time and waits for them.
+// It returns the first error it got, if any, and remembers the error of each
+// player.
+func (m *Match) each(f func(*Player) error) (err error) {
+	var wg sync.WaitGroup
+
+	for i, p := range m.players {
+		wg.Add(1)
+		go func(i int, p *Player) {
+			defer wg.Done()
+			if e := f(p); e != nil {
+				m.errors[i] = e
+			}
+		}(i, p)
+	}
+
+	wg.Wait()
+
+	for _, e := range m.errors {
+		if e != nil {
+			return e
+		}
+	}
+
+	return
+}
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"github.com/franela/goblin"
+	o "github.com/onsi/gomega"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+
+	g := goblin.Goblin(t)
+
+	o.RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	g.Describe("Match", func() {
+		var m *Match
+
+		g.BeforeEach(func() {
+			m = NewMatch(NewPlayer("alice", "a"), NewPlayer("bob", "b"))
+		})
+
+		g.It("Should restrict the game to its players", func() {
+			gs := &GameSpec{Public: true, Turns: 10, MinPlayers: 1, MaxPlayers: 1}
+			spec := m.spec(gs)
+
+			o.Expect(spec.Public).To(o.BeFalse())
+			o.Expect(spec.Players).To(o.Equal([]string{"alice", "bob"}))
+			o.Expect(spec.MinPlayers).To(o.Equal(2))
+			o.Expect(spec.MaxPlayers).To(o.Equal(2))
+			o.Expect(spec.Turns).To(o.Equal(10))
+
+			// the original spec is left untouched
+			o.Expect(gs.Public).To(o.BeTrue())
+		})
+
+		g.It("Should fail without players", func() {
+			o.Expect(NewMatch().Start(&GameSpec{})).To(o.Equal(ErrInvalidArgument))
+		})
+
+		g.It("Should call a function on all its players", func() {
+			called := make(chan string, 2)
+
+			err := m.each(func(p *Player) error {
+				called <- p.username
+				if p.username == "bob" {
+					return ErrWrongGame
+				}
+				return nil
+			})
+
+			o.Expect(err).To(o.Equal(ErrWrongGame))
+			o.Expect(called).To(o.HaveLen(2))
+		})
+
+		g.It("Should not play once the game is over", func() {
+			for _, p := range m.Players() {
+				p.done = true
+			}
+
+			o.Expect(m.Play()).To(o.BeNil())
+		})
+	})
+}
@@ -0,0 +1,161 @@
+package api
+
+// This file describes how a player finds an open game to join instead of
+// creating one: it looks for the public games which didn't start, still have
+// free slots and fit its constraints, and joins the best one. If it doesn't
+// find any before a timeout it creates a game.
+//
+// Other players may join the same games at the same time, so a game can be
+// full when we try to join it; we then try the next one.
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Constraints are what we expect from the games we join. Their zero fields
+// accept any value.
+type Constraints struct {
+	AntsPerPlayer int
+	Pace          int
+	Turns         int
+}
+
+// Fits returns true if a game spec fits the constraints. /status may not
+// give the number of turns of a game (see `gameStatusResponse`); a spec
+// without it fits any number of turns, otherwise no game would ever fit.
+func (c Constraints) Fits(gs *GameSpec) bool {
+	if gs == nil {
+		return false
+	}
+
+	return (c.AntsPerPlayer == 0 || gs.AntsPerPlayer == c.AntsPerPlayer) &&
+		(c.Pace == 0 || gs.Pace == c.Pace) &&
+		(c.Turns == 0 || gs.Turns == 0 || gs.Turns == c.Turns)
+}
+
+func (c Constraints) String() string {
+	value := func(n int) string {
+		if n == 0 {
+			return "any"
+		}
+		return fmt.Sprint(n)
+	}
+
+	return fmt.Sprintf("%s ants per player, pace %s, %s turns",
+		value(c.AntsPerPlayer), value(c.Pace), value(c.Turns))
+}
+
+// OpenGames returns the public games we can join which fit some constraints,
+// the best ones first: the ones which need the fewest players to start, then
+// the oldest ones. The games we already joined are left out.
+func (cl *Client) OpenGames(c Constraints, parallel int) ([]*GameStatus, error) {
+	games, err := cl.ListGames()
+	if err != nil {
+		return nil, err
+	}
+
+	// we don't care about the games whose status we can't get
+	listed, _ := cl.FetchStatuses(games, parallel)
+
+	listed = FilterGames(listed, GameFilter{Visibility: PublicGames, Open: true})
+
+	var open []*GameStatus
+
+Games:
+	for _, g := range listed {
+		if !c.Fits(g.Status.Spec) {
+			continue
+		}
+
+		for _, p := range g.Status.Players {
+			if p == cl.Username() {
+				continue Games
+			}
+		}
+
+		open = append(open, g.Status)
+	}
+
+	sortOpenGames(open)
+
+	return open, nil
+}
+
+// sortOpenGames sorts games from the one which needs the fewest players to
+// start to the one which needs the most, then from the oldest one. The games
+// whose minimum number of players we don't know come last.
+func sortOpenGames(games []*GameStatus) {
+	missing := func(g *GameStatus) int {
+		if n, known := g.MissingPlayers(); known {
+			return n
+		}
+		return math.MaxInt32
+	}
+
+	sort.SliceStable(games, func(i, j int) bool {
+		mi, mj := missing(games[i]), missing(games[j])
+		if mi != mj {
+			return mi < mj
+		}
+		return createdBefore(games[i].Game, games[j].Game)
+	})
+}
+
+// the interval between two looks for an open game, if the options don't have
+// one
+const defaultAutoJoinInterval = 5 * time.Second
+
+// AutoJoinOptions are the options of `Player.AutoJoinGame`
+type AutoJoinOptions struct {
+	Constraints Constraints
+	// how long we look for a game before creating one
+	Timeout time.Duration
+	// the interval between two looks. The default is 5 seconds.
+	Interval time.Duration
+	// the number of statuses we get at the same time
+	Parallel int
+}
+
+// AutoJoinGame joins the best open game which fits some constraints. If it
+// doesn't find one before the timeout, it creates a game from a spec and
+// joins it. It returns true if it created the game.
+func (p *Player) AutoJoinGame(opts AutoJoinOptions, gs *GameSpec) (bool, error) {
+	deadline := time.Now().Add(opts.Timeout)
+
+	if opts.Interval <= 0 {
+		opts.Interval = defaultAutoJoinInterval
+	}
+
+	for {
+		games, err := p.Client.OpenGames(opts.Constraints, opts.Parallel)
+		if err != nil {
+			return false, err
+		}
+
+		for _, g := range games {
+			err = p.join(g.Identifier)
+
+			// someone took the last slot before us, or destroyed the game
+			if errors.Is(err, ErrNoMoreSlot) || errors.Is(err, ErrWrongGame) {
+				continue
+			}
+			if err != nil {
+				return false, err
+			}
+
+			return false, p.start()
+		}
+
+		if !time.Now().Add(opts.Interval).Before(deadline) {
+			break
+		}
+
+		time.Sleep(opts.Interval)
+	}
+
+	return true, p.CreateAndJoinGame(gs)
+}
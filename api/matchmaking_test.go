@@ -0,0 +1,199 @@
+package api
+
+import (
+	"fmt"
+	"github.com/franela/goblin"
+	o "github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newFakeLobby returns a fake remote server with a few games to join. Game
+// 6 is the best one for one ant but it's full when we try to join it. It also
+// returns the games we joined and the ones we created.
+func newFakeLobby() (*httptest.Server, *[]string, *int) {
+	var joined []string
+	var created int
+
+	games := map[string]string{
+		"1": `"visibility": "public", "nb_player": 2, "minimal_nb_player": 2, "players": ["a"],
+		      "creation_date": "2015-02-01"`,
+		"2": `"visibility": "public", "nb_player": 4, "minimal_nb_player": 3,
+		      "players": ["a"], "creation_date": "2015-01-01"`,
+		"3": `"visibility": ["a", "b"], "players": ["a"]`,
+		"4": `"visibility": "public", "nb_player": 4, "players": ["a"],
+		      "nb_ant_per_player": 2`,
+		"5": `"visibility": "public", "nb_player": 4, "players": ["a"],
+		      "status": {"status": "playing"}`,
+		"6": `"visibility": "public", "nb_player": 3, "minimal_nb_player": 3,
+		      "players": ["a", "b"],
+		      "creation_date": "2015-01-01"`,
+		"7": `"visibility": "public", "nb_player": 4, "players": ["a", "ww"]`,
+	}
+
+	completed := func(w http.ResponseWriter, resp string) {
+		fmt.Fprintf(w, `{"status": "completed", "response": %s}`, resp)
+	}
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(
+		w http.ResponseWriter, r *http.Request) {
+
+		r.ParseForm()
+		id := r.Form.Get("id")
+
+		switch r.URL.Path {
+		case "/0/register", "/0/auth", "/0/logout":
+			completed(w, "{}")
+
+		case "/0/games":
+			var list []string
+			for i := 1; i <= len(games); i++ {
+				list = append(list, fmt.Sprintf(
+					`{"game_description": {"identifier": "%d"}}`, i))
+			}
+			completed(w, fmt.Sprintf(`{"games": [%s]}`, strings.Join(list, ", ")))
+
+		case "/0/status":
+			desc, ok := games[id]
+			if !ok {
+				desc = `"visibility": "public", "players": ["ww"]`
+			}
+			completed(w, fmt.Sprintf(`{"status": {"nb_ant_per_player": 1, "pace": 1,
+				"nb_turn": 10, "status": {"status": "waiting"}, %s}}`, desc))
+
+		case "/0/join":
+			if id == "6" {
+				fmt.Fprint(w, `{"status": "error", "response": {
+					"error_code": 1001223883, "error_msg": ""}}`)
+				return
+			}
+			joined = append(joined, id)
+			completed(w, "{}")
+
+		case "/0/create":
+			created++
+			completed(w, `{"identifier": "new"}`)
+
+		case "/0/play":
+			completed(w, `{"turn": 1, "observations": [[
+				{"id": 0, "x": 0, "y": 0, "dx": 1, "dy": 0, "energy": 90,
+				 "acid": 80, "brain": "controlled"}, [], []]]}`)
+
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+
+	return ts, &joined, &created
+}
+
+func TestMatchmaking(t *testing.T) {
+
+	g := goblin.Goblin(t)
+
+	o.RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	g.Describe("Constraints", func() {
+		gs := &GameSpec{AntsPerPlayer: 2, Pace: 5, Turns: 100}
+
+		g.It("Should accept any game without constraints", func() {
+			o.Expect(Constraints{}.Fits(gs)).To(o.BeTrue())
+		})
+
+		g.It("Should check the spec of the game", func() {
+			o.Expect(Constraints{AntsPerPlayer: 2, Pace: 5}.Fits(gs)).To(o.BeTrue())
+			o.Expect(Constraints{AntsPerPlayer: 1}.Fits(gs)).To(o.BeFalse())
+			o.Expect(Constraints{Turns: 10}.Fits(gs)).To(o.BeFalse())
+			o.Expect(Constraints{}.Fits(nil)).To(o.BeFalse())
+		})
+
+		g.It("Should not check the turns if the server didn't give them", func() {
+			o.Expect(Constraints{Turns: 10}.Fits(&GameSpec{Pace: 5})).To(o.BeTrue())
+		})
+
+		g.It("Should be printed", func() {
+			o.Expect(Constraints{AntsPerPlayer: 2}.String()).To(o.Equal(
+				"2 ants per player, pace any, any turns"))
+		})
+	})
+
+	g.Describe("sortOpenGames", func() {
+		g.It("Should put first the games which need the fewest players", func() {
+			game := func(id GameID, min, max int, players ...string) *GameStatus {
+				return &GameStatus{
+					Game: Game{Identifier: id,
+						Spec: &GameSpec{Public: true, MinPlayers: min, MaxPlayers: max}},
+					Status:  "waiting",
+					Players: players,
+				}
+			}
+
+			// "a" has more free slots than "b" but needs only one player
+			games := []*GameStatus{
+				game("b", 2, 2),
+				game("c", 0, 2),
+				game("a", 2, 8, "x"),
+			}
+
+			sortOpenGames(games)
+
+			o.Expect(games[0].Identifier).To(o.Equal(GameID("a")))
+			o.Expect(games[1].Identifier).To(o.Equal(GameID("b")))
+			o.Expect(games[2].Identifier).To(o.Equal(GameID("c")))
+		})
+	})
+
+	g.Describe("Matchmaking", func() {
+		var ts *httptest.Server
+		var joined *[]string
+		var created *int
+		var p *Player
+
+		g.BeforeEach(func() {
+			ts, joined, created = newFakeLobby()
+
+			p = NewPlayer("ww", "a")
+			p.Client.SetBaseURL(ts.URL)
+			p.Connect()
+		})
+
+		g.AfterEach(func() {
+			p.Quit()
+			ts.Close()
+		})
+
+		g.It("Should find the open games, the best ones first", func() {
+			games, err := p.Client.OpenGames(Constraints{AntsPerPlayer: 1}, 2)
+			o.Expect(err).To(o.BeNil())
+
+			var ids []GameID
+			for _, g := range games {
+				ids = append(ids, g.Identifier)
+			}
+			o.Expect(ids).To(o.Equal([]GameID{"6", "1", "2"}))
+		})
+
+		g.It("Should join the best game with a free slot", func() {
+			created, err := p.AutoJoinGame(AutoJoinOptions{
+				Constraints: Constraints{AntsPerPlayer: 1},
+			}, &GameSpec{})
+
+			o.Expect(err).To(o.BeNil())
+			o.Expect(created).To(o.BeFalse())
+			o.Expect(*joined).To(o.Equal([]string{"1"}))
+		})
+
+		g.It("Should create a game if there's none to join", func() {
+			ok, err := p.AutoJoinGame(AutoJoinOptions{
+				Constraints: Constraints{Pace: 42},
+			}, testSpec())
+
+			o.Expect(err).To(o.BeNil())
+			o.Expect(ok).To(o.BeTrue())
+			o.Expect(*created).To(o.Equal(1))
+			o.Expect(*joined).To(o.Equal([]string{"new"}))
+		})
+	})
+}
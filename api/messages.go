@@ -0,0 +1,292 @@
+package api
+
+// This file describes how to read back the messages the game server sends to
+// AIs and plugin listeners (see `docs/ai_protocol.md` and `api/server.go`).
+// This is used by listeners written in Go, which receive the same messages as
+// the external ones.
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// An EnemyAnt is an enemy ant as described in a turn message
+type EnemyAnt struct {
+	BasicAntStatus
+
+	// the local ID given by the game server's tracker
+	ID int
+	// the index of its owner in the players list, or -1 if we don't know it
+	Owner int
+}
+
+// A TurnMessage is a message sent by the game server at each turn
+type TurnMessage struct {
+	// the turn number
+	Turn int
+	// the number of ants per player
+	AntsPerPlayer int
+	// the number of players
+	Players int
+	// false if the game is over
+	Playing bool
+
+	// our ants. They don't have any vision nor visible ants.
+	Ants []AntStatus
+	// the enemy ants we see
+	Enemies []EnemyAnt
+	// the map as we know it
+	Map *PartialMap
+	// the facts shared by the AIs, sorted by key (see `api/blackboard.go`)
+	Blackboard []Fact
+	// the annotations sent by the AIs. Only listeners get them, see
+	// `ReadListenerMessage`.
+	Annotations []Annotation
+}
+
+// readInts reads one line of space-separated integers. It fails if there are
+// less than `min` integers.
+func readInts(r *bufio.Reader, min int) ([]int, error) {
+	line, err := r.ReadString('\n')
+
+	if err != nil && (err != io.EOF || line == "") {
+		return nil, err
+	}
+
+	fields := strings.Fields(line)
+
+	if len(fields) < min {
+		return nil, ErrBadMessage
+	}
+
+	ints := make([]int, len(fields))
+
+	for i, f := range fields {
+		if ints[i], err = strconv.Atoi(f); err != nil {
+			return nil, ErrBadMessage
+		}
+	}
+
+	return ints, nil
+}
+
+// brainState is the opposite of brainNumber in `api/server.go`
+func brainState(n int) string {
+	if n == 1 {
+		return "controlled"
+	}
+
+	return ""
+}
+
+// contentName is the opposite of contentNumber in `api/server.go`
+func contentName(n int) string {
+	for name, c := range contents {
+		if c == n {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// ReadTurnMessage reads a turn message. It returns io.EOF if there are no
+// more messages.
+func ReadTurnMessage(r *bufio.Reader) (m *TurnMessage, err error) {
+	var ints []int
+
+	// header
+	if ints, err = readInts(r, 4); err != nil {
+		return
+	}
+
+	m = &TurnMessage{
+		Turn:          ints[0],
+		AntsPerPlayer: ints[1],
+		Players:       ints[2],
+		Playing:       ints[3] == 1,
+		Map:           NewPartialMap(),
+	}
+
+	// our ants
+	for i := 0; i < m.AntsPerPlayer; i++ {
+		if ints, err = readInts(r, 8); err != nil {
+			return nil, badMessage(err)
+		}
+
+		m.Ants = append(m.Ants, AntStatus{
+			BasicAntStatus: BasicAntStatus{
+				Pos:   Position{X: ints[1], Y: ints[2]},
+				Dir:   Direction{X: ints[3], Y: ints[4]},
+				Brain: brainState(ints[7]),
+			},
+			ID:     ints[0],
+			Energy: ints[5],
+			Acid:   ints[6],
+		})
+	}
+
+	// enemy ants
+	if ints, err = readInts(r, 1); err != nil {
+		return nil, badMessage(err)
+	}
+
+	for i, n := 0, ints[0]; i < n; i++ {
+		if ints, err = readInts(r, 5); err != nil {
+			return nil, badMessage(err)
+		}
+
+		enemy := EnemyAnt{
+			BasicAntStatus: BasicAntStatus{
+				Pos:   Position{X: ints[0], Y: ints[1]},
+				Dir:   Direction{X: ints[2], Y: ints[3]},
+				Brain: brainState(ints[4]),
+			},
+			ID:    -1,
+			Owner: -1,
+		}
+
+		// older game servers don't send the ID and the owner
+		if len(ints) >= 7 {
+			enemy.ID = ints[5]
+			enemy.Owner = ints[6]
+		}
+
+		m.Enemies = append(m.Enemies, enemy)
+	}
+
+	// map
+	if ints, err = readInts(r, 3); err != nil {
+		return nil, badMessage(err)
+	}
+
+	for i, n := 0, ints[2]; i < n; i++ {
+		if ints, err = readInts(r, 4); err != nil {
+			return nil, badMessage(err)
+		}
+
+		m.Map.SetCell(&Cell{
+			Pos:        Position{X: ints[0], Y: ints[1]},
+			Content:    contentName(ints[2]),
+			Visibility: ints[3] == 1,
+		})
+	}
+
+	m.Map.ClearDirty()
+
+	// blackboard
+	if ints, err = readInts(r, 1); err != nil {
+		return nil, badMessage(err)
+	}
+
+	for i, n := 0, ints[0]; i < n; i++ {
+		var f Fact
+
+		if f, err = readFact(r); err != nil {
+			return nil, badMessage(err)
+		}
+
+		m.Blackboard = append(m.Blackboard, f)
+	}
+
+	return
+}
+
+// readFact reads one fact line as written by `Fact.protocolLine()`
+func readFact(r *bufio.Reader) (f Fact, err error) {
+	line, err := r.ReadString('\n')
+
+	if err != nil && (err != io.EOF || line == "") {
+		return
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) < 3 {
+		return f, ErrBadMessage
+	}
+
+	ai, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return f, ErrBadMessage
+	}
+
+	f, _ = ParseFact(ai, afterFields(line, 1))
+	return f, nil
+}
+
+// ReadListenerMessage reads a turn message as sent to listeners, i.e. followed
+// by the annotations the AIs sent during this turn. It returns io.EOF if there
+// are no more messages.
+func ReadListenerMessage(r *bufio.Reader) (m *TurnMessage, err error) {
+	if m, err = ReadTurnMessage(r); err != nil {
+		return
+	}
+
+	var ints []int
+
+	if ints, err = readInts(r, 1); err != nil {
+		return nil, badMessage(err)
+	}
+
+	for i, n := 0, ints[0]; i < n; i++ {
+		var a Annotation
+
+		if a, err = readAnnotation(r); err != nil {
+			return nil, badMessage(err)
+		}
+
+		m.Annotations = append(m.Annotations, a)
+	}
+
+	return
+}
+
+// readAnnotation reads one annotation line as written by
+// `Annotation.protocolLine()`
+func readAnnotation(r *bufio.Reader) (a Annotation, err error) {
+	line, err := r.ReadString('\n')
+
+	if err != nil && (err != io.EOF || line == "") {
+		return
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return a, ErrBadMessage
+	}
+
+	ai, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return a, ErrBadMessage
+	}
+
+	annotation := afterFields(line, 1)
+
+	// paths have their length before their positions
+	if fields[1] == "path" {
+		annotation = "path " + afterFields(line, 3)
+	}
+
+	a, ok := ParseAnnotation(ai, annotation)
+	if !ok {
+		return a, ErrBadMessage
+	}
+
+	if a.Kind == "path" && fields[2] != strconv.Itoa(len(a.Positions)) {
+		return a, ErrBadMessage
+	}
+
+	return a, nil
+}
+
+// badMessage converts an error that happened in the middle of a message. An
+// EOF there means the message was truncated.
+func badMessage(err error) error {
+	if err == io.EOF {
+		return ErrBadMessage
+	}
+
+	return err
+}
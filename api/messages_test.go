@@ -0,0 +1,217 @@
+package api
+
+import (
+	"bufio"
+	"github.com/franela/goblin"
+	o "github.com/onsi/gomega"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// a fakeActor remembers all the messages it receives
+type fakeActor struct {
+	messages []string
+}
+
+func (a *fakeActor) Start(wg *sync.WaitGroup) { wg.Done() }
+func (a *fakeActor) Send(m string)            { a.messages = append(a.messages, m) }
+func (a *fakeActor) Read() string             { return "" }
+
+// newTestPlayer returns a player in the middle of a game, with one ant
+func newTestPlayer() *Player {
+	p := NewPlayer("foo", "bar")
+
+	vision := NewPartialMap()
+	vision.SetCell(&Cell{Pos: Position{X: 3, Y: 4}, Content: "grass"})
+	vision.SetCell(&Cell{Pos: Position{X: 4, Y: 4}, Content: "sugar"})
+
+	enemy := BasicAntStatus{
+		Pos:   Position{X: 4, Y: 4},
+		Dir:   Direction{X: -1, Y: 0},
+		Brain: "controlled",
+	}
+
+	ant := AntStatus{
+		BasicAntStatus: BasicAntStatus{
+			Pos:   Position{X: 3, Y: 4},
+			Dir:   Direction{X: 1, Y: 0},
+			Brain: "controlled",
+		},
+		ID:     0,
+		Energy: 90,
+		Acid:   80,
+		Vision: vision,
+	}
+
+	ant.VisibleAnts = []BasicAntStatus{ant.BasicAntStatus, enemy}
+
+	p.status = &GameStatus{
+		Game: Game{
+			Identifier: "42",
+			Spec:       &GameSpec{AntsPerPlayer: 1},
+		},
+		Status:  "playing",
+		Players: []string{"foo", "qux"},
+		Score:   map[string]int{"foo": 1, "qux": 2},
+	}
+
+	p.turn = &Turn{Number: 3, AntsStatuses: []AntStatus{ant}}
+
+	return p
+}
+
+func TestMessages(t *testing.T) {
+
+	g := goblin.Goblin(t)
+
+	o.RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	g.Describe("ReadTurnMessage", func() {
+		read := func(s string) (*TurnMessage, error) {
+			return ReadTurnMessage(bufio.NewReader(strings.NewReader(s)))
+		}
+
+		g.It("Should return io.EOF on an empty input", func() {
+			_, err := read("")
+			o.Expect(err).To(o.Equal(io.EOF))
+		})
+
+		g.It("Should return ErrBadMessage on a truncated message", func() {
+			_, err := read("1 2 1 1\n0 0 0 1 0 100 100 1\n")
+			o.Expect(err).To(o.Equal(ErrBadMessage))
+		})
+
+		g.It("Should return ErrBadMessage on a non-numeric line", func() {
+			_, err := read("1 a 1 1\n")
+			o.Expect(err).To(o.Equal(ErrBadMessage))
+		})
+
+		g.It("Should parse a message", func() {
+			m, err := read("4 1 2 1\n" +
+				"0 5 3 -1 0 17 56 1\n" +
+				"1\n" +
+				"6 3 1 0 1 12 1\n" +
+				"7 4 2\n" +
+				"5 3 0 1\n" +
+				"6 3 5 0\n" +
+				"0\n")
+
+			o.Expect(err).To(o.BeNil())
+			o.Expect(m.Turn).To(o.Equal(4))
+			o.Expect(m.Playing).To(o.BeTrue())
+			o.Expect(m.Ants).To(o.HaveLen(1))
+			o.Expect(m.Ants[0].Energy).To(o.Equal(17))
+			o.Expect(m.Ants[0].Brain).To(o.Equal("controlled"))
+			o.Expect(m.Enemies).To(o.HaveLen(1))
+			o.Expect(m.Enemies[0].ID).To(o.Equal(12))
+			o.Expect(m.Enemies[0].Owner).To(o.Equal(1))
+			o.Expect(m.Map.Cells).To(o.HaveLen(2))
+			o.Expect(m.Map.Cell(6, 3).Content).To(o.Equal("meat"))
+			o.Expect(m.Map.Cell(6, 3).Visibility).To(o.BeFalse())
+		})
+
+		g.It("Should parse the blackboard", func() {
+			m, err := read("4 0 2 1\n0\n0 0 0\n2\n" +
+				"1 food:3:4 found by  ant 2\n" +
+				"0 target 5\n")
+
+			o.Expect(err).To(o.BeNil())
+			o.Expect(m.Blackboard).To(o.Equal([]Fact{
+				{AI: 1, Key: "food:3:4", Value: "found by  ant 2"},
+				{AI: 0, Key: "target", Value: "5"},
+			}))
+		})
+
+		g.It("Should return ErrBadMessage on a malformed fact", func() {
+			_, err := read("4 0 2 1\n0\n0 0 0\n1\n0 target\n")
+			o.Expect(err).To(o.Equal(ErrBadMessage))
+		})
+
+		g.It("Should accept enemy lines without ID and owner", func() {
+			m, err := read("4 0 2 1\n1\n6 3 1 0 1\n0 0 0\n0\n")
+
+			o.Expect(err).To(o.BeNil())
+			o.Expect(m.Enemies[0].ID).To(o.Equal(-1))
+			o.Expect(m.Enemies[0].Owner).To(o.Equal(-1))
+		})
+
+		g.It("Should read back the messages sent by a Player to its AIs", func() {
+			p := newTestPlayer()
+			ai := &fakeActor{}
+			p.AIs.AddActor(ai)
+
+			p.sendTurnStatusToAIs()
+
+			o.Expect(ai.messages).To(o.HaveLen(1))
+
+			m, err := read(ai.messages[0])
+
+			o.Expect(err).To(o.BeNil())
+			o.Expect(m.Turn).To(o.Equal(3))
+			o.Expect(m.Ants[0].Pos).To(o.Equal(Position{X: 3, Y: 4}))
+			o.Expect(m.Enemies).To(o.HaveLen(1))
+			o.Expect(m.Enemies[0].Owner).To(o.Equal(1))
+			o.Expect(m.Map.Cell(4, 4).Content).To(o.Equal("sugar"))
+			o.Expect(m.Map.Cell(4, 4).Visibility).To(o.BeTrue())
+		})
+	})
+
+	g.Describe("ReadListenerMessage", func() {
+		read := func(s string) (*TurnMessage, error) {
+			return ReadListenerMessage(bufio.NewReader(strings.NewReader(s)))
+		}
+
+		g.It("Should return io.EOF on an empty input", func() {
+			_, err := read("")
+			o.Expect(err).To(o.Equal(io.EOF))
+		})
+
+		g.It("Should return ErrBadMessage if there are no annotations", func() {
+			_, err := read("4 0 2 1\n0\n0 0 0\n0\n")
+			o.Expect(err).To(o.Equal(ErrBadMessage))
+		})
+
+		g.It("Should return ErrBadMessage on a malformed annotation", func() {
+			_, err := read("4 0 2 1\n0\n0 0 0\n0\n1\n0 path 2 1 1\n")
+			o.Expect(err).To(o.Equal(ErrBadMessage))
+		})
+
+		g.It("Should parse the annotations", func() {
+			m, err := read("4 0 2 1\n0\n0 0 0\n0\n3\n" +
+				"0 mark 3 4 some food\n" +
+				"1 path 2 3 4 4 5\n" +
+				"1 log going  north\n")
+
+			o.Expect(err).To(o.BeNil())
+			o.Expect(m.Turn).To(o.Equal(4))
+			o.Expect(m.Annotations).To(o.Equal([]Annotation{
+				{AI: 0, Kind: "mark", Positions: []Position{{X: 3, Y: 4}}, Text: "some food"},
+				{AI: 1, Kind: "path", Positions: []Position{{X: 3, Y: 4}, {X: 4, Y: 5}}},
+				{AI: 1, Kind: "log", Text: "going  north"},
+			}))
+		})
+
+		g.It("Should read back the messages sent by a Player to its listeners", func() {
+			p := newTestPlayer()
+			listener := &fakeActor{}
+			p.Listeners.AddActor(listener)
+
+			p.sendTurnStatusToAIs()
+			p.sendTurnStatusToListeners([]Annotation{
+				{AI: 0, Kind: "mark", Positions: []Position{{X: 4, Y: 4}}, Text: "enemy"},
+			})
+
+			o.Expect(listener.messages).To(o.HaveLen(1))
+
+			m, err := read(listener.messages[0])
+
+			o.Expect(err).To(o.BeNil())
+			o.Expect(m.Turn).To(o.Equal(3))
+			o.Expect(m.Map.Cell(4, 4).Content).To(o.Equal("sugar"))
+			o.Expect(m.Annotations).To(o.HaveLen(1))
+			o.Expect(m.Annotations[0].Text).To(o.Equal("enemy"))
+		})
+	})
+}
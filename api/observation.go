@@ -0,0 +1,175 @@
+package api
+
+// This file describes observations, which encode what a player knows at a
+// turn as fixed-size arrays of numbers. They're used by the learning
+// environment (see `api/env.go`): a model can't easily take a variable-size
+// map and a variable list of ants as its input.
+//
+// The map is encoded as feature planes: one `Width`×`Height` plane per
+// feature, e.g. "this cell is a rock" or "there's an enemy ant here", with 1
+// where it's true and 0 elsewhere. Cells outside of the planes are ignored.
+// Our ants are encoded as one vector of features per ant.
+
+// the feature planes, in order
+const (
+	// the cell is known
+	PlaneKnown = iota
+	// the cell is currently seen by one of our ants
+	PlaneVisible
+	PlaneGrass
+	PlaneRock
+	PlaneWater
+	// the cell contains food: sugar, mill or meat
+	PlaneFood
+	// one of our ants is on the cell
+	PlaneAnts
+	// a visible enemy ant is on the cell
+	PlaneEnemies
+
+	// the number of planes
+	Planes
+)
+
+// the features of each ant, in order
+const (
+	// 1 if the ant is alive, 0 otherwise. All the other features are 0 if
+	// it's not.
+	AntAlive = iota
+	AntX
+	AntY
+	AntDX
+	AntDY
+	AntEnergy
+	AntAcid
+	// 1 if the ant is controlled
+	AntControlled
+
+	// the number of features of each ant
+	AntFeatures
+)
+
+// An Observation is a fixed-size encoding of a turn
+type Observation struct {
+	// the turn number
+	Turn int `json:"turn"`
+	// our score
+	Score int `json:"score"`
+
+	// the shape of `Planes`: [Planes, Height, Width]
+	Shape [3]int `json:"shape"`
+	// the feature planes, flattened: the value of the plane `p` for the cell
+	// (x, y) is at the index (p*Height + y)*Width + x. The first row is the
+	// bottom of the map.
+	Planes []float32 `json:"planes"`
+
+	// the shape of `Ants`: [AntsPerPlayer, AntFeatures]
+	AntsShape [2]int `json:"ants_shape"`
+	// the features of our ants, flattened and indexed by their ID: the
+	// feature `f` of the ant `i` is at the index i*AntFeatures + f.
+	Ants []float32 `json:"ants"`
+}
+
+// NewObservation returns a pointer on a new, empty, observation with planes
+// of the given size for the given number of ants per player
+func NewObservation(width, height, ants int) *Observation {
+	return &Observation{
+		Shape:     [3]int{Planes, height, width},
+		Planes:    make([]float32, Planes*height*width),
+		AntsShape: [2]int{ants, AntFeatures},
+		Ants:      make([]float32, ants*AntFeatures),
+	}
+}
+
+// Plane returns the value of a plane for a cell. It returns 0 for cells
+// outside of the planes.
+func (o *Observation) Plane(plane, x, y int) float32 {
+	if i, ok := o.planeIndex(plane, x, y); ok {
+		return o.Planes[i]
+	}
+	return 0
+}
+
+// Ant returns a feature of an ant, or 0 if there's no such ant
+func (o *Observation) Ant(id, feature int) float32 {
+	if id < 0 || id >= o.AntsShape[0] {
+		return 0
+	}
+	return o.Ants[id*AntFeatures+feature]
+}
+
+// planeIndex returns the index of a plane's cell in `Planes`. The boolean is
+// false if the cell is outside of the planes.
+func (o *Observation) planeIndex(plane, x, y int) (int, bool) {
+	height, width := o.Shape[1], o.Shape[2]
+
+	if x < 0 || y < 0 || x >= width || y >= height {
+		return 0, false
+	}
+
+	return (plane*height+y)*width + x, true
+}
+
+// set sets a plane to 1 for a cell
+func (o *Observation) set(plane int, p Position) {
+	if i, ok := o.planeIndex(plane, p.X, p.Y); ok {
+		o.Planes[i] = 1
+	}
+}
+
+// the plane of each cell content
+var contentPlanes = map[string]int{
+	"grass": PlaneGrass,
+	"rock":  PlaneRock,
+	"water": PlaneWater,
+	"sugar": PlaneFood,
+	"mill":  PlaneFood,
+	"meat":  PlaneFood,
+}
+
+// observe encodes what the player knows at this turn in an observation with
+// planes of the given size
+func (p *Player) observe(width, height int) *Observation {
+	o := NewObservation(width, height, p.status.Game.Spec.AntsPerPlayer)
+
+	o.Turn = p.turn.Number
+	o.Score = p.status.Score[p.username]
+
+	for pos, c := range p.partialMap.Cells {
+		o.set(PlaneKnown, pos)
+
+		if c.Visibility {
+			o.set(PlaneVisible, pos)
+		}
+
+		if plane, ok := contentPlanes[c.Content]; ok {
+			o.set(plane, pos)
+		}
+	}
+
+	for _, e := range p.enemies.Ants() {
+		if e.Visible {
+			o.set(PlaneEnemies, e.Pos)
+		}
+	}
+
+	for _, a := range p.turn.AntsStatuses {
+		o.set(PlaneAnts, a.Pos)
+
+		if a.ID < 0 || a.ID >= o.AntsShape[0] {
+			continue
+		}
+
+		features := o.Ants[a.ID*AntFeatures : (a.ID+1)*AntFeatures]
+
+		features[AntAlive] = 1
+		features[AntX] = float32(a.Pos.X)
+		features[AntY] = float32(a.Pos.Y)
+		features[AntDX] = float32(a.Dir.X)
+		features[AntDY] = float32(a.Dir.Y)
+		features[AntEnergy] = float32(a.Energy)
+		features[AntAcid] = float32(a.Acid)
+		features[AntControlled] = float32(brainNumber(a.BasicAntStatus))
+	}
+
+	return o
+}
@@ -20,9 +20,21 @@ func NewListener(name string, arg ...string) *Listener {
 	}
 }
 
+// A TurnObserver is a listener that runs in our own process instead of an
+// external command. Instead of the protocol message it gets the turn as it's
+// recorded in the journal (see `api/journal.go`) and the map as we know it.
+// The map changes on each turn so it must not be kept after `Observe` returns.
+type TurnObserver interface {
+	Observe(e *JournalEntry, m *PartialMap)
+}
+
 // A ListenersPool is just a wrapper around a Stage that contains Listeners
-// only.
-type ListenersPool struct{ Stage }
+// only. It can also contain TurnObservers.
+type ListenersPool struct {
+	Stage
+
+	observers []TurnObserver
+}
 
 // NewListenersPool returns a new, empty, ListenersPool
 func NewListenersPool() *ListenersPool {
@@ -35,3 +47,20 @@ func NewListenersPool() *ListenersPool {
 func (pool *ListenersPool) AddListener(name string, args ...string) {
 	pool.AddActor(NewListener(name, args...))
 }
+
+// AddObserver adds a new TurnObserver to the pool
+func (pool *ListenersPool) AddObserver(o TurnObserver) {
+	pool.observers = append(pool.observers, o)
+}
+
+// HasObservers returns true if the pool contains at least one TurnObserver
+func (pool *ListenersPool) HasObservers() bool {
+	return len(pool.observers) > 0
+}
+
+// ObserveAll sends a turn to all TurnObservers
+func (pool *ListenersPool) ObserveAll(e *JournalEntry, m *PartialMap) {
+	for _, o := range pool.observers {
+		o.Observe(e, m)
+	}
+}
@@ -3,7 +3,10 @@ package api
 // This file adds `.String()` methods on our structs to get human-readable
 // descriptions.
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 func (g Game) String() string {
 	return fmt.Sprintf("Game %s, created on %s by %s (%s)",
@@ -15,6 +18,18 @@ func (g GameStatus) String() string {
 		g.Identifier, g.CreationDate, g.Creator, g.Teaser, g.Turn, g.Status)
 }
 
+func (gs GameSpec) String() string {
+	users := "public"
+	if !gs.Public {
+		users = "private (" + strings.Join(gs.Players, ", ") + ")"
+	}
+
+	return fmt.Sprintf("%s game %q: %d turns at pace %d, %d-%d players "+
+		"with %d ants, energy %d, acid %d", users, gs.Description, gs.Turns,
+		gs.Pace, gs.MinPlayers, gs.MaxPlayers, gs.AntsPerPlayer,
+		gs.InitialEnergy, gs.InitialAcid)
+}
+
 func (cmds Commands) String() string {
 	return string(cmds)
 }
@@ -24,6 +24,13 @@ func TestPrettyPrinting(t *testing.T) {
 		})
 	})
 
+	g.Describe("GameSpec#String()", func() {
+		g.It("Should list the players of private games", func() {
+			gs := GameSpec{Players: []string{"foo", "bar"}}
+			o.Expect(gs.String()).To(o.HavePrefix("private (foo, bar) game"))
+		})
+	})
+
 	g.Describe("Commands#String()", func() {
 		g.It("Should return an empty string if there're no commands", func() {
 			o.Expect(Commands("").String()).To(o.Equal(""))
@@ -0,0 +1,137 @@
+package api
+
+// This file describes game spec profiles: named sets of game parameters kept
+// in a JSON file so that we don't have to repeat them on each command-line.
+// Profiles can inherit from each other:
+//
+//     {
+//       "quick": {"turns": 20, "pace": 5},
+//       "duel": {"inherits": "quick", "turns": 200, "minPlayers": 2, "maxPlayers": 2},
+//       "big-map": {"maxPlayers": 8, "antsPerPlayer": 5}
+//     }
+//
+// A profile takes the fields it doesn't set from the one it inherits, which
+// is the "default" profile if it doesn't say otherwise. That one has the
+// default values of the command-line flags; it can be redefined in the file.
+// Since booleans can't be unset, a profile is public only if it says so or
+// if it has no players and inherits a public one.
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// DefaultProfile is the name of the profile every other one inherits from
+const DefaultProfile = "default"
+
+// defaultSpec is the spec of games if neither the profiles nor the
+// command-line flags set their parameters
+var defaultSpec = GameSpec{
+	Public:        true,
+	Description:   "a test",
+	Pace:          1,
+	Turns:         10,
+	AntsPerPlayer: 1,
+	MaxPlayers:    1,
+	MinPlayers:    1,
+	InitialEnergy: 100,
+	InitialAcid:   100,
+}
+
+// A Profile is a named game spec
+type Profile struct {
+	// the name of the profile it inherits from, if it's not the default one
+	Inherits string `json:"inherits"`
+
+	GameSpec
+}
+
+// Profiles are game spec profiles indexed by their name
+type Profiles map[string]*Profile
+
+// DefaultProfiles returns the profiles we have without any file: only the
+// default one
+func DefaultProfiles() Profiles {
+	return Profiles{DefaultProfile: &Profile{GameSpec: defaultSpec}}
+}
+
+// LoadProfiles reads profiles from a JSON file. It returns the default
+// profiles if the file doesn't exist.
+func LoadProfiles(path string) (Profiles, error) {
+	ps := DefaultProfiles()
+
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return ps, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if err = json.NewDecoder(f).Decode(&ps); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	return ps, nil
+}
+
+// Names returns the names of all profiles, sorted
+func (ps Profiles) Names() []string {
+	var names []string
+
+	for name := range ps {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// parent returns the name of the profile a profile inherits from, or an
+// empty string if it's the default one
+func (ps Profiles) parent(name string) string {
+	if name == DefaultProfile {
+		return ""
+	}
+
+	if p := ps[name]; p != nil && p.Inherits != "" {
+		return p.Inherits
+	}
+
+	return DefaultProfile
+}
+
+// Spec returns the spec of a profile with all the fields it inherits. It
+// fails if the profile or one of its ancestors doesn't exist, or if they
+// inherit from each other in a loop.
+func (ps Profiles) Spec(name string) (GameSpec, error) {
+	var gs GameSpec
+
+	seen := make(map[string]bool)
+
+	for n := name; n != ""; n = ps.parent(n) {
+		p, ok := ps[n]
+		if !ok || p == nil {
+			if n == name {
+				return gs, fmt.Errorf("unknown profile %q", n)
+			}
+			return gs, fmt.Errorf("profile %q inherits from an unknown profile %q", name, n)
+		}
+
+		if seen[n] {
+			return gs, fmt.Errorf("profile %q inherits from itself", n)
+		}
+		seen[n] = true
+
+		gs.Inherit(p.GameSpec)
+	}
+
+	// the default profile can be redefined without all the fields
+	gs.Inherit(defaultSpec)
+
+	return gs, nil
+}
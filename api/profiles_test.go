@@ -0,0 +1,109 @@
+package api
+
+import (
+	"github.com/franela/goblin"
+	o "github.com/onsi/gomega"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestProfiles(t *testing.T) {
+
+	g := goblin.Goblin(t)
+
+	o.RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	g.Describe("LoadProfiles", func() {
+		var dir string
+
+		g.BeforeEach(func() {
+			dir, _ = ioutil.TempDir("", "antroid-profiles")
+		})
+
+		g.AfterEach(func() {
+			os.RemoveAll(dir)
+		})
+
+		g.It("Should return the default profile without file", func() {
+			ps, err := LoadProfiles(filepath.Join(dir, "nope.json"))
+
+			o.Expect(err).To(o.BeNil())
+			o.Expect(ps.Names()).To(o.Equal([]string{DefaultProfile}))
+		})
+
+		g.It("Should read the profiles of a file", func() {
+			path := filepath.Join(dir, "profiles.json")
+			ioutil.WriteFile(path, []byte(`{
+				"quick": {"turns": 20, "pace": 5},
+				"duel": {"inherits": "quick", "maxPlayers": 2, "players": ["a", "b"]}
+			}`), 0644)
+
+			ps, err := LoadProfiles(path)
+
+			o.Expect(err).To(o.BeNil())
+			o.Expect(ps.Names()).To(o.Equal([]string{"default", "duel", "quick"}))
+			o.Expect(ps["duel"].Inherits).To(o.Equal("quick"))
+			o.Expect(ps["duel"].MaxPlayers).To(o.Equal(2))
+		})
+
+		g.It("Should fail on a bad file", func() {
+			path := filepath.Join(dir, "profiles.json")
+			ioutil.WriteFile(path, []byte(`{"quick": 42}`), 0644)
+
+			_, err := LoadProfiles(path)
+			o.Expect(err).NotTo(o.BeNil())
+		})
+	})
+
+	g.Describe("Profiles.Spec", func() {
+		ps := DefaultProfiles()
+		ps["quick"] = &Profile{GameSpec: GameSpec{Turns: 20, Pace: 5}}
+		ps["duel"] = &Profile{Inherits: "quick", GameSpec: GameSpec{
+			MaxPlayers: 2,
+			Players:    []string{"a", "b"},
+		}}
+		ps["loop"] = &Profile{Inherits: "loop"}
+		ps["orphan"] = &Profile{Inherits: "nope"}
+
+		g.It("Should return the default spec", func() {
+			gs, err := ps.Spec(DefaultProfile)
+
+			o.Expect(err).To(o.BeNil())
+			o.Expect(gs).To(o.Equal(defaultSpec))
+		})
+
+		g.It("Should inherit the fields of the ancestors", func() {
+			gs, err := ps.Spec("duel")
+
+			o.Expect(err).To(o.BeNil())
+			o.Expect(gs.Public).To(o.BeFalse())
+			o.Expect(gs.MaxPlayers).To(o.Equal(2))
+			o.Expect(gs.Turns).To(o.Equal(20))
+			o.Expect(gs.InitialAcid).To(o.Equal(defaultSpec.InitialAcid))
+		})
+
+		g.It("Should fill a redefined default profile", func() {
+			ps := Profiles{DefaultProfile: &Profile{GameSpec: GameSpec{Turns: 42}}}
+			gs, err := ps.Spec(DefaultProfile)
+
+			o.Expect(err).To(o.BeNil())
+			o.Expect(gs.Turns).To(o.Equal(42))
+			o.Expect(gs.Pace).To(o.Equal(defaultSpec.Pace))
+		})
+
+		g.It("Should fail on unknown profiles", func() {
+			_, err := ps.Spec("nope")
+			o.Expect(err).NotTo(o.BeNil())
+
+			_, err = ps.Spec("orphan")
+			o.Expect(err).NotTo(o.BeNil())
+		})
+
+		g.It("Should fail on inheritance loops", func() {
+			_, err := ps.Spec("loop")
+			o.Expect(err).NotTo(o.BeNil())
+		})
+	})
+}
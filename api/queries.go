@@ -0,0 +1,231 @@
+package api
+
+// This file describes the queries AIs can send during a turn. Instead of
+// sending their command right away, AIs can ask the game server for things it
+// can compute from the map as it knows it, e.g. a path between two cells or
+// the nearest food. Each query is one line starting with `?` and gets one line
+// back. See `docs/ai_protocol.md` for the details.
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// A QueryHandler answers the queries sent by AIs
+type QueryHandler interface {
+	// Query takes a query without its leading `?` and returns the answer
+	// without the trailing newline.
+	Query(q string) string
+}
+
+// queryable is implemented by actors which can send queries
+type queryable interface {
+	SetQueryHandler(QueryHandler)
+}
+
+// This is the answer to queries which don't have a result, e.g. when there's
+// no path between two cells.
+const noResult = "-1"
+
+// internal helper to format an answer to a query we can't understand
+func queryError(format string, args ...interface{}) string {
+	return "error: " + fmt.Sprintf(format, args...)
+}
+
+// Walkable returns true if an ant can walk on this cell. We don't know what's
+// on unknown cells so they're not walkable.
+func Walkable(c *Cell) bool {
+	return c != nil && c.Content != "rock" && c.Content != "water"
+}
+
+// all the directions, in the order we try them when we look for a path
+var directions = []Direction{
+	{X: 0, Y: 1},
+	{X: 1, Y: 1},
+	{X: 1, Y: 0},
+	{X: 1, Y: -1},
+	{X: 0, Y: -1},
+	{X: -1, Y: -1},
+	{X: -1, Y: 0},
+	{X: -1, Y: 1},
+}
+
+// search does a breadth-first search on the map from the given position until
+// it finds one for which `goal` returns true. It returns the path to this
+// position, without the starting one, and false if there's no such position.
+// Ants can move in the 8 directions. The goal doesn't need to be walkable.
+func search(m MapInterface, from Position, goal func(Position) bool) ([]Position, bool) {
+	if goal(from) {
+		return []Position{}, true
+	}
+
+	previous := map[Position]Position{from: from}
+	queue := []Position{from}
+
+	for len(queue) > 0 {
+		pos := queue[0]
+		queue = queue[1:]
+
+		for _, d := range directions {
+			next := Position{X: pos.X + d.X, Y: pos.Y + d.Y}
+
+			if _, seen := previous[next]; seen {
+				continue
+			}
+
+			reached := goal(next)
+			if !reached && !Walkable(m.Cell(next.X, next.Y)) {
+				continue
+			}
+
+			previous[next] = pos
+
+			if reached {
+				// walk back to the start, then put the path in order
+				path := []Position{next}
+				for p := pos; p != from; p = previous[p] {
+					path = append(path, p)
+				}
+				for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+					path[i], path[j] = path[j], path[i]
+				}
+				return path, true
+			}
+
+			queue = append(queue, next)
+		}
+	}
+
+	return nil, false
+}
+
+// FindPath returns the shortest path between two positions on the map, using
+// only the cells we know. The path doesn't contain the starting position. The
+// boolean is false if there's no such path.
+func FindPath(m MapInterface, from, to Position) ([]Position, bool) {
+	return search(m, from, func(p Position) bool { return p == to })
+}
+
+// Query answers an AI's query using the map as we know it. It implements
+// QueryHandler.
+func (p *Player) Query(q string) string {
+	// AIs send their queries at the same time
+	p.queryLock.Lock()
+	defer p.queryLock.Unlock()
+
+	words := strings.Fields(q)
+	if len(words) == 0 {
+		return queryError("empty query")
+	}
+
+	switch words[0] {
+	case "path":
+		return p.queryPath(words[1:])
+	case "nearest":
+		return p.queryNearest(words[1:])
+	}
+
+	return queryError("unknown query %q", words[0])
+}
+
+// queryPath answers `?path X1 Y1 X2 Y2` with `N X Y X Y ...`, with `N` the
+// number of steps followed by the positions of each step.
+func (p *Player) queryPath(args []string) string {
+	if len(args) != 4 {
+		return queryError("usage: ?path X1 Y1 X2 Y2")
+	}
+
+	coords := make([]int, len(args))
+	for i, arg := range args {
+		var err error
+		if coords[i], err = strconv.Atoi(arg); err != nil {
+			return queryError("bad coordinate %q", arg)
+		}
+	}
+
+	from := Position{X: coords[0], Y: coords[1]}
+	to := Position{X: coords[2], Y: coords[3]}
+
+	path, ok := FindPath(p.partialMap, from, to)
+	if !ok {
+		return noResult
+	}
+
+	return formatPath(path)
+}
+
+// queryNearest answers `?nearest C ID` with `X Y N`, the position of the
+// nearest cell with the content `C` from the ant `ID` and the number of steps
+// to get there. `C` can be a content name (e.g. "sugar"), "food" or "enemy".
+func (p *Player) queryNearest(args []string) string {
+	if len(args) != 2 {
+		return queryError("usage: ?nearest C ID")
+	}
+
+	id, err := strconv.Atoi(args[1])
+	if err != nil {
+		return queryError("bad ant ID %q", args[1])
+	}
+
+	var ant *AntStatus
+	for i := range p.turn.AntsStatuses {
+		if p.turn.AntsStatuses[i].ID == id {
+			ant = &p.turn.AntsStatuses[i]
+			break
+		}
+	}
+
+	if ant == nil {
+		return queryError("unknown ant %d", id)
+	}
+
+	var goal func(Position) bool
+
+	switch args[0] {
+	case "enemy":
+		enemies := make(map[Position]bool)
+		for _, e := range p.enemies.Ants() {
+			if e.Visible {
+				enemies[e.Pos] = true
+			}
+		}
+		goal = func(pos Position) bool { return enemies[pos] }
+	case "food":
+		goal = func(pos Position) bool {
+			c := p.partialMap.Cell(pos.X, pos.Y)
+			return c != nil && IsFood(c.Content)
+		}
+	default:
+		if _, ok := contents[args[0]]; !ok {
+			return queryError("unknown content %q", args[0])
+		}
+		goal = func(pos Position) bool {
+			c := p.partialMap.Cell(pos.X, pos.Y)
+			return c != nil && c.Content == args[0]
+		}
+	}
+
+	path, ok := search(p.partialMap, ant.Pos, goal)
+	if !ok {
+		return noResult
+	}
+
+	target := ant.Pos
+	if len(path) > 0 {
+		target = path[len(path)-1]
+	}
+
+	return fmt.Sprintf("%d %d %d", target.X, target.Y, len(path))
+}
+
+// formatPath formats a path as `N X Y X Y ...`
+func formatPath(path []Position) string {
+	parts := []string{strconv.Itoa(len(path))}
+
+	for _, pos := range path {
+		parts = append(parts, strconv.Itoa(pos.X), strconv.Itoa(pos.Y))
+	}
+
+	return strings.Join(parts, " ")
+}
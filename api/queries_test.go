@@ -0,0 +1,178 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"github.com/franela/goblin"
+	o "github.com/onsi/gomega"
+	"strings"
+	"testing"
+)
+
+// fakeQueryHandler answers each query with it in upper case
+type fakeQueryHandler struct{}
+
+func (fakeQueryHandler) Query(q string) string { return strings.ToUpper(q) }
+
+// lineMap returns a map with one row of cells, from (0, 0) to (n-1, 0)
+func lineMap(contents ...string) *PartialMap {
+	m := NewPartialMap()
+
+	for x, content := range contents {
+		m.SetCell(&Cell{Pos: Position{X: x, Y: 0}, Content: content})
+	}
+
+	return m
+}
+
+func TestQueries(t *testing.T) {
+
+	g := goblin.Goblin(t)
+
+	o.RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	g.Describe("readCommand", func() {
+		g.It("Should return the first line if it's not a query", func() {
+			var w bytes.Buffer
+			r := bufio.NewReader(strings.NewReader("0:rest\n"))
+
+			line, err := readCommand(r, &w, fakeQueryHandler{}, nil)
+			o.Expect(err).To(o.BeNil())
+			o.Expect(line).To(o.Equal("0:rest\n"))
+			o.Expect(w.Len()).To(o.Equal(0))
+		})
+
+		g.It("Should answer queries until it gets a command", func() {
+			var w bytes.Buffer
+			r := bufio.NewReader(strings.NewReader("?foo 1\n?bar\n0:rest\n"))
+
+			line, err := readCommand(r, &w, fakeQueryHandler{}, nil)
+			o.Expect(err).To(o.BeNil())
+			o.Expect(line).To(o.Equal("0:rest\n"))
+			o.Expect(w.String()).To(o.Equal("FOO 1\nBAR\n"))
+		})
+
+		g.It("Should answer with an error if there's no handler", func() {
+			var w bytes.Buffer
+			r := bufio.NewReader(strings.NewReader("?foo\n0:rest\n"))
+
+			line, _ := readCommand(r, &w, nil, nil)
+			o.Expect(line).To(o.Equal("0:rest\n"))
+			o.Expect(w.String()).To(o.HavePrefix("error: "))
+		})
+	})
+
+	g.Describe("Walkable", func() {
+		g.It("Should return false for unknown cells", func() {
+			o.Expect(Walkable(nil)).To(o.BeFalse())
+		})
+
+		g.It("Should return false for rocks and water", func() {
+			o.Expect(Walkable(&Cell{Content: "rock"})).To(o.BeFalse())
+			o.Expect(Walkable(&Cell{Content: "water"})).To(o.BeFalse())
+		})
+
+		g.It("Should return true for grass and food", func() {
+			o.Expect(Walkable(&Cell{Content: "grass"})).To(o.BeTrue())
+			o.Expect(Walkable(&Cell{Content: "sugar"})).To(o.BeTrue())
+		})
+	})
+
+	g.Describe("FindPath", func() {
+		g.It("Should return an empty path if we're already there", func() {
+			path, ok := FindPath(lineMap("grass"), Position{}, Position{})
+			o.Expect(ok).To(o.BeTrue())
+			o.Expect(path).To(o.BeEmpty())
+		})
+
+		g.It("Should return the path without the starting position", func() {
+			path, ok := FindPath(lineMap("grass", "grass", "grass"),
+				Position{X: 0, Y: 0}, Position{X: 2, Y: 0})
+
+			o.Expect(ok).To(o.BeTrue())
+			o.Expect(path).To(o.Equal([]Position{{X: 1, Y: 0}, {X: 2, Y: 0}}))
+		})
+
+		g.It("Should go around obstacles using diagonals", func() {
+			m := lineMap("grass", "rock", "grass")
+			m.SetCell(&Cell{Pos: Position{X: 1, Y: 1}, Content: "grass"})
+
+			path, ok := FindPath(m, Position{X: 0, Y: 0}, Position{X: 2, Y: 0})
+
+			o.Expect(ok).To(o.BeTrue())
+			o.Expect(path).To(o.Equal([]Position{{X: 1, Y: 1}, {X: 2, Y: 0}}))
+		})
+
+		g.It("Should return false if there's no known path", func() {
+			_, ok := FindPath(lineMap("grass", "water", "grass"),
+				Position{X: 0, Y: 0}, Position{X: 2, Y: 0})
+
+			o.Expect(ok).To(o.BeFalse())
+		})
+	})
+
+	g.Describe("Player.Query", func() {
+		var p *Player
+
+		g.BeforeEach(func() {
+			p = newTestPlayer()
+			p.partialMap = lineMap("grass", "grass", "grass", "grass", "sugar", "rock")
+			p.turn.AntsStatuses[0].Pos = Position{X: 1, Y: 0}
+		})
+
+		g.It("Should return an error for empty queries", func() {
+			o.Expect(p.Query("")).To(o.HavePrefix("error: "))
+		})
+
+		g.It("Should return an error for unknown queries", func() {
+			o.Expect(p.Query("foo 1 2")).To(o.HavePrefix("error: "))
+		})
+
+		g.It("Should answer path queries", func() {
+			o.Expect(p.Query("path 0 0 2 0")).To(o.Equal("2 1 0 2 0"))
+			o.Expect(p.Query("path 0 0 0 0")).To(o.Equal("0"))
+		})
+
+		g.It("Should answer -1 if there's no path", func() {
+			o.Expect(p.Query("path 0 0 6 0")).To(o.Equal("-1"))
+		})
+
+		g.It("Should return an error for malformed path queries", func() {
+			o.Expect(p.Query("path 0 0 2")).To(o.HavePrefix("error: "))
+			o.Expect(p.Query("path 0 0 2 a")).To(o.HavePrefix("error: "))
+		})
+
+		g.It("Should answer nearest queries", func() {
+			o.Expect(p.Query("nearest food 0")).To(o.Equal("4 0 3"))
+			o.Expect(p.Query("nearest sugar 0")).To(o.Equal("4 0 3"))
+			o.Expect(p.Query("nearest rock 0")).To(o.Equal("5 0 4"))
+			o.Expect(p.Query("nearest water 0")).To(o.Equal("-1"))
+		})
+
+		g.It("Should answer nearest queries for enemies", func() {
+			o.Expect(p.Query("nearest enemy 0")).To(o.Equal("-1"))
+
+			p.enemies.Update(3, []BasicAntStatus{{Pos: Position{X: 3, Y: 0}}})
+			o.Expect(p.Query("nearest enemy 0")).To(o.Equal("3 0 2"))
+		})
+
+		g.It("Should return an error for malformed nearest queries", func() {
+			o.Expect(p.Query("nearest food")).To(o.HavePrefix("error: "))
+			o.Expect(p.Query("nearest food 42")).To(o.HavePrefix("error: "))
+			o.Expect(p.Query("nearest lava 0")).To(o.HavePrefix("error: "))
+		})
+	})
+
+	g.Describe("AIPool", func() {
+		g.It("Should let AIs send queries before their command", func() {
+			pool := NewAIPool()
+			pool.SetQueryHandler(fakeQueryHandler{})
+			pool.AddAI("sh", "-c", `read msg; echo "?path $msg"; read ans; echo "0:$ans"`)
+
+			pool.Start()
+			pool.SendAll("1 2\n")
+			o.Expect(pool.GetCommandResponse()).To(o.Equal(Commands("0:PATH 1 2")))
+			pool.Stop()
+		})
+	})
+}
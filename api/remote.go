@@ -0,0 +1,186 @@
+package api
+
+// This file describes remote AIs. They're like the AIs in `api/ai.go` except
+// that instead of starting a command we connect to an endpoint, which can be a
+// TCP address (`tcp://host:port`), a Unix socket (`unix:///path`) or a
+// WebSocket (`ws://host/path` or `wss://...`). The protocol is exactly the
+// same as the one we use with local AIs (see `docs/ai_protocol.md`): we write
+// the messages on the connection and read one line back.
+//
+// The game server can also listen on an endpoint and wait for AIs to connect
+// to it, see `AIPool.Accept`.
+
+import (
+	"bufio"
+	"fmt"
+	"golang.org/x/net/websocket"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+// A RemoteAI is an AI we talk to over a connection. The *RemoteAI type
+// implements ActorInterface.
+type RemoteAI struct {
+	// the connection's name, used in the logs
+	name string
+	conn io.ReadWriteCloser
+
+	// answers the AI's queries, see `api/queries.go`
+	queries QueryHandler
+	// collects the AI's annotations and facts, see `api/annotations.go` and
+	// `api/blackboard.go`
+	annotate func(string)
+
+	input  chan string
+	output chan string
+}
+
+// NewRemoteAI returns a pointer on a new RemoteAI which uses an already open
+// connection. `name` is only used in the logs.
+func NewRemoteAI(name string, conn io.ReadWriteCloser) *RemoteAI {
+	return &RemoteAI{
+		name: name,
+		conn: conn,
+
+		input:  make(chan string),
+		output: make(chan string),
+	}
+}
+
+// DialAI connects to an endpoint and returns a pointer on a new RemoteAI
+func DialAI(endpoint string) (*RemoteAI, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var conn io.ReadWriteCloser
+
+	switch u.Scheme {
+	case "tcp":
+		conn, err = net.Dial("tcp", u.Host)
+	case "unix":
+		conn, err = net.Dial("unix", u.Path)
+	case "ws", "wss":
+		// the origin is mandatory but the servers we talk to don't care
+		// about it
+		origin := "http://localhost/"
+		if u.Scheme == "wss" {
+			origin = "https://localhost/"
+		}
+		conn, err = websocket.Dial(endpoint, "", origin)
+	default:
+		return nil, ErrUnknownEndpoint
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRemoteAI(endpoint, conn), nil
+}
+
+// IsRemoteEndpoint returns true if the string looks like an endpoint we can
+// connect to rather than a command.
+func IsRemoteEndpoint(s string) bool {
+	for _, scheme := range []string{"tcp", "unix", "ws", "wss"} {
+		if strings.HasPrefix(s, scheme+"://") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Listen starts listening on a `tcp://` or `unix://` endpoint
+func Listen(endpoint string) (net.Listener, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "tcp":
+		return net.Listen("tcp", u.Host)
+	case "unix":
+		return net.Listen("unix", u.Path)
+	}
+
+	return nil, ErrUnknownEndpoint
+}
+
+// String returns the AI's endpoint or address
+func (ai *RemoteAI) String() string { return ai.name }
+
+// SetQueryHandler sets the handler used to answer this AI's queries. This
+// should be called before `Start(wg)`.
+func (ai *RemoteAI) SetQueryHandler(h QueryHandler) { ai.queries = h }
+
+// SetAnnotationHandler sets the function called with each annotation or fact
+// line this AI sends. This should be called before `Start(wg)`.
+func (ai *RemoteAI) SetAnnotationHandler(f func(string)) { ai.annotate = f }
+
+// Start the remote AI. Like `Actor.Start` this starts a goroutine and uses the
+// WaitGroup to tell when it ends.
+func (ai *RemoteAI) Start(wg *sync.WaitGroup) {
+	go ai.start(wg)
+}
+
+// Send a message to this AI (blocking)
+func (ai *RemoteAI) Send(m string) { ai.input <- m }
+
+// Read a message from this AI (blocking)
+func (ai *RemoteAI) Read() string { return <-ai.output }
+
+// errLog takes an error and prints it on stderr along with the AI's name
+func (ai *RemoteAI) errLog(err error) {
+	fmt.Fprintf(os.Stderr, "%s: %s\n", ai.name, err)
+}
+
+// This is the main loop of a remote AI, see `Actor.start`. The difference is
+// that a remote AI can go away in the middle of a game: we then log the error
+// and keep answering with empty lines, i.e. no commands, so the game can go
+// on without it.
+func (ai *RemoteAI) start(wg *sync.WaitGroup) {
+	if wg != nil {
+		defer wg.Done()
+	}
+
+	reader := bufio.NewReader(ai.conn)
+	connected := true
+
+	for {
+		msg := <-ai.input
+
+		if msg == stop {
+			break
+		}
+
+		line := ""
+
+		if connected {
+			var err error
+
+			if _, err = io.WriteString(ai.conn, msg); err == nil {
+				line, err = readCommand(reader, ai.conn, ai.queries, ai.annotate)
+			}
+
+			if err != nil {
+				ai.errLog(err)
+				connected = false
+				line = ""
+			}
+		}
+
+		ai.output <- line
+	}
+
+	ai.conn.Close()
+
+	close(ai.input)
+	close(ai.output)
+}
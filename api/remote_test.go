@@ -0,0 +1,226 @@
+package api
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/franela/goblin"
+	o "github.com/onsi/gomega"
+	"golang.org/x/net/websocket"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeRemoteAI answers "<prefix><first word of the message>" to each message
+func fakeRemoteAI(conn io.ReadWriteCloser, prefix string) {
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		io.WriteString(conn, prefix+strings.Fields(line)[0]+"\n")
+	}
+}
+
+// serveFakeAI accepts connections on the listener and answers like
+// fakeRemoteAI
+func serveFakeAI(l net.Listener, prefix string) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		go fakeRemoteAI(conn, prefix)
+	}
+}
+
+func TestRemoteAI(t *testing.T) {
+
+	g := goblin.Goblin(t)
+
+	o.RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	g.Describe("IsRemoteEndpoint", func() {
+		g.It("Should return true for endpoints", func() {
+			o.Expect(IsRemoteEndpoint("tcp://localhost:9000")).To(o.BeTrue())
+			o.Expect(IsRemoteEndpoint("unix:///tmp/ai.sock")).To(o.BeTrue())
+			o.Expect(IsRemoteEndpoint("ws://localhost/ai")).To(o.BeTrue())
+			o.Expect(IsRemoteEndpoint("wss://localhost/ai")).To(o.BeTrue())
+		})
+
+		g.It("Should return false for commands", func() {
+			o.Expect(IsRemoteEndpoint("ai/ant.rb")).To(o.BeFalse())
+			o.Expect(IsRemoteEndpoint("http://localhost")).To(o.BeFalse())
+		})
+	})
+
+	g.Describe("DialAI", func() {
+		g.It("Should return ErrUnknownEndpoint for unknown schemes", func() {
+			_, err := DialAI("http://localhost/")
+			o.Expect(err).To(o.Equal(ErrUnknownEndpoint))
+		})
+
+		g.It("Should talk to an AI over TCP", func() {
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			o.Expect(err).To(o.BeNil())
+			defer l.Close()
+
+			go serveFakeAI(l, "tcp:")
+
+			pool := NewAIPool()
+			o.Expect(pool.AddRemoteAI("tcp://" + l.Addr().String())).To(o.BeNil())
+
+			pool.Start()
+			pool.SendAll("1 2 3\n")
+			o.Expect(pool.ReadAll()).To(o.Equal([]string{"tcp:1"}))
+			pool.Stop()
+		})
+
+		g.It("Should talk to an AI over a Unix socket", func() {
+			dir, _ := ioutil.TempDir("", "antroid")
+			defer os.RemoveAll(dir)
+
+			path := filepath.Join(dir, "ai.sock")
+
+			l, err := net.Listen("unix", path)
+			o.Expect(err).To(o.BeNil())
+			defer l.Close()
+
+			go serveFakeAI(l, "unix:")
+
+			ai, err := DialAI("unix://" + path)
+			o.Expect(err).To(o.BeNil())
+
+			pool := NewAIPool()
+			pool.AddActor(ai)
+			pool.Start()
+			pool.SendAll("4 5 6\n")
+			o.Expect(pool.ReadAll()).To(o.Equal([]string{"unix:4"}))
+			pool.Stop()
+		})
+
+		g.It("Should talk to an AI over a WebSocket", func() {
+			srv := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+				fakeRemoteAI(ws, "ws:")
+			}))
+			defer srv.Close()
+
+			ai, err := DialAI("ws" + strings.TrimPrefix(srv.URL, "http"))
+			o.Expect(err).To(o.BeNil())
+
+			pool := NewAIPool()
+			pool.AddActor(ai)
+			pool.Start()
+			pool.SendAll("7 8 9\n")
+			o.Expect(pool.ReadAll()).To(o.Equal([]string{"ws:7"}))
+			pool.Stop()
+		})
+	})
+
+	g.Describe("RemoteAI", func() {
+		g.It("Should answer with empty lines once the connection is lost", func() {
+			ours, theirs := net.Pipe()
+			theirs.Close()
+
+			ai := NewRemoteAI("pipe", ours)
+			ai.Start(nil)
+
+			ai.Send("1 2 3\n")
+			o.Expect(ai.Read()).To(o.Equal(""))
+			ai.Send("4 5 6\n")
+			o.Expect(ai.Read()).To(o.Equal(""))
+			ai.Send(stop)
+		})
+	})
+
+	g.Describe("AIPool", func() {
+		g.It("Should wait for AIs to connect", func() {
+			l, err := net.Listen("tcp", "127.0.0.1:0")
+			o.Expect(err).To(o.BeNil())
+			defer l.Close()
+
+			for i := 0; i < 2; i++ {
+				go func(i int) {
+					conn, err := net.Dial("tcp", l.Addr().String())
+					if err == nil {
+						fakeRemoteAI(conn, fmt.Sprintf("%d:", i))
+					}
+				}(i)
+			}
+
+			pool := NewAIPool()
+			count := 0
+
+			err = pool.Accept(l, 2, func(*RemoteAI) { count++ })
+			o.Expect(err).To(o.BeNil())
+			o.Expect(count).To(o.Equal(2))
+
+			pool.Start()
+			pool.SendAll("3 1 1\n")
+			// they connect in any order
+			o.Expect(pool.GetCommandResponse()).To(o.SatisfyAny(
+				o.Equal(Commands("0:3,1:3")), o.Equal(Commands("1:3,0:3"))))
+			pool.Stop()
+		})
+
+		g.It("Should keep the first command given to each ant", func() {
+			pool := NewAIPool()
+			pool.AddAI("sh", "-c", `read msg; echo "1:left"`)
+			pool.AddAI("sh", "-c", `read msg; echo "0:rest,1:forward,2:right"`)
+
+			pool.Start()
+			pool.SendAll("1 2\n")
+			o.Expect(pool.GetCommandResponse()).To(o.Equal(Commands("1:left,0:rest,2:right")))
+			pool.Stop()
+		})
+
+		g.It("Should go on without crashed AIs", func() {
+			pool := NewAIPool()
+			pool.AddAI("sh", "-c", `read msg; echo "0:rest"; read msg; echo "0:left"`)
+			pool.AddAI("sh", "-c", `read msg; exit 1`)
+			pool.AddAI("/does/not/exist")
+
+			pool.Start()
+			pool.SendAll("1 2\n")
+			o.Expect(pool.GetCommandResponse()).To(o.Equal(Commands("0:rest")))
+			pool.SendAll("2 2\n")
+			o.Expect(pool.GetCommandResponse()).To(o.Equal(Commands("0:left")))
+			pool.Stop()
+		})
+
+		g.It("Should ignore AIs which don't send any command", func() {
+			ours, theirs := net.Pipe()
+			theirs.Close()
+
+			pool := NewAIPool()
+			pool.AddActor(NewRemoteAI("pipe", ours))
+			pool.Start()
+			pool.SendAll("1 2 3\n")
+			o.Expect(pool.GetCommandResponse()).To(o.Equal(Commands("")))
+			pool.Stop()
+		})
+	})
+
+	g.Describe("Listen", func() {
+		g.It("Should return ErrUnknownEndpoint for unknown schemes", func() {
+			_, err := Listen("ws://localhost:9000")
+			o.Expect(err).To(o.Equal(ErrUnknownEndpoint))
+		})
+
+		g.It("Should listen on a TCP endpoint", func() {
+			l, err := Listen("tcp://127.0.0.1:0")
+			o.Expect(err).To(o.BeNil())
+			l.Close()
+		})
+	})
+}
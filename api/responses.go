@@ -19,9 +19,10 @@ type errorResponse struct {
 	Message string `json:"error_msg"`
 }
 
-// Return a Go error from the code of an error
-func (e errorResponse) Error() error {
-	return errorForCode(e.Code)
+// Return a Go error from the code of an error, using an error table for the
+// codes we don't know
+func (e errorResponse) Error(t ErrorTable) error {
+	return t.errorFor(e.Code, e.Message)
 }
 
 // Body is a body response from the API
@@ -93,6 +94,11 @@ type gameStatusResponse struct {
 	Score          map[string]int
 	Status         struct{ Status string }
 	Turn           int
+
+	// the server may not send these ones, in which case they're 0
+	NbTurn          int `json:"nb_turn"`
+	NbPlayer        int `json:"nb_player"`
+	MinimalNbPlayer int `json:"minimal_nb_player"`
 }
 
 // a playResponse is a partially parsed result from an API call to /play
@@ -101,6 +107,17 @@ type playResponse struct {
 	Observations [][]json.RawMessage
 }
 
+// a logResponse is a partially parsed result from an API call to /log. It
+// has each turn of the game with the scores and the observations of each
+// player, in the same format as the ones of /play.
+type logResponse struct {
+	Log []struct {
+		Turn         int
+		Score        map[string]int
+		Observations map[string][][]json.RawMessage
+	}
+}
+
 // visibleAntResponse is a part of a response which describes a visible ant
 type visibleAntResponse struct {
 	X, Y, Dx, Dy int
@@ -167,10 +184,10 @@ func (p playResponse) getTurn() (t *Turn, err error) {
 			}
 
 			// add a cell at its position
-			pmap.Cells[p] = &Cell{
+			pmap.SetCell(&Cell{
 				Pos:     p,
 				Content: content,
-			}
+			})
 		}
 
 		// populate the visible ants list
@@ -205,3 +222,30 @@ func (p playResponse) getTurn() (t *Turn, err error) {
 
 	return
 }
+
+// getLog returns the log of a game from a /log response
+func (l logResponse) getLog(id GameID) (gl GameLog, err error) {
+	gl.Game = id
+
+	for _, lt := range l.Log {
+		turn := LoggedTurn{
+			Number:  lt.Turn,
+			Score:   lt.Score,
+			Players: make(map[string]*Turn),
+		}
+
+		for player, obs := range lt.Observations {
+			var t *Turn
+
+			if t, err = (playResponse{Turn: lt.Turn, Observations: obs}).getTurn(); err != nil {
+				return
+			}
+
+			turn.Players[player] = t
+		}
+
+		gl.Turns = append(gl.Turns, turn)
+	}
+
+	return
+}
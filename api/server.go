@@ -4,8 +4,11 @@ package api
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"sync"
 )
 
 // A Player represents a local game server connected to the remote one and
@@ -44,14 +47,32 @@ type Player struct {
 	turns int
 	// The map as we know it. This is updated at each turn
 	partialMap *PartialMap
-
+	// The protocol lines describing each cell of the map. We only re-format
+	// the cells that changed during the last turn.
+	cellLines map[Position]string
+	// The enemy ants we saw. This is updated at each turn
+	enemies *EnemyTracker
+	// The facts our AIs share. This is updated at the end of each turn
+	blackboard *Blackboard
+	// The journal in which we record each turn, if any
+	journal *JournalWriter
+	// AIs send their queries concurrently, see `api/queries.go`
+	queryLock sync.Mutex
+	// The message we sent to the AIs at this turn, and the cells that
+	// changed. We send them to the listeners once the AIs played, along with
+	// their annotations.
+	message string
+	dirty   []*Cell
+
+	// This will be true once the AIs and Listeners are started
+	started bool
 	// This will be true when the game will end
 	done bool
 }
 
 // NewPlayer returns a pointer on a new Player
 func NewPlayer(username, password string) (p *Player) {
-	return &Player{
+	p = &Player{
 		Client:     NewClient(),
 		AIs:        NewAIPool(),
 		Listeners:  NewListenersPool(),
@@ -60,7 +81,14 @@ func NewPlayer(username, password string) (p *Player) {
 		status:     &GameStatus{},
 		turn:       &EmptyTurn,
 		partialMap: NewPartialMap(),
+		cellLines:  make(map[Position]string),
+		enemies:    NewEnemyTracker(),
+		blackboard: NewBlackboard(),
 	}
+
+	p.AIs.SetQueryHandler(p)
+
+	return
 }
 
 // SetDebug enables/disables the debug mode
@@ -69,11 +97,22 @@ func (p *Player) SetDebug(debug bool) {
 	p.debug = debug
 }
 
+// SetJournal makes the player record each turn in a journal written on the
+// given writer. See `api/journal.go`.
+func (p *Player) SetJournal(w io.Writer) {
+	p.journal = NewJournalWriter(w)
+}
+
 // Done returns true if the game ended
 func (p *Player) Done() bool {
 	return p.done
 }
 
+// GameID returns the identifier of the game the player joined
+func (p *Player) GameID() GameID {
+	return p.status.Identifier
+}
+
 // Connect connects the player to the remote server, first trying to register
 // its credentials.
 func (p *Player) Connect() (err error) {
@@ -103,10 +142,20 @@ func (p *Player) CreateAndJoinGame(gs *GameSpec) (err error) {
 	return
 }
 
-// JoinGame joins an existing game
+// JoinGame joins an existing game. It's fine if we already joined it, e.g.
+// with `antroid join`.
 func (p *Player) JoinGame(id GameID) (err error) {
+	if err = p.join(id); err != nil {
+		return
+	}
 
-	if err = p.Client.JoinGameIdentifier(id); err != nil {
+	return p.start()
+}
+
+// join joins an existing game and gets its status, without playing
+func (p *Player) join(id GameID) (err error) {
+	err = p.Client.JoinGameIdentifier(id)
+	if err != nil && !errors.Is(err, ErrAlreadyJoined) {
 		return
 	}
 
@@ -115,6 +164,16 @@ func (p *Player) JoinGame(id GameID) (err error) {
 		return
 	}
 
+	// remember the number of turns if the server told us
+	if p.status.Spec != nil && p.status.Spec.Turns > 0 {
+		p.turns = p.status.Spec.Turns
+	}
+
+	return
+}
+
+// start plays the first turn of a game we joined and starts the plugins
+func (p *Player) start() (err error) {
 	firstAnt := true
 	var restCmd bytes.Buffer
 
@@ -152,7 +211,7 @@ func (p *Player) JoinGame(id GameID) (err error) {
 // PlayTurn sends the game status to all AIs and gets their feedback before
 // sending everything to the remote server
 func (p *Player) PlayTurn() (done bool, err error) {
-	p.sendTurnStatusToPlugins()
+	p.sendTurnStatusToAIs()
 	err = p.playTurn()
 	done = p.done
 
@@ -164,14 +223,51 @@ func (p *Player) PlayTurn() (done bool, err error) {
 	return
 }
 
-// Quit stops all AIs and Listeners and logout the player from the remote
-// server
+// Quit stops all AIs and Listeners if they were started and logout the
+// player from the remote server
 func (p *Player) Quit() error {
-	p.AIs.Stop()
-	p.Listeners.Stop()
+	if p.started {
+		p.AIs.Stop()
+		p.Listeners.Stop()
+		p.started = false
+	}
 	return p.Client.Logout()
 }
 
+// Blackboard returns the facts shared by our AIs
+func (p *Player) Blackboard() *Blackboard {
+	return p.blackboard
+}
+
+// Enemies returns all the enemy ants we know, including the ones we lost
+// sight of.
+func (p *Player) Enemies() []*TrackedAnt {
+	return p.enemies.Ants()
+}
+
+// opponents returns the other players in the game
+func (p *Player) opponents() (players []string) {
+	for _, username := range p.status.Players {
+		if username != p.username {
+			players = append(players, username)
+		}
+	}
+
+	return
+}
+
+// internal helper to get the index of an ant's owner in the players list, or
+// -1 if we don't know it.
+func (p *Player) ownerNumber(a *TrackedAnt) int {
+	for i, username := range p.status.Players {
+		if username == a.Owner {
+			return i
+		}
+	}
+
+	return -1
+}
+
 // PrintScores prints the current scores
 func (p *Player) PrintScores() {
 	var usernameMaxSize int
@@ -206,6 +302,7 @@ func (p *Player) updateStatus() (err error) {
 func (p *Player) startPlugins() {
 	p.AIs.Start()
 	p.Listeners.Start()
+	p.started = true
 }
 
 // internal helper to get a number for an ant's brain state
@@ -248,8 +345,9 @@ func contentNumber(c Cell) (v int) {
 }
 
 // constructs a message describing the current turn (see `docs/ai_protocol.md`)
-// and send it to all plugins and AIs.
-func (p *Player) sendTurnStatusToPlugins() {
+// and send it to all AIs. It's sent to the listeners later, once the AIs
+// played, by `.sendTurnStatusToListeners()`.
+func (p *Player) sendTurnStatusToAIs() {
 	var buf bytes.Buffer
 
 	playing := 1
@@ -268,6 +366,9 @@ func (p *Player) sendTurnStatusToPlugins() {
 
 	var visibleAnts, enemyAnts []BasicAntStatus
 
+	// what we saw on the previous turn is not visible anymore
+	p.partialMap.ResetVisibility()
+
 	// all our ants
 	for _, ant := range p.turn.AntsStatuses {
 		// save visible ants
@@ -277,7 +378,6 @@ func (p *Player) sendTurnStatusToPlugins() {
 
 		// update the current map
 		ant.Vision.SetVisibility(true)
-		p.partialMap.ResetVisibility()
 		p.partialMap.Combine(*ant.Vision)
 
 		buf.WriteString(fmt.Sprintf("%d %d %d %d %d %d %d %d\n",
@@ -303,17 +403,23 @@ Visible:
 		enemyAnts = append(enemyAnts, visible)
 	}
 
+	// link them with the ones we saw on the previous turns
+	p.enemies.SetOpponents(p.opponents())
+	trackedAnts := p.enemies.Update(p.turn.Number, enemyAnts)
+
 	// N enemy ants
-	buf.WriteString(fmt.Sprintf("%d\n", len(enemyAnts)))
+	buf.WriteString(fmt.Sprintf("%d\n", len(trackedAnts)))
 
 	// enemy ants
-	for _, ant := range enemyAnts {
-		buf.WriteString(fmt.Sprintf("%d %d %d %d %d\n",
-			ant.Pos.X,        // X
-			ant.Pos.Y,        // Y
-			ant.Dir.X,        // DX
-			ant.Dir.Y,        // DY
-			brainNumber(ant), // B
+	for _, ant := range trackedAnts {
+		buf.WriteString(fmt.Sprintf("%d %d %d %d %d %d %d\n",
+			ant.Pos.X,                       // X
+			ant.Pos.Y,                       // Y
+			ant.Dir.X,                       // DX
+			ant.Dir.Y,                       // DY
+			brainNumber(ant.BasicAntStatus), // B
+			ant.ID,                          // I
+			p.ownerNumber(ant),              // O
 		))
 	}
 
@@ -324,38 +430,105 @@ Visible:
 		len(p.partialMap.Cells), // N
 	))
 
+	p.dirty = p.partialMap.DirtyCells()
+	p.partialMap.ClearDirty()
+
 	// map cells
-	for _, cell := range p.partialMap.Cells {
-		buf.WriteString(fmt.Sprintf("%d %d %d %d\n",
-			cell.Pos.X,              // X
-			cell.Pos.Y,              // Y
-			contentNumber(*cell),    // C
-			visibilityNumber(*cell), // S
-		))
+	p.updateCellLines(p.dirty)
+	for _, line := range p.cellLines {
+		buf.WriteString(line)
 	}
 
-	msg := buf.String()
+	// B facts on the blackboard
+	buf.WriteString(fmt.Sprintf("%d\n", p.blackboard.Len()))
+
+	for _, f := range p.blackboard.Facts() {
+		buf.WriteString(f.protocolLine())
+		buf.WriteString("\n")
+	}
+
+	p.message = buf.String()
 
 	if p.debug {
 		// print the message if we're debugging
-		fmt.Fprintf(os.Stderr, "%s\n", msg)
+		fmt.Fprintf(os.Stderr, "%s\n", p.message)
 	}
 
 	// send it
-	p.AIs.SendAll(msg)
-	p.Listeners.SendAll(msg)
+	p.AIs.SendAll(p.message)
 }
 
-// playTurn gets the command to use from all AIs, send it to the server and
+// sends the current turn to the listeners and records it in the journal,
+// along with the annotations the AIs sent while playing it. Listeners get the
+// same message as the AIs followed by the annotations (see
+// `docs/ai_protocol.md`).
+func (p *Player) sendTurnStatusToListeners(annotations []Annotation) {
+	var entry *JournalEntry
+
+	if p.journal != nil || p.Listeners.HasObservers() {
+		entry = p.newJournalEntry(p.dirty)
+		entry.Annotations = annotations
+	}
+
+	if p.journal != nil {
+		if err := p.journal.Write(entry); err != nil {
+			fmt.Fprintf(os.Stderr, "Can't write the journal: %s\n", err)
+		}
+	}
+
+	var buf bytes.Buffer
+
+	buf.WriteString(p.message)
+
+	// K annotations
+	buf.WriteString(fmt.Sprintf("%d\n", len(annotations)))
+
+	for _, a := range annotations {
+		buf.WriteString(a.protocolLine())
+		buf.WriteString("\n")
+	}
+
+	p.Listeners.SendAll(buf.String())
+
+	if entry != nil {
+		p.Listeners.ObserveAll(entry, p.partialMap)
+	}
+}
+
+// updateCellLines re-formats the protocol lines of all the given cells, which
+// are the ones that changed since the last turn.
+func (p *Player) updateCellLines(dirty []*Cell) {
+	for _, cell := range dirty {
+		p.cellLines[cell.Pos] = fmt.Sprintf("%d %d %d %d\n",
+			cell.Pos.X,              // X
+			cell.Pos.Y,              // Y
+			contentNumber(*cell),    // C
+			visibilityNumber(*cell), // S
+		)
+	}
+}
+
+// playTurn gets the command to use from all AIs, send the turn to the
+// listeners, updates the blackboard, send the command to the server and
 // updates the local game status.
-func (p *Player) playTurn() (err error) {
-	cmd := p.AIs.GetCommandResponse()
+func (p *Player) playTurn() error {
+	return p.play(p.AIs.GetCommandResponse())
+}
 
-	p.turn, err = p.Client.PlayIdentifier(p.status.Identifier, cmd)
+// play sends the turn to the listeners, updates the blackboard, send the
+// given command to the server and updates the local game status. The turn is
+// left untouched if the server returns an error.
+func (p *Player) play(cmd Commands) (err error) {
+	p.sendTurnStatusToListeners(p.AIs.Annotations())
+	p.blackboard.Merge(p.AIs.Facts())
+
+	turn, err := p.Client.PlayIdentifier(p.status.Identifier, cmd)
 	if err != nil {
 		return
 	}
 
+	p.turn = turn
+
 	err = p.updateStatus()
 	return
 }
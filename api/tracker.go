@@ -0,0 +1,219 @@
+package api
+
+// This file describes an enemy tracker. The remote server only gives us the
+// position, direction and brain state of the enemy ants we see, without any
+// identifier. The tracker links these sightings across turns to give each
+// enemy ant a stable local ID and remember where we last saw it.
+//
+// Ants move at most one cell per turn, so an ant we saw `n` turns ago can't be
+// further than `n` cells from its last known position. Among all the possible
+// links between tracked ants and new sightings, we pick the ones that best
+// continue the ants' trajectories, i.e. with the smallest distance between the
+// position we expected and the one we see.
+
+import "sort"
+
+// defaultTrackerMaxAge is the default number of turns we remember an enemy
+// after we lost sight of it
+const defaultTrackerMaxAge = 20
+
+// A TrackedAnt is an enemy ant we saw at least once
+type TrackedAnt struct {
+	// its last known position, direction and brain state
+	BasicAntStatus
+
+	// our local ID for this ant. It's not related to the ID the remote server
+	// uses for its owner.
+	ID int
+	// its estimated owner, or an empty string if we don't know it
+	Owner string
+	// the last turn we saw it
+	LastSeen int
+	// true if we saw it on the last turn
+	Visible bool
+}
+
+// An EnemyTracker follows enemy ants across turns
+type EnemyTracker struct {
+	// the number of turns we remember an enemy after we lost sight of it
+	MaxAge int
+
+	// all the ants we know, ordered by ID
+	ants []*TrackedAnt
+	// the ID we'll give to the next new ant
+	nextID int
+	// the other players in the game
+	opponents []string
+}
+
+// NewEnemyTracker returns a pointer on a new, empty, EnemyTracker
+func NewEnemyTracker() *EnemyTracker {
+	return &EnemyTracker{MaxAge: defaultTrackerMaxAge}
+}
+
+// SetOpponents sets the list of the other players in the game. It's used to
+// estimate the owner of new ants.
+func (t *EnemyTracker) SetOpponents(players []string) {
+	t.opponents = players
+}
+
+// guessOwner returns the owner of a new ant if we can guess it. The only case
+// we can be sure of is when there's only one other player.
+func (t *EnemyTracker) guessOwner() string {
+	if len(t.opponents) == 1 {
+		return t.opponents[0]
+	}
+
+	return ""
+}
+
+// a trackingLink is a possible link between a tracked ant and a sighting
+type trackingLink struct {
+	ant      *TrackedAnt
+	sighting int
+	cost     int
+}
+
+// abs returns the absolute value of an int
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// distance returns the number of moves needed to go from a position to
+// another one, assuming we can move diagonally.
+func distance(a, b Position) int {
+	dx, dy := abs(a.X-b.X), abs(a.Y-b.Y)
+
+	if dx > dy {
+		return dx
+	}
+	return dy
+}
+
+// linkCost returns the cost of linking a tracked ant with a sighting at the
+// given turn, or -1 if the sighting can't be this ant.
+func linkCost(a *TrackedAnt, s BasicAntStatus, turn int) int {
+	elapsed := turn - a.LastSeen
+
+	if elapsed < 1 || distance(a.Pos, s.Pos) > elapsed {
+		return -1
+	}
+
+	// where the ant would be if it kept going forward
+	expected := Position{
+		X: a.Pos.X + a.Dir.X*elapsed,
+		Y: a.Pos.Y + a.Dir.Y*elapsed,
+	}
+
+	cost := 2 * distance(expected, s.Pos)
+
+	if s.Dir != a.Dir {
+		cost++
+	}
+
+	return cost
+}
+
+// Update takes all the enemy ants we saw during a turn and links them with the
+// ones we already know. It returns the ants that are visible at this turn, in
+// the same order as the given sightings. Duplicated sightings (e.g. the same
+// ant seen by two of our ants) are merged.
+func (t *EnemyTracker) Update(turn int, sightings []BasicAntStatus) []*TrackedAnt {
+	var unique []BasicAntStatus
+
+Sightings:
+	for _, s := range sightings {
+		for _, u := range unique {
+			if s.Eq(u) {
+				continue Sightings
+			}
+		}
+		unique = append(unique, s)
+	}
+
+	var links []trackingLink
+
+	for _, a := range t.ants {
+		for i, s := range unique {
+			if cost := linkCost(a, s, turn); cost >= 0 {
+				links = append(links, trackingLink{ant: a, sighting: i, cost: cost})
+			}
+		}
+	}
+
+	// cheapest links first. Ties are broken on the oldest ant then the first
+	// sighting to keep the result deterministic.
+	sort.Slice(links, func(i, j int) bool {
+		if links[i].cost != links[j].cost {
+			return links[i].cost < links[j].cost
+		}
+		if links[i].ant.ID != links[j].ant.ID {
+			return links[i].ant.ID < links[j].ant.ID
+		}
+		return links[i].sighting < links[j].sighting
+	})
+
+	visible := make([]*TrackedAnt, len(unique))
+	linked := make(map[*TrackedAnt]bool)
+
+	for _, l := range links {
+		if linked[l.ant] || visible[l.sighting] != nil {
+			continue
+		}
+
+		linked[l.ant] = true
+		visible[l.sighting] = l.ant
+	}
+
+	// sightings we couldn't link are new ants
+	for i, s := range unique {
+		if visible[i] == nil {
+			a := &TrackedAnt{ID: t.nextID, Owner: t.guessOwner()}
+			t.nextID++
+			t.ants = append(t.ants, a)
+			visible[i] = a
+		}
+
+		visible[i].BasicAntStatus = s
+		visible[i].LastSeen = turn
+	}
+
+	// update the visibility of all ants and forget the old ones
+	known := t.ants[:0]
+
+	for _, a := range t.ants {
+		a.Visible = a.LastSeen == turn
+
+		if a.Owner == "" {
+			a.Owner = t.guessOwner()
+		}
+
+		if turn-a.LastSeen <= t.MaxAge {
+			known = append(known, a)
+		}
+	}
+
+	t.ants = known
+
+	return visible
+}
+
+// Ants returns all the ants we know, visible or not, ordered by ID
+func (t *EnemyTracker) Ants() []*TrackedAnt {
+	return t.ants
+}
+
+// Lost returns the ants we know but didn't see on the last turn, with their
+// last known position.
+func (t *EnemyTracker) Lost() (ants []*TrackedAnt) {
+	for _, a := range t.ants {
+		if !a.Visible {
+			ants = append(ants, a)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"github.com/franela/goblin"
+	o "github.com/onsi/gomega"
+	"testing"
+)
+
+func antAt(x, y, dx, dy int) BasicAntStatus {
+	return BasicAntStatus{
+		Pos:   Position{X: x, Y: y},
+		Dir:   Direction{X: dx, Y: dy},
+		Brain: "controlled",
+	}
+}
+
+func TestTracker(t *testing.T) {
+
+	g := goblin.Goblin(t)
+
+	o.RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	g.Describe("NewEnemyTracker", func() {
+		g.It("Should not return nil", func() {
+			o.Expect(NewEnemyTracker()).NotTo(o.BeNil())
+		})
+
+		g.It("Should return a tracker with no ants", func() {
+			o.Expect(NewEnemyTracker().Ants()).To(o.BeEmpty())
+		})
+	})
+
+	g.Describe("EnemyTracker", func() {
+		var tr *EnemyTracker
+
+		g.BeforeEach(func() {
+			tr = NewEnemyTracker()
+		})
+
+		g.Describe(".Update(turn, sightings)", func() {
+			g.It("Should give different IDs to different ants", func() {
+				ants := tr.Update(1, []BasicAntStatus{
+					antAt(1, 1, 1, 0),
+					antAt(10, 10, 1, 0),
+				})
+
+				o.Expect(ants).To(o.HaveLen(2))
+				o.Expect(ants[0].ID).NotTo(o.Equal(ants[1].ID))
+			})
+
+			g.It("Should merge duplicated sightings", func() {
+				ants := tr.Update(1, []BasicAntStatus{
+					antAt(1, 1, 1, 0),
+					antAt(1, 1, 1, 0),
+				})
+
+				o.Expect(ants).To(o.HaveLen(1))
+			})
+
+			g.It("Should keep the ID of an ant going forward", func() {
+				first := tr.Update(1, []BasicAntStatus{antAt(1, 1, 1, 0)})
+				second := tr.Update(2, []BasicAntStatus{antAt(2, 1, 1, 0)})
+
+				o.Expect(second[0].ID).To(o.Equal(first[0].ID))
+			})
+
+			g.It("Should keep the ID of an ant turning in place", func() {
+				first := tr.Update(1, []BasicAntStatus{antAt(1, 1, 1, 0)})
+				second := tr.Update(2, []BasicAntStatus{antAt(1, 1, 0, 1)})
+
+				o.Expect(second[0].ID).To(o.Equal(first[0].ID))
+			})
+
+			g.It("Should follow the trajectories of crossing ants", func() {
+				first := tr.Update(1, []BasicAntStatus{
+					antAt(1, 1, 1, 0),
+					antAt(3, 1, -1, 0),
+				})
+				second := tr.Update(2, []BasicAntStatus{
+					antAt(2, 1, -1, 0),
+					antAt(2, 1, 1, 0),
+				})
+
+				o.Expect(second[0].ID).To(o.Equal(first[1].ID))
+				o.Expect(second[1].ID).To(o.Equal(first[0].ID))
+			})
+
+			g.It("Should give a new ID to an ant too far away", func() {
+				first := tr.Update(1, []BasicAntStatus{antAt(1, 1, 1, 0)})
+				second := tr.Update(2, []BasicAntStatus{antAt(5, 1, 1, 0)})
+
+				o.Expect(second[0].ID).NotTo(o.Equal(first[0].ID))
+			})
+
+			g.It("Should remember ants out of sight", func() {
+				tr.Update(1, []BasicAntStatus{antAt(1, 1, 1, 0)})
+				tr.Update(2, []BasicAntStatus{})
+
+				lost := tr.Lost()
+				o.Expect(lost).To(o.HaveLen(1))
+				o.Expect(lost[0].Pos).To(o.Equal(Position{X: 1, Y: 1}))
+				o.Expect(lost[0].LastSeen).To(o.Equal(1))
+			})
+
+			g.It("Should link an ant seen again with its old ID", func() {
+				first := tr.Update(1, []BasicAntStatus{antAt(1, 1, 1, 0)})
+				tr.Update(2, []BasicAntStatus{})
+				third := tr.Update(3, []BasicAntStatus{antAt(3, 1, 1, 0)})
+
+				o.Expect(third[0].ID).To(o.Equal(first[0].ID))
+				o.Expect(tr.Lost()).To(o.BeEmpty())
+			})
+
+			g.It("Should forget ants lost for too long", func() {
+				tr.MaxAge = 2
+				tr.Update(1, []BasicAntStatus{antAt(1, 1, 1, 0)})
+				tr.Update(4, []BasicAntStatus{})
+
+				o.Expect(tr.Ants()).To(o.BeEmpty())
+			})
+
+			g.It("Should guess the owner if there's only one opponent", func() {
+				tr.SetOpponents([]string{"foo"})
+				ants := tr.Update(1, []BasicAntStatus{antAt(1, 1, 1, 0)})
+
+				o.Expect(ants[0].Owner).To(o.Equal("foo"))
+			})
+
+			g.It("Should not guess the owner if there're more opponents", func() {
+				tr.SetOpponents([]string{"foo", "bar"})
+				ants := tr.Update(1, []BasicAntStatus{antAt(1, 1, 1, 0)})
+
+				o.Expect(ants[0].Owner).To(o.Equal(""))
+			})
+		})
+	})
+}
@@ -0,0 +1,176 @@
+package api
+
+// This file describes a watcher, which follows a game from the outside by
+// polling its status until it's over. Each poll gives a WatchEvent with the
+// scoreboard and the change of status, if any:
+//
+//     w := NewWatcher(cl, "42")
+//     err := w.Watch(func(e *WatchEvent) error {
+//         fmt.Printf("turn %d: %v\n", e.Status.Turn, e.Scoreboard)
+//         return nil
+//     })
+
+import (
+	"sort"
+	"time"
+)
+
+// bounds of the interval between two polls
+const (
+	minPollInterval = 250 * time.Millisecond
+	maxPollInterval = 5 * time.Second
+)
+
+// PollInterval returns the interval between two polls of a game with some
+// pace. The faster the game, the more often we poll it, but not more than
+// four times a second.
+func PollInterval(pace int) time.Duration {
+	if pace <= 0 {
+		return maxPollInterval
+	}
+
+	d := time.Second / time.Duration(pace)
+
+	if d < minPollInterval {
+		return minPollInterval
+	}
+	if d > maxPollInterval {
+		return maxPollInterval
+	}
+
+	return d
+}
+
+// A ScoreLine is the score of a player at some poll
+type ScoreLine struct {
+	Player string
+	Score  int
+	// the points the player won since the previous poll
+	Delta int
+}
+
+// Scoreboard returns the scores of a game sorted from the best to the worst,
+// with their deltas since a previous status which can be nil. Players without
+// a score yet have 0.
+func Scoreboard(status, previous *GameStatus) []ScoreLine {
+	scores := make(map[string]int)
+
+	for _, p := range status.Players {
+		scores[p] = 0
+	}
+	for p, s := range status.Score {
+		scores[p] = s
+	}
+
+	lines := []ScoreLine{}
+
+	for p, s := range scores {
+		l := ScoreLine{Player: p, Score: s}
+		if previous != nil {
+			l.Delta = s - previous.Score[p]
+		}
+		lines = append(lines, l)
+	}
+
+	sort.Slice(lines, func(i, j int) bool {
+		if lines[i].Score != lines[j].Score {
+			return lines[i].Score > lines[j].Score
+		}
+		return lines[i].Player < lines[j].Player
+	})
+
+	return lines
+}
+
+// A WatchEvent is what a watcher got at some poll
+type WatchEvent struct {
+	Status *GameStatus
+	// the status of the game at the previous poll, empty at the first one
+	PreviousStatus string
+	Scoreboard     []ScoreLine
+}
+
+// Transition returns true if the status of the game changed since the
+// previous poll, or if it's the first one
+func (e *WatchEvent) Transition() bool {
+	return e.PreviousStatus != e.Status.Status
+}
+
+// Over returns true if the game is over
+func (e *WatchEvent) Over() bool {
+	return e.Status.Status == overStatus
+}
+
+// A Watcher polls the status of a game
+type Watcher struct {
+	// the interval between two polls. If it's 0, it depends on the game's
+	// pace.
+	Interval time.Duration
+
+	client *Client
+	id     GameID
+	last   *GameStatus
+
+	// how we wait between two polls; replaced in tests
+	sleep func(time.Duration)
+}
+
+// NewWatcher returns a new watcher for a game
+func NewWatcher(cl *Client, id GameID) *Watcher {
+	return &Watcher{client: cl, id: id, sleep: time.Sleep}
+}
+
+// Poll gets the status of the game once
+func (w *Watcher) Poll() (*WatchEvent, error) {
+	status, err := w.client.GetGameIdentifierStatus(w.id)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &WatchEvent{
+		Status:     status,
+		Scoreboard: Scoreboard(status, w.last),
+	}
+
+	if w.last != nil {
+		e.PreviousStatus = w.last.Status
+	}
+
+	w.last = status
+
+	return e, nil
+}
+
+// interval returns the interval until the next poll
+func (w *Watcher) interval() time.Duration {
+	if w.Interval > 0 {
+		return w.Interval
+	}
+
+	if w.last != nil && w.last.Spec != nil {
+		return PollInterval(w.last.Spec.Pace)
+	}
+
+	return maxPollInterval
+}
+
+// Watch polls the game until it's over and calls a function on each poll. It
+// stops at the first error, either from the server or from the function.
+func (w *Watcher) Watch(f func(*WatchEvent) error) error {
+	for {
+		e, err := w.Poll()
+		if err != nil {
+			return err
+		}
+
+		if err = f(e); err != nil {
+			return err
+		}
+
+		if e.Over() {
+			return nil
+		}
+
+		w.sleep(w.interval())
+	}
+}
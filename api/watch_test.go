@@ -0,0 +1,146 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"github.com/franela/goblin"
+	o "github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+
+	g := goblin.Goblin(t)
+
+	o.RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	g.Describe("PollInterval", func() {
+		g.It("Should poll faster games more often", func() {
+			o.Expect(PollInterval(1)).To(o.Equal(time.Second))
+			o.Expect(PollInterval(2)).To(o.Equal(500 * time.Millisecond))
+		})
+
+		g.It("Should stay within bounds", func() {
+			o.Expect(PollInterval(100)).To(o.Equal(minPollInterval))
+			o.Expect(PollInterval(0)).To(o.Equal(maxPollInterval))
+		})
+	})
+
+	g.Describe("Scoreboard", func() {
+		g.It("Should sort the players by score then by name", func() {
+			status := &GameStatus{
+				Players: []string{"a", "b", "c", "d"},
+				Score:   map[string]int{"a": 1, "b": 3, "d": 1},
+			}
+
+			o.Expect(Scoreboard(status, nil)).To(o.Equal([]ScoreLine{
+				{"b", 3, 0}, {"a", 1, 0}, {"d", 1, 0}, {"c", 0, 0},
+			}))
+		})
+
+		g.It("Should give the deltas since the previous status", func() {
+			prev := &GameStatus{Score: map[string]int{"a": 1, "b": 3}}
+			status := &GameStatus{Score: map[string]int{"a": 4, "b": 3, "c": 2}}
+
+			o.Expect(Scoreboard(status, prev)).To(o.Equal([]ScoreLine{
+				{"a", 4, 3}, {"b", 3, 0}, {"c", 2, 2},
+			}))
+		})
+	})
+
+	g.Describe("Watcher", func() {
+		var ts *httptest.Server
+		var w *Watcher
+		var polls int
+		var slept []time.Duration
+
+		statuses := []struct {
+			status string
+			turn   int
+			score  int
+		}{
+			{"waiting", 0, 0},
+			{"playing", 1, 0},
+			{"playing", 2, 5},
+			{"over", 3, 7},
+		}
+
+		g.BeforeEach(func() {
+			polls = 0
+			slept = nil
+
+			ts = httptest.NewTLSServer(http.HandlerFunc(func(
+				rw http.ResponseWriter, r *http.Request) {
+
+				s := statuses[polls]
+				if polls < len(statuses)-1 {
+					polls++
+				}
+
+				fmt.Fprintf(rw, `{"status": "completed", "response": {"status": {
+					"visibility": "public", "pace": 2, "players": ["foo"],
+					"score": {"foo": %d}, "status": {"status": %q}, "turn": %d}}}`,
+					s.score, s.status, s.turn)
+			}))
+
+			cl := NewClient()
+			cl.SetBaseURL(ts.URL)
+
+			w = NewWatcher(cl, "g1")
+			w.sleep = func(d time.Duration) { slept = append(slept, d) }
+		})
+
+		g.AfterEach(func() { ts.Close() })
+
+		g.It("Should poll the game until it's over", func() {
+			var events []*WatchEvent
+
+			err := w.Watch(func(e *WatchEvent) error {
+				events = append(events, e)
+				return nil
+			})
+
+			o.Expect(err).To(o.BeNil())
+			o.Expect(len(events)).To(o.Equal(4))
+
+			o.Expect(events[0].PreviousStatus).To(o.Equal(""))
+			o.Expect(events[0].Transition()).To(o.BeTrue())
+			o.Expect(events[1].Transition()).To(o.BeTrue())
+			o.Expect(events[2].Transition()).To(o.BeFalse())
+			o.Expect(events[3].Transition()).To(o.BeTrue())
+			o.Expect(events[3].Over()).To(o.BeTrue())
+
+			o.Expect(events[2].Scoreboard).To(o.Equal([]ScoreLine{{"foo", 5, 5}}))
+			o.Expect(events[3].Scoreboard).To(o.Equal([]ScoreLine{{"foo", 7, 2}}))
+
+			// pace 2
+			o.Expect(slept).To(o.Equal([]time.Duration{
+				500 * time.Millisecond, 500 * time.Millisecond, 500 * time.Millisecond,
+			}))
+		})
+
+		g.It("Should use its interval if it has one", func() {
+			w.Interval = time.Minute
+			w.Watch(func(*WatchEvent) error { return nil })
+
+			o.Expect(slept[0]).To(o.Equal(time.Minute))
+		})
+
+		g.It("Should stop on the first error", func() {
+			stop := errors.New("stop")
+
+			err := w.Watch(func(e *WatchEvent) error {
+				if e.Status.Status == "playing" {
+					return stop
+				}
+				return nil
+			})
+
+			o.Expect(err).To(o.Equal(stop))
+			o.Expect(len(slept)).To(o.Equal(1))
+		})
+	})
+}
@@ -0,0 +1,148 @@
+package main
+
+// This file implements how the command-line tool gets its credentials and
+// keeps its session (see `api/credentials.go`), and the `login`, `logout` and
+// `register` subcommands:
+//
+//     ANTROID_PASSWORD=secret antroid --login ww login
+//     antroid status 42
+//     antroid logout
+//
+// Once logged in, other commands resume the saved session instead of logging
+// in and out each time.
+
+import (
+	"fmt"
+	"github.com/bfontaine/antroid/api"
+	"os"
+	"path/filepath"
+)
+
+// the credentials we use if we don't find any
+var defaultCredentials = api.Credentials{Login: "ww", Password: "a"}
+
+// configPath returns the path of a file in our configuration directory
+func configPath(name string) string {
+	return filepath.Join(os.Getenv("HOME"), ".antroid", name)
+}
+
+// sessionPath is the file in which we keep the session between two
+// invocations
+var sessionPath = configPath("session.json")
+
+// credentials returns the credentials given by the user: the ones of the
+// flags, then the ones of the environment variables, then the ones of the
+// credentials file. They don't include the default ones.
+func credentials(flags api.Credentials, path string) (api.Credentials, error) {
+	creds := flags
+	creds.Inherit(api.CredentialsFromEnv())
+
+	if creds.Login == "" || creds.Password == "" {
+		file, err := api.LoadCredentials(path)
+		if err != nil {
+			return creds, err
+		}
+		creds.Inherit(file)
+	}
+
+	return creds, nil
+}
+
+// userCredentials returns the credentials given by the user, and the ones the
+// commands which don't use the kept session log in with, i.e. the same ones
+// completed by the default ones. It exits if it can't read them; only the
+// commands which log in call it, so that the other ones work without a
+// readable credentials file.
+func userCredentials() (creds, user api.Credentials) {
+	creds, err := credentials(api.Credentials{Login: *login, Password: *password}, *credFile)
+	if err != nil {
+		exitErr(err)
+	}
+
+	user = creds
+	user.Inherit(defaultCredentials)
+
+	return creds, user
+}
+
+// saveSession saves the session of a logged client
+func saveSession(cl *api.Client) error {
+	if err := os.MkdirAll(filepath.Dir(sessionPath), 0700); err != nil {
+		return err
+	}
+
+	return cl.Session().Save(sessionPath)
+}
+
+// sessionCommand runs the `login` or `register` subcommand. It returns false
+// if the command is another one.
+func sessionCommand(cl *api.Client, cmd string, creds api.Credentials) (bool, error) {
+	switch cmd {
+	case loginCmd.FullCommand():
+		if err := cl.LoginWithCredentials(creds.Login, creds.Password); err != nil {
+			return true, err
+		}
+
+		if err := saveSession(cl); err != nil {
+			return true, err
+		}
+
+		fmt.Printf("Logged in as %s\n", creds.Login)
+
+	case regCmd.FullCommand():
+		if err := cl.RegisterWithCredentials(creds.Login, creds.Password); err != nil {
+			return true, err
+		}
+
+		fmt.Printf("Registered %s\n", creds.Login)
+
+	default:
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// logout logs out of the kept session. It doesn't need the credentials.
+func logout(cl *api.Client) error {
+	s, err := api.LoadSession(sessionPath)
+	if err != nil {
+		return err
+	}
+
+	if !cl.Resume(s, "") {
+		fmt.Println("Not logged in")
+		return nil
+	}
+
+	// the session may have expired on the server-side; we forget it anyway
+	if err = os.Remove(sessionPath); err != nil {
+		return err
+	}
+
+	if err = cl.Logout(); err != nil && err != api.ErrNotLogged {
+		return err
+	}
+
+	fmt.Printf("Logged out %s\n", s.Username)
+
+	return nil
+}
+
+// connect authenticates a client, resuming the saved session if there's one
+// for this server and user; any user if we weren't given a login. It returns
+// true if it resumed it; otherwise we should log out once we're done.
+func connect(cl *api.Client, creds api.Credentials) (bool, error) {
+	s, err := api.LoadSession(sessionPath)
+	if err != nil {
+		return false, err
+	}
+
+	if cl.Resume(s, creds.Login) {
+		return true, nil
+	}
+
+	creds.Inherit(defaultCredentials)
+
+	return false, cl.LoginWithCredentials(creds.Login, creds.Password)
+}
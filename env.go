@@ -0,0 +1,114 @@
+package main
+
+// This file implements the `env` subcommand, which exposes the learning
+// environment (see `api/env.go`) as a line-based protocol on stdin and stdout
+// so it can be driven from another language, e.g. a Python training script
+// which starts `antroid env` as a subprocess. See `docs/env_protocol.md`.
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"github.com/bfontaine/antroid/api"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// envResponse is the JSON line we write after each request
+type envResponse struct {
+	Observation *api.Observation `json:"observation,omitempty"`
+	Reward      float64          `json:"reward"`
+	Done        bool             `json:"done"`
+	Error       string           `json:"error,omitempty"`
+}
+
+// setSpecParam sets a game parameter from a `KEY=VALUE` argument of `reset`
+func setSpecParam(gs *api.GameSpec, arg string) error {
+	parts := strings.SplitN(arg, "=", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("bad parameter %q, expected KEY=VALUE", arg)
+	}
+
+	n, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return fmt.Errorf("bad value for %s: %q", parts[0], parts[1])
+	}
+
+	switch parts[0] {
+	case "turns":
+		gs.Turns = n
+	case "ants":
+		gs.AntsPerPlayer = n
+	case "pace":
+		gs.Pace = n
+	case "energy":
+		gs.InitialEnergy = n
+	case "acid":
+		gs.InitialAcid = n
+	default:
+		return fmt.Errorf("unknown parameter %q", parts[0])
+	}
+
+	return nil
+}
+
+// serveEnv reads requests on `r` and writes the responses on `w` until it
+// reads `close` or the end of the input
+func serveEnv(e *api.Env, gs api.GameSpec, r io.Reader, w io.Writer) error {
+	reader := bufio.NewReader(r)
+	encoder := json.NewEncoder(w)
+
+	defer e.Close()
+
+	for {
+		line, readErr := reader.ReadString('\n')
+		if readErr != nil && line == "" {
+			if readErr == io.EOF {
+				return nil
+			}
+			return readErr
+		}
+
+		words := strings.Fields(line)
+		if len(words) == 0 {
+			continue
+		}
+
+		var resp envResponse
+		var err error
+
+		switch words[0] {
+		case "reset":
+			spec := gs
+
+			for _, arg := range words[1:] {
+				if err = setSpecParam(&spec, arg); err != nil {
+					break
+				}
+			}
+
+			if err == nil {
+				resp.Observation, err = e.Reset(&spec)
+			}
+
+		case "step":
+			cmds := api.Commands(strings.Join(words[1:], ","))
+			resp.Observation, resp.Reward, resp.Done, err = e.Step(cmds)
+
+		case "close":
+			return nil
+
+		default:
+			err = fmt.Errorf("unknown request %q", words[0])
+		}
+
+		if err != nil {
+			resp = envResponse{Error: err.Error()}
+		}
+
+		if err = encoder.Encode(resp); err != nil {
+			return err
+		}
+	}
+}
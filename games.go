@@ -0,0 +1,86 @@
+package main
+
+// This file implements the `games` subcommand, which lists the visible games.
+// They can be filtered, sorted, and shown with their status, which we get
+// for all of them at the same time:
+//
+//     antroid games --open --visibility public --sort turn --status
+
+import (
+	"fmt"
+	"github.com/bfontaine/antroid/api"
+	"os"
+)
+
+// listGames prints the games selected by the `games` flags
+func listGames(cl *api.Client) error {
+	f := api.GameFilter{
+		Visibility: *gamesVis,
+		Open:       *gamesOpen,
+		Status:     *gamesState,
+	}
+
+	if *gamesMine {
+		f.Creator = cl.Username()
+	}
+
+	if *gamesAfter != "" {
+		after, err := api.ParseDate(*gamesAfter)
+		if err != nil {
+			return err
+		}
+		f.After = after
+	}
+
+	games, err := cl.ListGames()
+	if err != nil {
+		return err
+	}
+
+	// we don't need the statuses of the games we won't show
+	listed := api.FilterGames(listedGames(games), api.GameFilter{
+		Creator: f.Creator,
+		After:   f.After,
+	})
+
+	// we may need the statuses to filter or sort the games even if we don't
+	// show them
+	fetch := *gamesStatus || f.NeedsStatus() || api.SortNeedsStatus(*gamesSort)
+
+	if fetch {
+		games = nil
+		for _, g := range listed {
+			games = append(games, g.Game)
+		}
+
+		var errs []error
+		listed, errs = cl.FetchStatuses(games, *gamesParallel)
+
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	listed = api.FilterGames(listed, f)
+
+	if *gamesSort != "" {
+		if err = api.SortGames(listed, *gamesSort, *gamesReverse); err != nil {
+			return err
+		}
+	} else if *gamesReverse {
+		for i, j := 0, len(listed)-1; i < j; i, j = i+1, j-1 {
+			listed[i], listed[j] = listed[j], listed[i]
+		}
+	}
+
+	return printGames(listed, *gamesStatus)
+}
+
+// listedGames returns games without their status
+func listedGames(games []api.Game) []api.ListedGame {
+	listed := []api.ListedGame{}
+	for _, g := range games {
+		listed = append(listed, api.ListedGame{Game: g})
+	}
+	return listed
+}
@@ -252,7 +252,12 @@ class AntroidGUI(Tk):
             self.printAnt((x,y,dx,dy,b))
         # affiche enemy ants 
         for ant in EAnts:
-            self.printAnt(ant)
+        # nb N : Ant Enemy 'X Y DX DY B I O'
+            self.printAnt(tuple(ant[:5]))
+        # affiche AIs' marks and paths
+        if len(turn) > 5:
+            for annotation in turn[5]:
+                self.printAnnotation(annotation)
 
         #to move map
         self.maptroid.bind('<ButtonPress-1>',self.grab)
@@ -276,6 +281,21 @@ class AntroidGUI(Tk):
         (x1, y1, x2, y2) = self.getPoint(x, y)
         self.maptroid.create_oval(x1, y1, x2, y2, fill="red4")
 
+    def printAnnotation(self, annotation):
+        # 'I mark X Y label' or 'I path N X Y ...'
+        kind = annotation[1]
+        if kind == "mark":
+            (x1, y1, x2, y2) = self.getPoint(int(annotation[2]), int(annotation[3]))
+            self.maptroid.create_oval(x1, y1, x2, y2, outline="magenta")
+        elif kind == "path":
+            coords = map(int, annotation[3:])
+            points = []
+            for i in range(0, len(coords) - 1, 2):
+                (x1, y1, x2, y2) = self.getPoint(coords[i], coords[i+1])
+                points += [(x1 + x2) / 2, (y1 + y2) / 2]
+            if len(points) >= 4:
+                self.maptroid.create_line(*points, fill="magenta")
+
     def getPoint(self, x, y):
         x1 = (x - self.MinX) * self.caselength + self.CaseMarge
         y1 = (y - self.MinY) * self.caselength + self.CaseMarge
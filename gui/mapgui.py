@@ -26,6 +26,7 @@ class MapGUI(object):
         self.YAnts = []
         self.EAnts = []
         self.MapTurn = []
+        self.Annotations = []
 
     def initpiper(self):
         self.queue = Queue.Queue()
@@ -95,7 +96,7 @@ class MapGUI(object):
         N = self.collectStdin()
         nbEAnt = int(N)
 
-        # nb N : Ant Enemy : X Y DX DY B 
+        # nb N : Ant Enemy : X Y DX DY B I O
         for LAnt in range(0,nbEAnt):
             self.decodeEnemyAnt(self.collectStdin())
         self.nextTurn.append(self.EAnts)
@@ -107,6 +108,21 @@ class MapGUI(object):
             self.decodeCase(self.collectStdin())
         self.nextTurn.append(self.MapTurn)
 
+        # B -> number of facts on the blackboard
+        nbFacts = int(self.collectStdin())
+
+        # nb B : Fact : I KEY VALUE
+        for fact in range(0, nbFacts):
+            self.collectStdin()
+
+        # K -> number of annotations from the AIs
+        nbAnnotations = int(self.collectStdin())
+
+        # nb K : Annotation : I mark X Y label, I path N X Y ..., I log text
+        for annotation in range(0, nbAnnotations):
+            self.decodeAnnotation(self.collectStdin())
+        self.nextTurn.append(self.Annotations)
+
     def decodeFirstLine(self, line):
         # first Line: T A P S
         InfoTurn = map(int, line.split(" "))
@@ -120,7 +136,7 @@ class MapGUI(object):
         self.YAnts.append(map(int, line.split(" ")))
 
     def decodeEnemyAnt(self, line):
-        # nb N : Ant Enemy : X Y DX DY B 
+        # nb N : Ant Enemy : X Y DX DY B I O
         self.EAnts.append(map(int, line.split(" ")))
 
     def decodeMapInit(self, line):
@@ -133,6 +149,10 @@ class MapGUI(object):
         # Case : X Y C S 
         self.MapTurn.append(map(int, line.split(" ")))
 
+    def decodeAnnotation(self, line):
+        # Annotation : I KIND ...
+        self.Annotations.append(line.split())
+
 def main():
     mapTroid = MapGUI()
     mapTroid.startRead()
@@ -56,6 +56,15 @@ let _ =
     let [w ; h ; n] = read_ints () in
     let map = loop read_ints n in
 
+    (* Skip the blackboard. *)
+    let [b] = read_ints () in
+    let _ = loop (fun () -> input_line stdin) b in
+
+    (* Read the AIs' annotations, as lists of words. *)
+    let [k] = read_ints () in
+    let annotations = loop (fun () -> input_line stdin
+                                      |> Str.split (Str.regexp "[ \t]+")) k in
+
     (* Get the window size and compute the size of a cell. *)
     let scr_w = size_x () in
     let scr_h = size_y () in
@@ -77,7 +86,15 @@ let _ =
 
     (* Enemies *)
     set_color blue ;
-    List.iter (fun [x; y; _; _; _] -> square x y) enemies ;
+    List.iter (fun (x :: y :: _) -> square x y) enemies ;
+
+    (* AIs' marks *)
+    set_color magenta ;
+    List.iter (function
+               | _ :: "mark" :: x :: y :: _ ->
+                 draw_rect (int_of_string x * side) (int_of_string y * side)
+                           side side
+               | _ -> ()) annotations ;
 
     (* Refresh the screen. *)
     synchronize ()
@@ -0,0 +1,93 @@
+package main
+
+// This file implements the `match` subcommand, which plays a private game
+// between several accounts from the same process, each with its own AIs:
+//
+//     antroid match "alice=ai/ant.rb" "bob:secret=ai/scout.scm;tcp://10.0.0.2:9000"
+//
+// Each player is given as `LOGIN[:PASSWORD]=AI[;AI...]`. The password
+// defaults to the one given with `--password`.
+
+import (
+	"fmt"
+	"github.com/bfontaine/antroid/api"
+	"strings"
+)
+
+// a contestant is one player of a match
+type contestant struct {
+	login, password string
+	ais             []string
+}
+
+// parseContestant parses a `LOGIN[:PASSWORD]=AI[;AI...]` argument
+func parseContestant(arg, defaultPassword string) (c contestant, err error) {
+	parts := strings.SplitN(arg, "=", 2)
+
+	if len(parts) != 2 || parts[0] == "" {
+		err = fmt.Errorf("bad player %q, expected LOGIN[:PASSWORD]=AI[;AI...]", arg)
+		return
+	}
+
+	credentials := strings.SplitN(parts[0], ":", 2)
+
+	c.login = credentials[0]
+	c.password = defaultPassword
+	if len(credentials) == 2 {
+		c.password = credentials[1]
+	}
+
+	for _, ai := range strings.Split(parts[1], ";") {
+		if ai = strings.TrimSpace(ai); ai != "" {
+			c.ais = append(c.ais, ai)
+		}
+	}
+
+	if len(c.ais) == 0 {
+		err = fmt.Errorf("player %s doesn't have any AI", c.login)
+	}
+
+	return
+}
+
+// playMatch plays a match between the given players
+func playMatch(args []string, defaultPassword string, gs api.GameSpec, debug bool) error {
+	var players []*api.Player
+
+	for _, arg := range args {
+		c, err := parseContestant(arg, defaultPassword)
+		if err != nil {
+			return err
+		}
+
+		p := api.NewPlayer(c.login, c.password)
+		p.SetDebug(debug)
+
+		// stop its AIs and log it out even if the match fails
+		defer p.Quit()
+
+		if err := p.AIs.Load(c.ais...); err != nil {
+			return err
+		}
+
+		players = append(players, p)
+	}
+
+	m := api.NewMatch(players...)
+
+	if err := m.Start(&gs); err != nil {
+		return err
+	}
+
+	fmt.Printf("Match started between %d players.\n", len(players))
+
+	if err := m.Play(); err != nil {
+		return err
+	}
+
+	fmt.Println("End of game.")
+	fmt.Println("Scores:")
+	m.PrintScores()
+
+	return nil
+}
@@ -0,0 +1,558 @@
+package main
+
+// This file describes how the subcommands which call the remote API print
+// their results. With `--output json` they print one JSON object on stdout,
+// and a JSON error object if they fail, e.g.:
+//
+//     {"id":"42","created":"...","creator":"ww","teaser":"a test"}
+//     {"error":"Invalid game identifier"}
+//
+// The objects have their own structs below instead of the API ones so that
+// their format doesn't change if we change the API structs. `--output table`
+// prints them as tables and `--output text` (the default) as sentences.
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/bfontaine/antroid/api"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// output formats
+const (
+	textOutput  = "text"
+	tableOutput = "table"
+	jsonOutput  = "json"
+)
+
+type gameJSON struct {
+	ID      api.GameID `json:"id"`
+	Created string     `json:"created"`
+	Creator string     `json:"creator"`
+	Teaser  string     `json:"teaser"`
+}
+
+type specJSON struct {
+	Public        bool     `json:"public"`
+	Players       []string `json:"players"`
+	Pace          int      `json:"pace"`
+	Turns         int      `json:"turns"`
+	MaxPlayers    int      `json:"max_players"`
+	MinPlayers    int      `json:"min_players"`
+	AntsPerPlayer int      `json:"ants_per_player"`
+	InitialEnergy int      `json:"initial_energy"`
+	InitialAcid   int      `json:"initial_acid"`
+}
+
+type statusJSON struct {
+	gameJSON
+
+	Status  string         `json:"status"`
+	Turn    int            `json:"turn"`
+	Score   map[string]int `json:"score"`
+	Players []string       `json:"players"`
+	Spec    *specJSON      `json:"spec,omitempty"`
+}
+
+type cellJSON struct {
+	X       int    `json:"x"`
+	Y       int    `json:"y"`
+	Content string `json:"content"`
+}
+
+type visibleAntJSON struct {
+	X     int    `json:"x"`
+	Y     int    `json:"y"`
+	DX    int    `json:"dx"`
+	DY    int    `json:"dy"`
+	Brain string `json:"brain"`
+}
+
+type antJSON struct {
+	ID int `json:"id"`
+	visibleAntJSON
+
+	Energy      int              `json:"energy"`
+	Acid        int              `json:"acid"`
+	Vision      []cellJSON       `json:"vision"`
+	VisibleAnts []visibleAntJSON `json:"visible_ants"`
+}
+
+type turnJSON struct {
+	Turn int       `json:"turn"`
+	Ants []antJSON `json:"ants"`
+}
+
+type apiErrorJSON struct {
+	Code        int    `json:"code"`
+	Description string `json:"description"`
+}
+
+type apiMethodJSON struct {
+	Name        string         `json:"name"`
+	Verb        string         `json:"verb"`
+	Input       []string       `json:"input"`
+	Errors      []apiErrorJSON `json:"errors"`
+	Description string         `json:"description"`
+}
+
+type errorJSON struct {
+	Error string `json:"error"`
+	// the problems of an invalid game spec
+	Problems []string `json:"problems,omitempty"`
+}
+
+// printOutput prints the result of a command in the chosen format: `v` in
+// JSON, or what `text` or `table` print. `table` can be nil if it's the same
+// as `text`.
+func printOutput(v interface{}, text, table func(w io.Writer)) error {
+	switch *outFmt {
+	case jsonOutput:
+		return json.NewEncoder(os.Stdout).Encode(v)
+
+	case tableOutput:
+		if table != nil {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			table(w)
+			return w.Flush()
+		}
+	}
+
+	text(os.Stdout)
+	return nil
+}
+
+// printError prints an error in JSON on stdout
+func printError(e error) {
+	ej := errorJSON{Error: e.Error()}
+
+	if errs, ok := e.(api.SpecErrors); ok {
+		for _, se := range errs {
+			ej.Problems = append(ej.Problems, se.Error())
+		}
+	}
+
+	json.NewEncoder(os.Stdout).Encode(ej)
+}
+
+func newGameJSON(g api.Game) gameJSON {
+	return gameJSON{
+		ID:      g.Identifier,
+		Created: g.CreationDate,
+		Creator: g.Creator,
+		Teaser:  g.Teaser,
+	}
+}
+
+func newVisibleAntJSON(a api.BasicAntStatus) visibleAntJSON {
+	return visibleAntJSON{
+		X:     a.Pos.X,
+		Y:     a.Pos.Y,
+		DX:    a.Dir.X,
+		DY:    a.Dir.Y,
+		Brain: a.Brain,
+	}
+}
+
+func newTurnJSON(t *api.Turn) turnJSON {
+	tj := turnJSON{Turn: t.Number, Ants: []antJSON{}}
+
+	for _, a := range t.AntsStatuses {
+		aj := antJSON{
+			ID:             a.ID,
+			visibleAntJSON: newVisibleAntJSON(a.BasicAntStatus),
+			Energy:         a.Energy,
+			Acid:           a.Acid,
+			Vision:         []cellJSON{},
+			VisibleAnts:    []visibleAntJSON{},
+		}
+
+		if a.Vision != nil {
+			for _, c := range a.Vision.Cells {
+				aj.Vision = append(aj.Vision, cellJSON{X: c.Pos.X, Y: c.Pos.Y,
+					Content: c.Content})
+			}
+
+			// map iteration order is random but our output must be stable
+			sort.Slice(aj.Vision, func(i, j int) bool {
+				ci, cj := aj.Vision[i], aj.Vision[j]
+				if ci.Y != cj.Y {
+					return ci.Y < cj.Y
+				}
+				return ci.X < cj.X
+			})
+		}
+
+		for _, v := range a.VisibleAnts {
+			aj.VisibleAnts = append(aj.VisibleAnts, newVisibleAntJSON(v))
+		}
+
+		tj.Ants = append(tj.Ants, aj)
+	}
+
+	return tj
+}
+
+// printAPIInfo prints the doc of the API's methods, sorted by name, or only
+// of one method if `method` is not empty
+func printAPIInfo(info api.APIInfo, method string) error {
+	methods := []apiMethodJSON{}
+
+	for name, m := range info.Doc {
+		if method != "" && strings.TrimPrefix(name, "/") != method {
+			continue
+		}
+
+		mj := apiMethodJSON{
+			Name:        name,
+			Verb:        strings.ToUpper(m.Verb),
+			Input:       m.Input,
+			Errors:      []apiErrorJSON{},
+			Description: m.Description,
+		}
+
+		for _, e := range m.Errors {
+			mj.Errors = append(mj.Errors, apiErrorJSON{e.Code, e.Description})
+		}
+
+		methods = append(methods, mj)
+	}
+
+	if method != "" && len(methods) == 0 {
+		return fmt.Errorf("unknown API method %q", method)
+	}
+
+	sort.Slice(methods, func(i, j int) bool { return methods[i].Name < methods[j].Name })
+
+	var v interface{} = methods
+	if method != "" {
+		v = methods[0]
+	}
+
+	return printOutput(v, func(w io.Writer) {
+		for i, m := range methods {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+
+			fmt.Fprintf(w, "%s /%s\n", m.Verb, strings.TrimPrefix(m.Name, "/"))
+			fmt.Fprintf(w, "  %s\n", m.Description)
+
+			if len(m.Input) > 0 {
+				fmt.Fprintln(w, "  Parameters:")
+				for _, in := range m.Input {
+					fmt.Fprintf(w, "    %s\n", in)
+				}
+			}
+
+			if len(m.Errors) > 0 {
+				fmt.Fprintln(w, "  Errors:")
+				for _, e := range m.Errors {
+					known := "unknown to this client"
+					if err, ok := api.KnownError(e.Code); ok {
+						known = err.Error()
+					}
+					fmt.Fprintf(w, "    %d %s (%s)\n", e.Code, e.Description, known)
+				}
+			}
+		}
+	}, func(w io.Writer) {
+		fmt.Fprintln(w, "METHOD\tVERB\tPARAMETERS\tERRORS\tDESCRIPTION")
+		for _, m := range methods {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\n", m.Name, m.Verb, len(m.Input),
+				len(m.Errors), m.Description)
+		}
+	})
+}
+
+type incompatibilityJSON struct {
+	Method  string `json:"method"`
+	Kind    string `json:"kind"`
+	Details string `json:"details,omitempty"`
+}
+
+// printIncompatibilities prints the differences between the remote API and
+// our client
+func printIncompatibilities(incs []api.Incompatibility) error {
+	ijs := []incompatibilityJSON{}
+	for _, inc := range incs {
+		ijs = append(ijs, incompatibilityJSON{inc.Method, inc.Kind, inc.Details})
+	}
+
+	return printOutput(ijs, func(w io.Writer) {
+		if len(incs) == 0 {
+			fmt.Fprintln(w, "The remote API is compatible with this client")
+		}
+		for _, inc := range incs {
+			fmt.Fprintf(w, "Warning: %s\n", inc)
+		}
+	}, func(w io.Writer) {
+		fmt.Fprintln(w, "METHOD\tKIND\tDETAILS")
+		for _, i := range ijs {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", i.Method, i.Kind, i.Details)
+		}
+	})
+}
+
+// printResponse prints the raw response of a method called with `call`,
+// indented unless we print JSON
+func printResponse(resp json.RawMessage) error {
+	return printOutput(resp, func(w io.Writer) {
+		var buf bytes.Buffer
+
+		if err := json.Indent(&buf, resp, "", "  "); err != nil {
+			w.Write(resp)
+		} else {
+			buf.WriteTo(w)
+		}
+		fmt.Fprintln(w)
+	}, nil)
+}
+
+type scoreLineJSON struct {
+	Rank   int    `json:"rank"`
+	Player string `json:"player"`
+	Score  int    `json:"score"`
+	Delta  int    `json:"delta"`
+}
+
+type watchJSON struct {
+	ID             api.GameID      `json:"id"`
+	Status         string          `json:"status"`
+	PreviousStatus string          `json:"previous_status,omitempty"`
+	Turn           int             `json:"turn"`
+	Scores         []scoreLineJSON `json:"scores"`
+	// only in the last object
+	Final bool `json:"final,omitempty"`
+}
+
+// newScoreLinesJSON ranks the lines of a scoreboard; tied players have the
+// same rank
+func newScoreLinesJSON(lines []api.ScoreLine) []scoreLineJSON {
+	sjs := []scoreLineJSON{}
+
+	for i, l := range lines {
+		rank := i + 1
+		if i > 0 && l.Score == lines[i-1].Score {
+			rank = sjs[i-1].Rank
+		}
+		sjs = append(sjs, scoreLineJSON{rank, l.Player, l.Score, l.Delta})
+	}
+
+	return sjs
+}
+
+func newWatchJSON(e *api.WatchEvent) watchJSON {
+	return watchJSON{
+		ID:             e.Status.Identifier,
+		Status:         e.Status.Status,
+		PreviousStatus: e.PreviousStatus,
+		Turn:           e.Status.Turn,
+		Scores:         newScoreLinesJSON(e.Scoreboard),
+	}
+}
+
+// printWatchEvent prints the status and the scoreboard of a watched game. In
+// JSON, each poll is an object on its own line.
+func printWatchEvent(e *api.WatchEvent) error {
+	wj := newWatchJSON(e)
+
+	return printOutput(wj, func(w io.Writer) {
+		if e.Transition() {
+			if e.PreviousStatus == "" {
+				fmt.Fprintf(w, "Game %s is %s\n", wj.ID, wj.Status)
+			} else {
+				fmt.Fprintf(w, "Game %s: %s -> %s\n", wj.ID, e.PreviousStatus, wj.Status)
+			}
+		}
+
+		fmt.Fprintf(w, "Turn %d (%s)\n", wj.Turn, wj.Status)
+		for _, s := range wj.Scores {
+			fmt.Fprintf(w, "  %d. %s: %d (%+d)\n", s.Rank, s.Player, s.Score, s.Delta)
+		}
+	}, func(w io.Writer) {
+		fmt.Fprintf(w, "Turn %d (%s)\n", wj.Turn, wj.Status)
+		fmt.Fprintln(w, "RANK\tPLAYER\tSCORE\tDELTA")
+		for _, s := range wj.Scores {
+			fmt.Fprintf(w, "%d\t%s\t%d\t%+d\n", s.Rank, s.Player, s.Score, s.Delta)
+		}
+		fmt.Fprintln(w)
+	})
+}
+
+// printRanking prints the final ranking of a watched game
+func printRanking(e *api.WatchEvent) error {
+	wj := newWatchJSON(e)
+	wj.Final = true
+
+	return printOutput(wj, func(w io.Writer) {
+		fmt.Fprintf(w, "Game %s is over after %d turns. Final ranking:\n", wj.ID, wj.Turn)
+		for _, s := range wj.Scores {
+			fmt.Fprintf(w, "%d. %s: %d\n", s.Rank, s.Player, s.Score)
+		}
+	}, func(w io.Writer) {
+		fmt.Fprintln(w, "RANK\tPLAYER\tSCORE")
+		for _, s := range wj.Scores {
+			fmt.Fprintf(w, "%d\t%s\t%d\n", s.Rank, s.Player, s.Score)
+		}
+	})
+}
+
+// printUsername prints the user we're logged as
+func printUsername(username string) error {
+	return printOutput(struct {
+		Username string `json:"username"`
+	}{username}, func(w io.Writer) {
+		fmt.Fprintf(w, "Username: %s\n", username)
+	}, nil)
+}
+
+// scoresString returns the players of a game with their scores, e.g.
+// "ww (3), foo (0)"
+func scoresString(status *api.GameStatus) string {
+	var scores []string
+	for _, l := range api.Scoreboard(status, nil) {
+		scores = append(scores, fmt.Sprintf("%s (%d)", l.Player, l.Score))
+	}
+	return strings.Join(scores, ", ")
+}
+
+// printGames prints a list of games, with their statuses if `withStatus` is
+// true. In JSON the games whose status we couldn't get then have only the
+// fields of a game.
+func printGames(games []api.ListedGame, withStatus bool) error {
+	var v interface{}
+
+	gjs := []gameJSON{}
+	sjs := []statusJSON{}
+
+	for _, g := range games {
+		gjs = append(gjs, newGameJSON(g.Game))
+
+		if g.Status != nil {
+			sjs = append(sjs, newStatusJSON(g.Status))
+		} else {
+			sjs = append(sjs, statusJSON{gameJSON: newGameJSON(g.Game)})
+		}
+	}
+
+	v = gjs
+	if withStatus {
+		v = sjs
+	}
+
+	return printOutput(v, func(w io.Writer) {
+		fmt.Fprintln(w, "Available games:")
+		for _, g := range games {
+			if !withStatus {
+				fmt.Fprintf(w, "- %s\n", g.Game)
+			} else if g.Status == nil {
+				fmt.Fprintf(w, "- %s, unknown status\n", g.Game)
+			} else {
+				fmt.Fprintf(w, "- %s, players: %s\n", g.Status, scoresString(g.Status))
+			}
+		}
+	}, func(w io.Writer) {
+		if !withStatus {
+			fmt.Fprintln(w, "ID\tCREATED\tCREATOR\tTEASER")
+			for _, g := range gjs {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", g.ID, g.Created, g.Creator, g.Teaser)
+			}
+			return
+		}
+
+		fmt.Fprintln(w, "ID\tCREATED\tCREATOR\tTEASER\tSTATUS\tTURN\tPLAYERS")
+		for _, g := range games {
+			status, turn, players := "?", "?", ""
+			if s := g.Status; s != nil {
+				status, turn, players = s.Status, fmt.Sprint(s.Turn), scoresString(s)
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", g.Identifier,
+				g.CreationDate, g.Creator, g.Teaser, status, turn, players)
+		}
+	})
+}
+
+func newStatusJSON(status *api.GameStatus) statusJSON {
+	sj := statusJSON{
+		gameJSON: newGameJSON(status.Game),
+		Status:   status.Status,
+		Turn:     status.Turn,
+		Score:    status.Score,
+		Players:  status.Players,
+	}
+
+	if sp := status.Spec; sp != nil {
+		sj.Spec = &specJSON{
+			Public:        sp.Public,
+			Players:       sp.Players,
+			Pace:          sp.Pace,
+			Turns:         sp.Turns,
+			MaxPlayers:    sp.MaxPlayers,
+			MinPlayers:    sp.MinPlayers,
+			AntsPerPlayer: sp.AntsPerPlayer,
+			InitialEnergy: sp.InitialEnergy,
+			InitialAcid:   sp.InitialAcid,
+		}
+	}
+
+	return sj
+}
+
+// printStatus prints the status of a game
+func printStatus(status *api.GameStatus) error {
+	sj := newStatusJSON(status)
+
+	return printOutput(sj, func(w io.Writer) {
+		fmt.Fprintf(w, "%s\n", status)
+	}, func(w io.Writer) {
+		fmt.Fprintf(w, "ID\t%s\n", sj.ID)
+		fmt.Fprintf(w, "Created\t%s\n", sj.Created)
+		fmt.Fprintf(w, "Creator\t%s\n", sj.Creator)
+		fmt.Fprintf(w, "Teaser\t%s\n", sj.Teaser)
+		fmt.Fprintf(w, "Status\t%s\n", sj.Status)
+		fmt.Fprintf(w, "Turn\t%d\n", sj.Turn)
+
+		var users []string
+		for user := range sj.Score {
+			users = append(users, user)
+		}
+		sort.Strings(users)
+
+		for _, user := range users {
+			fmt.Fprintf(w, "Score of %s\t%d\n", user, sj.Score[user])
+		}
+	})
+}
+
+// printGameAction prints that we did something on a game, e.g. "created"
+func printGameAction(id api.GameID, action string) error {
+	return printOutput(gameJSON{ID: id}, func(w io.Writer) {
+		fmt.Fprintf(w, "Game %s successfully %s\n", id, action)
+	}, func(w io.Writer) {
+		fmt.Fprintf(w, "ID\n%s\n", id)
+	})
+}
+
+// printTurn prints the turn we got after playing
+func printTurn(t *api.Turn) error {
+	tj := newTurnJSON(t)
+
+	return printOutput(tj, func(w io.Writer) {
+		fmt.Fprintf(w, "%s\n", t)
+	}, func(w io.Writer) {
+		fmt.Fprintf(w, "Turn %d\n\n", tj.Turn)
+		fmt.Fprintln(w, "ANT\tX\tY\tDX\tDY\tBRAIN\tENERGY\tACID\tSEEN CELLS\tSEEN ANTS")
+		for _, a := range tj.Ants {
+			fmt.Fprintf(w, "%d\t%d\t%d\t%d\t%d\t%s\t%d\t%d\t%d\t%d\n", a.ID, a.X,
+				a.Y, a.DX, a.DY, a.Brain, a.Energy, a.Acid, len(a.Vision),
+				len(a.VisibleAnts))
+		}
+	})
+}
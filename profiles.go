@@ -0,0 +1,68 @@
+package main
+
+// This file implements the `profiles` subcommand, which shows the game spec
+// profiles (see `api/profiles.go`) with all the fields they inherit:
+//
+//     antroid profiles
+//     antroid --profile duel create
+
+import (
+	"fmt"
+	"github.com/bfontaine/antroid/api"
+)
+
+// gameSpec returns the game spec of the flags, with the fields they don't set
+// taken from the profile. Only the commands which use a spec call it, so that
+// the other ones work with a broken profiles file; it exits on error.
+func gameSpec() api.GameSpec {
+	gs := api.GameSpec{
+		Description:   *gameDesc,
+		Pace:          *pace,
+		Turns:         *turns,
+		AntsPerPlayer: *ants,
+		MaxPlayers:    *maxP,
+		MinPlayers:    *minP,
+		InitialEnergy: *energy,
+		InitialAcid:   *acid,
+		Players:       *players,
+	}
+
+	profiles, err := api.LoadProfiles(*profFile)
+	if err != nil {
+		exitErr(err)
+	}
+
+	base, err := profiles.Spec(*profile)
+	if err != nil {
+		exitErr(err)
+	}
+
+	gs.Inherit(base)
+
+	return gs
+}
+
+// printProfiles prints all profiles and the problems of their spec, if any
+func printProfiles(profiles api.Profiles) {
+	for _, name := range profiles.Names() {
+		fmt.Print(name)
+
+		if p := profiles[name]; p != nil && p.Inherits != "" {
+			fmt.Printf(" (inherits %s)", p.Inherits)
+		}
+
+		gs, err := profiles.Spec(name)
+		if err != nil {
+			fmt.Printf("\n  error: %v\n", err)
+			continue
+		}
+
+		fmt.Printf("\n  %s\n", gs)
+
+		if errs, ok := gs.Check().(api.SpecErrors); ok {
+			for _, e := range errs {
+				fmt.Printf("  invalid: %v\n", e)
+			}
+		}
+	}
+}
@@ -0,0 +1,262 @@
+package main
+
+// This file implements the `render` subcommand, which draws a game as an
+// image. It can either read a turn from a journal recorded with
+// `antroid server --journal` or made from the log of a game (`/log`), draw
+// the whole journal as an animated GIF, or be used as a listener plugin to
+// render a live game at each turn:
+//
+//     antroid server --gui "antroid render --live -o game.png" ai/ant.rb
+//     antroid render --game 42 --animate -o game.gif
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"github.com/bfontaine/antroid/api"
+	"github.com/bfontaine/antroid/render"
+	"image"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// writeImage draws a scene in a file. The image is first written in a
+// temporary file which then replaces the output, so that a viewer never sees
+// a partial image.
+func writeImage(output, format string, s *render.Scene, opts render.Options) error {
+	tmp, err := ioutil.TempFile(filepath.Dir(output), ".antroid-render")
+	if err != nil {
+		return err
+	}
+
+	// temporary files are only readable by their owner
+	if err = tmp.Chmod(0644); err == nil {
+		err = render.Write(tmp, format, s, opts)
+	}
+
+	if err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	if err = tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), output)
+}
+
+// openJournal opens a journal file, or returns stdin if the filename is empty
+func openJournal(filename string) (io.ReadCloser, error) {
+	if filename == "" {
+		return ioutil.NopCloser(os.Stdin), nil
+	}
+
+	return os.Open(filename)
+}
+
+// gameLogJournal returns the journal of a game made from its log, as a player
+// saw it
+func gameLogJournal(cl *api.Client, id api.GameID, player string) (io.Reader, error) {
+	gl, err := cl.GetGameIdentifierLog(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	w := api.NewJournalWriter(&buf)
+
+	for _, e := range gl.Journal(player) {
+		if err = w.Write(e); err != nil {
+			return nil, err
+		}
+	}
+
+	return &buf, nil
+}
+
+// renderCommand runs the `render` subcommand. The journal is made from the
+// log of the game given with `--game`, which needs a logged client, or read
+// from `--journal`.
+func renderCommand(cl *api.Client) error {
+	format := *renderFormat
+	if format == "" {
+		format = render.FormatFromFilename(*renderOutput)
+	}
+
+	opts := render.Options{CellSize: *renderCellSize, Overlay: *renderOverlay}
+
+	if *renderLiveMode {
+		if *renderGame != "" {
+			return fmt.Errorf("--live can't draw the log of a game")
+		}
+		return renderLive(*renderOutput, format, opts)
+	}
+
+	var journal io.Reader
+
+	if *renderGame != "" {
+		player := *renderAs
+		if player == "" {
+			player = cl.Username()
+		}
+
+		j, err := gameLogJournal(cl, api.GameID(*renderGame), player)
+		if err != nil {
+			return err
+		}
+		journal = j
+	} else {
+		f, err := openJournal(*renderJournalFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		journal = f
+	}
+
+	if *renderAnimate {
+		return renderAnimation(journal, *renderOutput, *renderDelay,
+			*renderFollow, *renderWindow, opts)
+	}
+
+	return renderJournal(journal, *renderTurn, *renderOutput, format, opts)
+}
+
+// renderJournal draws a turn of a journal. If `turn` is 0 the last one is
+// drawn.
+func renderJournal(journal io.Reader, turn int, output, format string,
+	opts render.Options) error {
+
+	r := api.NewJournalReader(journal)
+
+	var entry *api.JournalEntry
+
+	for {
+		e, err := r.Next()
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		entry = e
+
+		if turn != 0 && e.Turn >= turn {
+			break
+		}
+	}
+
+	if entry == nil || (turn != 0 && entry.Turn != turn) {
+		return fmt.Errorf("Turn %d not found in the journal", turn)
+	}
+
+	return writeImage(output, format, render.NewSceneFromJournal(entry, r.Map), opts)
+}
+
+// renderLive reads messages from the game server on stdin and draws each turn
+// in the output file, until the game ends.
+func renderLive(output, format string, opts render.Options) error {
+	stdin := bufio.NewReader(os.Stdin)
+
+	for {
+		m, err := api.ReadListenerMessage(stdin)
+
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err = writeImage(output, format, render.NewSceneFromMessage(m), opts); err != nil {
+			return err
+		}
+
+		if !m.Playing {
+			return nil
+		}
+	}
+}
+
+// renderAnimation draws all the turns of a journal as an animated GIF. If
+// `follow` is positive or zero the animation follows the ant with this ID,
+// showing a square of `window` cells around it.
+func renderAnimation(journal io.Reader, output string, delay, follow, window int,
+	opts render.Options) error {
+
+	// GIFs can't have a shorter delay
+	if delay < 10 {
+		return fmt.Errorf("the delay must be at least 10ms, got %dms", delay)
+	}
+
+	// we read the journal twice, so we keep it in memory
+	content, err := ioutil.ReadAll(journal)
+	if err != nil {
+		return err
+	}
+
+	// first pass: find the final dimensions of the map, so that all frames
+	// show the same cells.
+	r := api.NewJournalReader(bytes.NewReader(content))
+	for {
+		if _, err = r.Next(); err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+
+	opts.Overlay = true
+	opts.Cells = image.Rect(0, 0, r.Map.Width(), r.Map.Height())
+
+	// the delay is given in milliseconds but GIFs use 100ths of a second; we
+	// round it up
+	anim := render.NewAnimation(opts, (delay+9)/10)
+
+	// second pass: draw each turn
+	r = api.NewJournalReader(bytes.NewReader(content))
+	for {
+		e, err := r.Next()
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		if follow >= 0 {
+			for _, a := range e.Ants {
+				if a.ID == follow {
+					anim.Options.Cells = render.CellsAround(a.Pos, window)
+					break
+				}
+			}
+		}
+
+		anim.AddFrame(render.NewSceneFromJournal(e, r.Map))
+	}
+
+	if anim.Len() == 0 {
+		return fmt.Errorf("The journal is empty")
+	}
+
+	out, err := os.Create(output)
+	if err != nil {
+		return err
+	}
+
+	if err = anim.WriteGIF(out); err != nil {
+		out.Close()
+		return err
+	}
+
+	return out.Close()
+}
@@ -0,0 +1,114 @@
+package render
+
+// This file describes a tiny bitmap font used to write the turn number and the
+// scoreboard on images. The standard library doesn't come with any font so we
+// define one here. Each glyph is 3 pixels wide and 5 pixels high; lowercase
+// letters are drawn as uppercase ones and unknown characters as '?'.
+
+import (
+	"image"
+	"image/color"
+	"strings"
+)
+
+// glyph dimensions, in pixels, without scaling
+const (
+	glyphWidth  = 3
+	glyphHeight = 5
+	// space between two glyphs or two lines
+	glyphSpacing = 1
+)
+
+// all glyphs, row by row
+var glyphs = map[rune][glyphHeight]string{
+	'A': {".#.", "#.#", "###", "#.#", "#.#"},
+	'B': {"##.", "#.#", "##.", "#.#", "##."},
+	'C': {".##", "#..", "#..", "#..", ".##"},
+	'D': {"##.", "#.#", "#.#", "#.#", "##."},
+	'E': {"###", "#..", "##.", "#..", "###"},
+	'F': {"###", "#..", "##.", "#..", "#.."},
+	'G': {".##", "#..", "#.#", "#.#", ".##"},
+	'H': {"#.#", "#.#", "###", "#.#", "#.#"},
+	'I': {"###", ".#.", ".#.", ".#.", "###"},
+	'J': {"..#", "..#", "..#", "#.#", ".#."},
+	'K': {"#.#", "#.#", "##.", "#.#", "#.#"},
+	'L': {"#..", "#..", "#..", "#..", "###"},
+	'M': {"#.#", "###", "###", "#.#", "#.#"},
+	'N': {"##.", "#.#", "#.#", "#.#", "#.#"},
+	'O': {".#.", "#.#", "#.#", "#.#", ".#."},
+	'P': {"##.", "#.#", "##.", "#..", "#.."},
+	'Q': {".#.", "#.#", "#.#", "##.", ".##"},
+	'R': {"##.", "#.#", "##.", "#.#", "#.#"},
+	'S': {".##", "#..", ".#.", "..#", "##."},
+	'T': {"###", ".#.", ".#.", ".#.", ".#."},
+	'U': {"#.#", "#.#", "#.#", "#.#", "###"},
+	'V': {"#.#", "#.#", "#.#", "#.#", ".#."},
+	'W': {"#.#", "#.#", "###", "###", "#.#"},
+	'X': {"#.#", "#.#", ".#.", "#.#", "#.#"},
+	'Y': {"#.#", "#.#", ".#.", ".#.", ".#."},
+	'Z': {"###", "..#", ".#.", "#..", "###"},
+	'0': {"###", "#.#", "#.#", "#.#", "###"},
+	'1': {".#.", "##.", ".#.", ".#.", "###"},
+	'2': {"##.", "..#", ".#.", "#..", "###"},
+	'3': {"##.", "..#", ".#.", "..#", "##."},
+	'4': {"#.#", "#.#", "###", "..#", "..#"},
+	'5': {"###", "#..", "##.", "..#", "##."},
+	'6': {".##", "#..", "###", "#.#", "###"},
+	'7': {"###", "..#", ".#.", ".#.", ".#."},
+	'8': {"###", "#.#", "###", "#.#", "###"},
+	'9': {"###", "#.#", "###", "..#", "##."},
+	' ': {"...", "...", "...", "...", "..."},
+	':': {"...", ".#.", "...", ".#.", "..."},
+	'.': {"...", "...", "...", "...", ".#."},
+	'-': {"...", "...", "###", "...", "..."},
+	'_': {"...", "...", "...", "...", "###"},
+	'+': {"...", ".#.", "###", ".#.", "..."},
+	'/': {"..#", "..#", ".#.", "#..", "#.."},
+	'(': {".#.", "#..", "#..", "#..", ".#."},
+	')': {".#.", "..#", "..#", "..#", ".#."},
+	'#': {"#.#", "###", "#.#", "###", "#.#"},
+	'?': {"##.", "..#", ".#.", "...", ".#."},
+}
+
+// textWidth returns the width of a text, in pixels, at the given scale
+func textWidth(text string, scale int) int {
+	n := len([]rune(text))
+	if n == 0 {
+		return 0
+	}
+
+	return (n*(glyphWidth+glyphSpacing) - glyphSpacing) * scale
+}
+
+// lineHeight returns the height of a line of text, in pixels, at the given
+// scale
+func lineHeight(scale int) int {
+	return (glyphHeight + glyphSpacing) * scale
+}
+
+// drawText writes a text on an image with its top-left corner at the given
+// point.
+func drawText(img *image.RGBA, at image.Point, text string, scale int, c color.Color) {
+	x := at.X
+
+	for _, r := range strings.ToUpper(text) {
+		g, ok := glyphs[r]
+		if !ok {
+			g = glyphs['?']
+		}
+
+		for gy, row := range g {
+			for gx, px := range row {
+				if px != '#' {
+					continue
+				}
+
+				fillRect(img, image.Rect(
+					x+gx*scale, at.Y+gy*scale,
+					x+(gx+1)*scale, at.Y+(gy+1)*scale), c)
+			}
+		}
+
+		x += (glyphWidth + glyphSpacing) * scale
+	}
+}
@@ -0,0 +1,94 @@
+package render
+
+// This file draws a list of scenes as an animated GIF. Frames are drawn as
+// soon as they're added because scenes built from a journal share the same
+// map, which changes as we read the journal.
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"io"
+)
+
+// gifPalette contains all the colors we use
+var gifPalette = func() color.Palette {
+	p := color.Palette{unknownColor, antColor, enemyColor, arrowColor, textColor, markColor}
+
+	for _, colors := range palette {
+		p = append(p, colors[0], colors[1])
+	}
+
+	return p
+}()
+
+// An Animation is a list of frames we write as an animated GIF
+type Animation struct {
+	// the drawing options of all frames
+	Options Options
+	// the delay between two frames, in 100ths of a second
+	Delay int
+
+	frames []*image.Paletted
+}
+
+// NewAnimation returns a pointer on a new, empty, animation
+func NewAnimation(opts Options, delay int) *Animation {
+	return &Animation{Options: opts, Delay: delay}
+}
+
+// AddFrame draws a scene as a new frame of the animation
+func (a *Animation) AddFrame(s *Scene) {
+	img := Image(s, a.Options)
+	frame := image.NewPaletted(img.Bounds(), gifPalette)
+
+	draw.Draw(frame, frame.Bounds(), img, image.Point{}, draw.Src)
+
+	a.frames = append(a.frames, frame)
+}
+
+// Len returns the number of frames
+func (a *Animation) Len() int {
+	return len(a.frames)
+}
+
+// WriteGIF writes the animation as a GIF image on the writer. The map grows as
+// we discover it so frames don't all have the same size; the smaller ones are
+// padded with unknown cells.
+func (a *Animation) WriteGIF(w io.Writer) error {
+	var width, height int
+
+	for _, f := range a.frames {
+		if f.Bounds().Dx() > width {
+			width = f.Bounds().Dx()
+		}
+		if f.Bounds().Dy() > height {
+			height = f.Bounds().Dy()
+		}
+	}
+
+	anim := &gif.GIF{
+		Config: image.Config{
+			ColorModel: gifPalette,
+			Width:      width,
+			Height:     height,
+		},
+	}
+
+	bounds := image.Rect(0, 0, width, height)
+
+	for _, f := range a.frames {
+		if !f.Bounds().Eq(bounds) {
+			padded := image.NewPaletted(bounds, gifPalette)
+			// the index 0 is the color of unknown cells
+			draw.Draw(padded, f.Bounds(), f, image.Point{}, draw.Src)
+			f = padded
+		}
+
+		anim.Image = append(anim.Image, f)
+		anim.Delay = append(anim.Delay, a.Delay)
+	}
+
+	return gif.EncodeAll(w, anim)
+}
@@ -0,0 +1,219 @@
+package render
+
+// This file draws scenes as raster images. We only use the standard library
+// so all the drawing primitives (discs, lines, text) are implemented here.
+
+import (
+	"github.com/bfontaine/antroid/api"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+)
+
+// fillRect fills a rectangle with a color
+func fillRect(img *image.RGBA, r image.Rectangle, c color.Color) {
+	draw.Draw(img, r, &image.Uniform{C: c}, image.Point{}, draw.Src)
+}
+
+// fillDisc fills a disc inscribed in a rectangle
+func fillDisc(img *image.RGBA, r image.Rectangle, c color.Color) {
+	drawDisc(img, r, c, false)
+}
+
+// strokeDisc draws the outline of a disc inscribed in a rectangle
+func strokeDisc(img *image.RGBA, r image.Rectangle, c color.Color) {
+	drawDisc(img, r, c, true)
+}
+
+// drawDisc draws a disc inscribed in a rectangle, either filled or not
+func drawDisc(img *image.RGBA, r image.Rectangle, c color.Color, outline bool) {
+	// we work with doubled coordinates to get the center right on even sizes
+	cx, cy := r.Min.X+r.Max.X-1, r.Min.Y+r.Max.Y-1
+	radius := r.Dx() - 1
+	inner := radius - 2
+
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			dx, dy := 2*x-cx, 2*y-cy
+			d := dx*dx + dy*dy
+
+			if d > radius*radius || (outline && inner > 0 && d < inner*inner) {
+				continue
+			}
+
+			img.Set(x, y, c)
+		}
+	}
+}
+
+// drawLine draws a line between two points using Bresenham's algorithm
+func drawLine(img *image.RGBA, from, to image.Point, c color.Color) {
+	dx, dy := to.X-from.X, to.Y-from.Y
+	sx, sy := 1, 1
+
+	if dx < 0 {
+		dx, sx = -dx, -1
+	}
+	if dy < 0 {
+		dy, sy = -dy, -1
+	}
+
+	err := dx - dy
+	x, y := from.X, from.Y
+
+	for {
+		img.Set(x, y, c)
+
+		if x == to.X && y == to.Y {
+			return
+		}
+
+		e2 := 2 * err
+
+		if e2 > -dy {
+			err -= dy
+			x += sx
+		}
+		if e2 < dx {
+			err += dx
+			y += sy
+		}
+	}
+}
+
+// arrow returns the start and the end of an ant's direction arrow in a cell.
+// The arrow starts at the cell's center and goes toward the ant's direction.
+func arrow(r image.Rectangle, dir api.Direction) (from, to image.Point) {
+	size := r.Dx()
+	reach := (size - 1) / 2
+
+	from = image.Point{X: r.Min.X + size/2, Y: r.Min.Y + size/2}
+	to = image.Point{
+		X: from.X + dir.X*reach,
+		// the Y axis is flipped
+		Y: from.Y - dir.Y*reach,
+	}
+
+	return
+}
+
+// clampDirection returns the direction of an ant with offsets between -1 and
+// 1, so that arrows stay in their cell.
+func clampDirection(a Ant) api.Direction {
+	clamp := func(n int) int {
+		if n < 0 {
+			return -1
+		}
+		if n > 0 {
+			return 1
+		}
+		return 0
+	}
+
+	return api.Direction{X: clamp(a.Dir.X), Y: clamp(a.Dir.Y)}
+}
+
+// the scale of the overlay's text
+const overlayScale = 2
+
+// Image draws the scene on a new image
+func Image(s *Scene, opts Options) *image.RGBA {
+	img := drawMap(s, s.view(opts))
+
+	if opts.Overlay {
+		img = addOverlay(img, s.overlayLines())
+	}
+
+	return img
+}
+
+// drawMap draws the map and the ants in the view on a new image
+func drawMap(s *Scene, v view) *image.RGBA {
+	img := image.NewRGBA(v.bounds())
+
+	// map
+	for y := v.y; y < v.y+v.height; y++ {
+		for x := v.x; x < v.x+v.width; x++ {
+			c := s.Map.Cell(x, y)
+			r := v.cellRect(x, y)
+			bg, fg := cellColors(c)
+
+			fillRect(img, r, bg)
+
+			if c != nil && api.IsFood(c.Content) {
+				fillDisc(img, r, fg)
+			}
+		}
+	}
+
+	// the AIs' paths and marks, below the ants
+	for _, a := range s.Annotations {
+		switch a.Kind {
+		case "path":
+			for i := 1; i < len(a.Positions); i++ {
+				drawLine(img, v.cellCenter(a.Positions[i-1]),
+					v.cellCenter(a.Positions[i]), markColor)
+			}
+		case "mark":
+			if len(a.Positions) == 0 {
+				continue
+			}
+
+			strokeDisc(img, v.cellRect(a.Positions[0].X, a.Positions[0].Y), markColor)
+		}
+	}
+
+	// ants. The ones out of the view are drawn out of the image, which does
+	// nothing.
+	for _, a := range s.Ants {
+		r := v.cellRect(a.Pos.X, a.Pos.Y)
+
+		if a.Lost {
+			strokeDisc(img, r, colorOf(a))
+			continue
+		}
+
+		fillDisc(img, r, colorOf(a))
+
+		if dir := clampDirection(a); dir.X != 0 || dir.Y != 0 {
+			from, to := arrow(r, dir)
+			drawLine(img, from, to, arrowColor)
+		}
+	}
+
+	return img
+}
+
+// addOverlay returns a new image with the given lines of text above the
+// original one
+func addOverlay(img *image.RGBA, lines []string) *image.RGBA {
+	padding := 2 * overlayScale
+	width := img.Bounds().Dx()
+
+	for _, line := range lines {
+		if w := textWidth(line, overlayScale) + 2*padding; w > width {
+			width = w
+		}
+	}
+
+	band := len(lines)*lineHeight(overlayScale) + padding
+
+	out := image.NewRGBA(image.Rect(0, 0, width, band+img.Bounds().Dy()))
+	fillRect(out, out.Bounds(), unknownColor)
+
+	for i, line := range lines {
+		at := image.Point{X: padding, Y: padding + i*lineHeight(overlayScale)}
+		drawText(out, at, line, overlayScale, textColor)
+	}
+
+	draw.Draw(out, img.Bounds().Add(image.Point{Y: band}), img, image.Point{}, draw.Src)
+
+	return out
+}
+
+// WritePNG draws the scene as a PNG image on the writer
+func WritePNG(w io.Writer, s *Scene, opts Options) error {
+	return png.Encode(w, Image(s, opts))
+}
@@ -0,0 +1,280 @@
+// Package render draws the state of a game as an image. It can draw a partial
+// map as we know it during a game or a full map, along with our ants and the
+// enemy ones. Images can be written as PNG or SVG.
+package render
+
+// This file describes what we draw (a `Scene`) and how we build it from a
+// journal entry or a game server message. See `render/image.go` and
+// `render/svg.go` for the drawing itself.
+
+import (
+	"fmt"
+	"github.com/bfontaine/antroid/api"
+	"image"
+	"image/color"
+	"io"
+	"sort"
+	"strings"
+)
+
+// An Ant is an ant we draw on the map
+type Ant struct {
+	// its position and direction
+	Pos api.Position
+	Dir api.Direction
+	// its ID. For enemies this is the ID given by the tracker (see
+	// `api/tracker.go`), or -1 if we don't know it.
+	ID int
+	// true if it's not one of ours
+	Enemy bool
+	// true if it's an enemy we lost sight of, drawn at its last known
+	// position
+	Lost bool
+}
+
+// A Scene is everything we draw
+type Scene struct {
+	// the map. For partial maps unknown cells are drawn in black.
+	Map api.MapInterface
+	// all the ants, ours and the enemy ones
+	Ants []Ant
+	// the turn number
+	Turn int
+	// the scoreboard (map username => score)
+	Score map[string]int
+	// the annotations sent by the AIs (see `api/annotations.go`). Marks and
+	// paths are drawn on the map, logs are written in the overlay.
+	Annotations []api.Annotation
+}
+
+// Options are the drawing options
+type Options struct {
+	// the size of a cell, in pixels
+	CellSize int
+	// if true, the turn number and the scoreboard are written on top of the
+	// map
+	Overlay bool
+	// if it's not empty, only draw these cells instead of the whole map. They
+	// are given in map coordinates, e.g. image.Rect(0, 0, 10, 10) is the 10x10
+	// square at the bottom-left of the map.
+	Cells image.Rectangle
+}
+
+// CellsAround returns a square of cells centered on a position, e.g. to
+// follow an ant.
+func CellsAround(p api.Position, size int) image.Rectangle {
+	x, y := p.X-size/2, p.Y-size/2
+	return image.Rect(x, y, x+size, y+size)
+}
+
+// DefaultOptions are the options used if none are given
+var DefaultOptions = Options{CellSize: 8}
+
+// NewSceneFromJournal returns a new scene for a journal entry. `m` must be the
+// map as rebuilt by the journal reader after this entry.
+func NewSceneFromJournal(e *api.JournalEntry, m api.MapInterface) *Scene {
+	s := &Scene{Map: m, Turn: e.Turn, Score: e.Score, Annotations: e.Annotations}
+
+	for _, a := range e.Ants {
+		s.Ants = append(s.Ants, Ant{Pos: a.Pos, Dir: a.Dir, ID: a.ID})
+	}
+
+	for _, a := range e.Enemies {
+		s.Ants = append(s.Ants, Ant{
+			Pos:   a.Pos,
+			Dir:   a.Dir,
+			ID:    a.ID,
+			Enemy: true,
+			Lost:  !a.Visible,
+		})
+	}
+
+	return s
+}
+
+// NewSceneFromMessage returns a new scene for a message from the game server
+func NewSceneFromMessage(m *api.TurnMessage) *Scene {
+	s := &Scene{Map: m.Map, Turn: m.Turn, Annotations: m.Annotations}
+
+	for _, a := range m.Ants {
+		s.Ants = append(s.Ants, Ant{Pos: a.Pos, Dir: a.Dir, ID: a.ID})
+	}
+
+	for _, a := range m.Enemies {
+		s.Ants = append(s.Ants, Ant{Pos: a.Pos, Dir: a.Dir, ID: a.ID, Enemy: true})
+	}
+
+	return s
+}
+
+// The colors we use. They're the same as the ones in `gui/antroidGUI.py`:
+// each content has a color for the cells we remember and a brighter one for
+// the ones we see at this turn.
+var (
+	palette = map[string][2]color.RGBA{
+		"grass": {rgb(0, 100, 0), rgb(0, 255, 0)},         // dark green, green
+		"sugar": {rgb(211, 211, 211), rgb(255, 250, 250)}, // light grey, snow
+		"rock":  {rgb(105, 105, 105), rgb(190, 190, 190)}, // dim gray, gray
+		"mill":  {rgb(255, 140, 0), rgb(255, 255, 0)},     // dark orange, yellow
+		"water": {rgb(25, 25, 112), rgb(0, 0, 255)},       // midnight blue, blue
+		"meat":  {rgb(160, 82, 45), rgb(255, 99, 71)},     // sienna, tomato
+	}
+
+	unknownColor = rgb(0, 0, 0)
+	antColor     = rgb(30, 144, 255) // dodger blue
+	enemyColor   = rgb(139, 0, 0)    // red4
+	arrowColor   = rgb(255, 255, 255)
+	textColor    = rgb(255, 255, 255)
+	markColor    = rgb(255, 0, 255) // magenta
+)
+
+func rgb(r, g, b uint8) color.RGBA { return color.RGBA{R: r, G: g, B: b, A: 255} }
+
+// cellColors returns the background and foreground colors of a cell. They are
+// the same for all contents except food, which is drawn as a disc on grass.
+func cellColors(c *api.Cell) (bg, fg color.RGBA) {
+	if c == nil {
+		return unknownColor, unknownColor
+	}
+
+	visibility := 0
+	if c.Visibility {
+		visibility = 1
+	}
+
+	colors, ok := palette[c.Content]
+	if !ok {
+		colors = palette["grass"]
+	}
+
+	fg = colors[visibility]
+	bg = fg
+
+	if api.IsFood(c.Content) {
+		bg = palette["grass"][visibility]
+	}
+
+	return
+}
+
+// colorOf returns the color of an ant
+func colorOf(a Ant) color.RGBA {
+	if a.Enemy {
+		return enemyColor
+	}
+	return antColor
+}
+
+// A view is the part of the map we draw, in cells
+type view struct {
+	// the bottom-left cell
+	x, y int
+	// the dimensions
+	width, height int
+	// the size of a cell, in pixels
+	size int
+}
+
+// view returns the part of the map we draw with the given options. An empty
+// map is drawn as one unknown cell.
+func (s *Scene) view(opts Options) (v view) {
+	v.size = opts.CellSize
+	if v.size < 1 {
+		v.size = DefaultOptions.CellSize
+	}
+
+	if !opts.Cells.Empty() {
+		v.x, v.y = opts.Cells.Min.X, opts.Cells.Min.Y
+		v.width, v.height = opts.Cells.Dx(), opts.Cells.Dy()
+		return
+	}
+
+	v.width, v.height = s.Map.Width(), s.Map.Height()
+
+	if v.width < 1 {
+		v.width = 1
+	}
+	if v.height < 1 {
+		v.height = 1
+	}
+
+	return
+}
+
+// cellRect returns the rectangle of the cell at (x,y) in the view, in pixels.
+// The map's origin is at the bottom-left (see `api/maps.go`), while images
+// have theirs at the top-left, so we flip the Y axis.
+func (v view) cellRect(x, y int) image.Rectangle {
+	left := (x - v.x) * v.size
+	top := (v.y + v.height - 1 - y) * v.size
+	return image.Rect(left, top, left+v.size, top+v.size)
+}
+
+// cellCenter returns the center of the cell at the given position, in pixels
+func (v view) cellCenter(p api.Position) image.Point {
+	r := v.cellRect(p.X, p.Y)
+	return image.Point{X: r.Min.X + v.size/2, Y: r.Min.Y + v.size/2}
+}
+
+// bounds returns the dimensions of the view, in pixels
+func (v view) bounds() image.Rectangle {
+	return image.Rect(0, 0, v.width*v.size, v.height*v.size)
+}
+
+// overlayLines returns the lines of text we write on top of the map: the turn
+// number, the scoreboard, sorted by decreasing score, and the AIs' logs.
+func (s *Scene) overlayLines() []string {
+	lines := []string{fmt.Sprintf("Turn %d", s.Turn)}
+
+	var players []string
+	for username := range s.Score {
+		players = append(players, username)
+	}
+
+	sort.Slice(players, func(i, j int) bool {
+		si, sj := s.Score[players[i]], s.Score[players[j]]
+		if si != sj {
+			return si > sj
+		}
+		return players[i] < players[j]
+	})
+
+	for _, username := range players {
+		lines = append(lines, fmt.Sprintf("%s: %d", username, s.Score[username]))
+	}
+
+	for _, a := range s.Annotations {
+		if a.Kind == "log" {
+			lines = append(lines, fmt.Sprintf("AI %d: %s", a.AI, a.Text))
+		}
+	}
+
+	return lines
+}
+
+// Formats we support
+const (
+	PNG = "png"
+	SVG = "svg"
+)
+
+// FormatFromFilename guesses an image format from a filename. It defaults to
+// PNG.
+func FormatFromFilename(filename string) string {
+	if strings.HasSuffix(strings.ToLower(filename), "."+SVG) {
+		return SVG
+	}
+	return PNG
+}
+
+// Write draws the scene in the given format on the writer
+func Write(w io.Writer, format string, s *Scene, opts Options) error {
+	switch format {
+	case PNG:
+		return WritePNG(w, s, opts)
+	case SVG:
+		return WriteSVG(w, s, opts)
+	}
+
+	return fmt.Errorf("Unknown image format: %s", format)
+}
@@ -0,0 +1,252 @@
+package render
+
+import (
+	"bytes"
+	"github.com/bfontaine/antroid/api"
+	"github.com/franela/goblin"
+	o "github.com/onsi/gomega"
+	"image"
+	"image/gif"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+// newTestScene returns a 3x2 scene with one ant of ours and one enemy
+func newTestScene() *Scene {
+	m := api.NewPartialMap()
+	m.SetCell(&api.Cell{Pos: api.Position{X: 0, Y: 0}, Content: "grass", Visibility: true})
+	m.SetCell(&api.Cell{Pos: api.Position{X: 1, Y: 0}, Content: "water"})
+	m.SetCell(&api.Cell{Pos: api.Position{X: 2, Y: 1}, Content: "sugar", Visibility: true})
+
+	return &Scene{
+		Map: m,
+		Ants: []Ant{
+			{Pos: api.Position{X: 0, Y: 0}, Dir: api.Direction{X: 1, Y: 0}},
+			{Pos: api.Position{X: 2, Y: 1}, Dir: api.Direction{X: 0, Y: -1}, Enemy: true},
+		},
+		Turn: 3,
+	}
+}
+
+func TestRender(t *testing.T) {
+
+	g := goblin.Goblin(t)
+
+	o.RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	g.Describe("FormatFromFilename", func() {
+		g.It("Should return SVG for .svg files", func() {
+			o.Expect(FormatFromFilename("foo.SVG")).To(o.Equal(SVG))
+		})
+
+		g.It("Should default to PNG", func() {
+			o.Expect(FormatFromFilename("foo")).To(o.Equal(PNG))
+		})
+	})
+
+	g.Describe("NewSceneFromJournal", func() {
+		g.It("Should add our ants and the enemy ones", func() {
+			e := &api.JournalEntry{
+				Turn: 2,
+				Ants: []api.AntStatus{{ID: 1}},
+				Enemies: []api.TrackedAnt{
+					{ID: 4, Visible: true},
+					{ID: 5},
+				},
+			}
+
+			s := NewSceneFromJournal(e, api.NewPartialMap())
+
+			o.Expect(s.Turn).To(o.Equal(2))
+			o.Expect(s.Ants).To(o.HaveLen(3))
+			o.Expect(s.Ants[0].Enemy).To(o.BeFalse())
+			o.Expect(s.Ants[1].Enemy).To(o.BeTrue())
+			o.Expect(s.Ants[1].Lost).To(o.BeFalse())
+			o.Expect(s.Ants[2].Lost).To(o.BeTrue())
+		})
+	})
+
+	g.Describe("Image", func() {
+		g.It("Should have one square per cell", func() {
+			img := Image(newTestScene(), Options{CellSize: 10})
+			o.Expect(img.Bounds().Dx()).To(o.Equal(30))
+			o.Expect(img.Bounds().Dy()).To(o.Equal(20))
+		})
+
+		g.It("Should draw one cell for an empty map", func() {
+			img := Image(&Scene{Map: api.NewPartialMap()}, Options{CellSize: 10})
+			o.Expect(img.Bounds().Dx()).To(o.Equal(10))
+		})
+
+		g.It("Should draw unknown cells in black", func() {
+			img := Image(newTestScene(), Options{CellSize: 10})
+			// (0,1) is at the top-left
+			o.Expect(img.RGBAAt(1, 1)).To(o.Equal(unknownColor))
+		})
+
+		g.It("Should draw remembered cells with their dark color", func() {
+			img := Image(newTestScene(), Options{CellSize: 10})
+			// (1,0) is at the bottom-middle
+			o.Expect(img.RGBAAt(11, 11)).To(o.Equal(palette["water"][0]))
+		})
+
+		g.It("Should draw food on grass", func() {
+			img := Image(newTestScene(), Options{CellSize: 10})
+			// (2,1) is at the top-right
+			o.Expect(img.RGBAAt(20, 0)).To(o.Equal(palette["grass"][1]))
+		})
+
+		g.It("Should draw ants", func() {
+			img := Image(newTestScene(), Options{CellSize: 10})
+			o.Expect(img.RGBAAt(3, 13)).To(o.Equal(antColor))
+			o.Expect(img.RGBAAt(23, 3)).To(o.Equal(enemyColor))
+		})
+	})
+
+	g.Describe("Image with annotations", func() {
+		g.It("Should draw the AIs' paths", func() {
+			s := newTestScene()
+			s.Annotations = []api.Annotation{{
+				Kind:      "path",
+				Positions: []api.Position{{X: 0, Y: 0}, {X: 1, Y: 0}},
+			}}
+
+			img := Image(s, Options{CellSize: 10})
+			o.Expect(img.RGBAAt(12, 15)).To(o.Equal(markColor))
+		})
+
+		g.It("Should draw the AIs' marks", func() {
+			s := newTestScene()
+			s.Annotations = []api.Annotation{{
+				Kind:      "mark",
+				Positions: []api.Position{{X: 1, Y: 0}},
+			}}
+
+			img := Image(s, Options{CellSize: 10})
+			o.Expect(img.RGBAAt(11, 15)).To(o.Equal(markColor))
+			o.Expect(img.RGBAAt(15, 15)).To(o.Equal(palette["water"][0]))
+		})
+	})
+
+	g.Describe("Image with options", func() {
+		g.It("Should only draw the given cells", func() {
+			opts := Options{CellSize: 10, Cells: image.Rect(1, 0, 3, 1)}
+			img := Image(newTestScene(), opts)
+
+			o.Expect(img.Bounds().Dx()).To(o.Equal(20))
+			o.Expect(img.Bounds().Dy()).To(o.Equal(10))
+			o.Expect(img.RGBAAt(1, 1)).To(o.Equal(palette["water"][0]))
+		})
+
+		g.It("Should add the overlay above the map", func() {
+			s := newTestScene()
+			s.Score = map[string]int{"foo": 12, "bar": 3}
+
+			img := Image(s, Options{CellSize: 10, Overlay: true})
+			band := 3*lineHeight(overlayScale) + 2*overlayScale
+
+			o.Expect(img.Bounds().Dy()).To(o.Equal(20 + band))
+			o.Expect(img.RGBAAt(1, band+1)).To(o.Equal(unknownColor))
+		})
+	})
+
+	g.Describe("CellsAround", func() {
+		g.It("Should return a square centered on the position", func() {
+			r := CellsAround(api.Position{X: 10, Y: 4}, 5)
+			o.Expect(r).To(o.Equal(image.Rect(8, 2, 13, 7)))
+		})
+	})
+
+	g.Describe("Scene", func() {
+		g.Describe(".overlayLines()", func() {
+			g.It("Should sort the scoreboard by decreasing score", func() {
+				s := &Scene{Turn: 7, Score: map[string]int{"a": 1, "b": 5}}
+				o.Expect(s.overlayLines()).To(o.Equal([]string{
+					"Turn 7", "b: 5", "a: 1",
+				}))
+			})
+
+			g.It("Should add the AIs' logs", func() {
+				s := &Scene{Turn: 7, Annotations: []api.Annotation{
+					{AI: 1, Kind: "log", Text: "hello"},
+					{AI: 0, Kind: "mark", Positions: []api.Position{{}}},
+				}}
+				o.Expect(s.overlayLines()).To(o.Equal([]string{
+					"Turn 7", "AI 1: hello",
+				}))
+			})
+		})
+	})
+
+	g.Describe("textWidth", func() {
+		g.It("Should return 0 for an empty text", func() {
+			o.Expect(textWidth("", 2)).To(o.Equal(0))
+		})
+
+		g.It("Should count the spacing between glyphs", func() {
+			o.Expect(textWidth("ab", 1)).To(o.Equal(7))
+		})
+	})
+
+	g.Describe("Animation", func() {
+		g.It("Should write all frames with the same size", func() {
+			anim := NewAnimation(Options{CellSize: 4}, 10)
+
+			anim.AddFrame(&Scene{Map: api.NewPartialMap()})
+			anim.AddFrame(newTestScene())
+
+			var buf bytes.Buffer
+			o.Expect(anim.WriteGIF(&buf)).To(o.BeNil())
+
+			decoded, err := gif.DecodeAll(&buf)
+			o.Expect(err).To(o.BeNil())
+			o.Expect(decoded.Image).To(o.HaveLen(2))
+			o.Expect(decoded.Delay).To(o.Equal([]int{10, 10}))
+			o.Expect(decoded.Image[0].Bounds()).To(o.Equal(decoded.Image[1].Bounds()))
+		})
+	})
+
+	g.Describe("WritePNG", func() {
+		g.It("Should write a valid PNG image", func() {
+			var buf bytes.Buffer
+			o.Expect(WritePNG(&buf, newTestScene(), DefaultOptions)).To(o.BeNil())
+
+			_, err := png.Decode(&buf)
+			o.Expect(err).To(o.BeNil())
+		})
+	})
+
+	g.Describe("WriteSVG", func() {
+		g.It("Should write an SVG image", func() {
+			var buf bytes.Buffer
+			o.Expect(WriteSVG(&buf, newTestScene(), DefaultOptions)).To(o.BeNil())
+
+			svg := buf.String()
+			o.Expect(strings.HasPrefix(svg, "<svg ")).To(o.BeTrue())
+			o.Expect(strings.Count(svg, "<line ")).To(o.Equal(2))
+		})
+
+		g.It("Should draw the AIs' paths and marks", func() {
+			s := newTestScene()
+			s.Annotations = []api.Annotation{
+				{Kind: "path", Positions: []api.Position{{X: 0, Y: 0}, {X: 1, Y: 0}}},
+				{Kind: "mark", Positions: []api.Position{{X: 1, Y: 0}}, Text: "a<b"},
+			}
+
+			var buf bytes.Buffer
+			o.Expect(WriteSVG(&buf, s, Options{CellSize: 10})).To(o.BeNil())
+
+			svg := buf.String()
+			o.Expect(svg).To(o.ContainSubstring(`<polyline points="5,15 15,15"`))
+			o.Expect(svg).To(o.ContainSubstring("<title>a&lt;b</title>"))
+		})
+	})
+
+	g.Describe("Write", func() {
+		g.It("Should fail on unknown formats", func() {
+			var buf bytes.Buffer
+			o.Expect(Write(&buf, "bmp", newTestScene(), DefaultOptions)).NotTo(o.BeNil())
+		})
+	})
+}
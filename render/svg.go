@@ -0,0 +1,152 @@
+package render
+
+// This file draws scenes as SVG images. Each cell is a `rect`, food and ants
+// are `circle`s and the ants' directions are `line`s. The AIs' paths are
+// `polyline`s and their marks `circle`s with their label as a `title`. The
+// overlay is written with `text` elements.
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/bfontaine/antroid/api"
+	"html"
+	"image/color"
+	"io"
+	"strings"
+)
+
+// the size of the overlay's font, in pixels, and the width of one of its
+// characters. We use a monospace font so we know the width of a line.
+const (
+	svgFontSize  = 12
+	svgCharWidth = 8
+)
+
+// hex returns the hexadecimal notation of a color, e.g. "#00ff00"
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// WriteSVG draws the scene as an SVG image on the writer
+func WriteSVG(w io.Writer, s *Scene, opts Options) error {
+	v := s.view(opts)
+	half := float64(v.size) / 2
+
+	var lines []string
+	var band int
+
+	width, height := v.bounds().Dx(), v.bounds().Dy()
+
+	if opts.Overlay {
+		lines = s.overlayLines()
+		band = (len(lines) + 1) * svgFontSize
+
+		for _, line := range lines {
+			if w := (len(line) + 2) * svgCharWidth; w > width {
+				width = w
+			}
+		}
+	}
+
+	buf := bufio.NewWriter(w)
+
+	fmt.Fprintf(buf, `<svg xmlns="http://www.w3.org/2000/svg" `+
+		`width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		width, band+height, width, band+height)
+
+	// background, for the unknown cells
+	fmt.Fprintf(buf, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n",
+		hex(unknownColor))
+
+	// overlay
+	for i, line := range lines {
+		fmt.Fprintf(buf, `<text x="%d" y="%d" font-family="monospace" `+
+			`font-size="%d" fill="%s">%s</text>`+"\n",
+			svgCharWidth, (i+1)*svgFontSize, svgFontSize, hex(textColor),
+			html.EscapeString(line))
+	}
+
+	// the map is drawn below the overlay
+	fmt.Fprintf(buf, `<g transform="translate(0 %d)">`+"\n", band)
+
+	// map
+	for y := v.y; y < v.y+v.height; y++ {
+		for x := v.x; x < v.x+v.width; x++ {
+			c := s.Map.Cell(x, y)
+			if c == nil {
+				continue
+			}
+
+			r := v.cellRect(x, y)
+			bg, fg := cellColors(c)
+
+			fmt.Fprintf(buf, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"/>`+"\n",
+				r.Min.X, r.Min.Y, v.size, v.size, hex(bg))
+
+			if api.IsFood(c.Content) {
+				fmt.Fprintf(buf, `<circle cx="%g" cy="%g" r="%g" fill="%s"/>`+"\n",
+					float64(r.Min.X)+half, float64(r.Min.Y)+half, half, hex(fg))
+			}
+		}
+	}
+
+	// the AIs' paths and marks, below the ants
+	for _, a := range s.Annotations {
+		switch a.Kind {
+		case "path":
+			var points []string
+			for _, p := range a.Positions {
+				r := v.cellRect(p.X, p.Y)
+				points = append(points, fmt.Sprintf("%g,%g",
+					float64(r.Min.X)+half, float64(r.Min.Y)+half))
+			}
+
+			fmt.Fprintf(buf, `<polyline points="%s" fill="none" stroke="%s"/>`+"\n",
+				strings.Join(points, " "), hex(markColor))
+
+		case "mark":
+			if len(a.Positions) == 0 {
+				continue
+			}
+
+			r := v.cellRect(a.Positions[0].X, a.Positions[0].Y)
+
+			fmt.Fprintf(buf, `<circle cx="%g" cy="%g" r="%g" fill="none" stroke="%s">`+
+				`<title>%s</title></circle>`+"\n",
+				float64(r.Min.X)+half, float64(r.Min.Y)+half, half-0.5,
+				hex(markColor), html.EscapeString(a.Text))
+		}
+	}
+
+	// ants
+	for _, a := range s.Ants {
+		if a.Pos.X < v.x || a.Pos.X >= v.x+v.width ||
+			a.Pos.Y < v.y || a.Pos.Y >= v.y+v.height {
+			continue
+		}
+
+		r := v.cellRect(a.Pos.X, a.Pos.Y)
+		cx, cy := float64(r.Min.X)+half, float64(r.Min.Y)+half
+
+		if a.Lost {
+			fmt.Fprintf(buf, `<circle cx="%g" cy="%g" r="%g" fill="none" stroke="%s"/>`+"\n",
+				cx, cy, half-0.5, hex(colorOf(a)))
+			continue
+		}
+
+		fmt.Fprintf(buf, `<circle cx="%g" cy="%g" r="%g" fill="%s"/>`+"\n",
+			cx, cy, half, hex(colorOf(a)))
+
+		if dir := clampDirection(a); dir.X != 0 || dir.Y != 0 {
+			// the Y axis is flipped
+			fmt.Fprintf(buf, `<line x1="%g" y1="%g" x2="%g" y2="%g" stroke="%s"/>`+"\n",
+				cx, cy, cx+float64(dir.X)*half, cy-float64(dir.Y)*half,
+				hex(arrowColor))
+		}
+	}
+
+	fmt.Fprintln(buf, "</g>")
+	fmt.Fprintln(buf, "</svg>")
+
+	return buf.Flush()
+}
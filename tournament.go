@@ -0,0 +1,43 @@
+package main
+
+// This file implements the `tournament` subcommand, which runs a tournament
+// described in a JSON file (see `tournament/config.go`) and prints the
+// standings:
+//
+//     antroid tournament tournament.json
+
+import (
+	"github.com/bfontaine/antroid/api"
+	"github.com/bfontaine/antroid/tournament"
+	"os"
+)
+
+// runTournament runs the tournament described in a configuration file
+func runTournament(path, defaultPassword string, gs api.GameSpec, debug bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	c, err := tournament.ReadConfig(f)
+	f.Close()
+
+	if err != nil {
+		return err
+	}
+
+	t, err := tournament.New(c, gs, defaultPassword, os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	t.SetDebug(debug)
+
+	err = t.Run()
+
+	// print the standings even if we can't save the ratings
+	os.Stdout.WriteString("\nStandings:\n")
+	t.PrintStandings()
+
+	return err
+}
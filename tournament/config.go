@@ -0,0 +1,141 @@
+// Package tournament runs tournaments between AIs. Each entry is an account
+// with its own AIs; entries play two-player private games against each other
+// (see `api.Match`) in round-robin or Swiss pairings. We keep the standings of
+// the tournament and persistent Elo ratings across tournaments.
+package tournament
+
+// This file describes the tournament configuration, which is a JSON file like
+// this one:
+//
+//     {
+//       "format": "round-robin",
+//       "repetitions": 2,
+//       "spec": {"turns": 100, "antsPerPlayer": 2},
+//       "ratings": "ratings.json",
+//       "journals": "journals",
+//       "entries": [
+//         {"name": "ant", "login": "ant-bot", "password": "a", "ais": ["ai/ant.rb 2"]},
+//         {"name": "scout", "ais": ["ai/scout.scm", "tcp://10.0.0.2:9000"]}
+//       ]
+//     }
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/bfontaine/antroid/api"
+	"io"
+)
+
+// tournament formats
+const (
+	RoundRobin = "round-robin"
+	Swiss      = "swiss"
+)
+
+// An Entry is a contestant of the tournament
+type Entry struct {
+	// its name in the standings and the ratings. It defaults to its login.
+	Name string `json:"name"`
+	// the credentials of its account. The login defaults to its name and the
+	// password to the one given to the tournament.
+	Login    string `json:"login"`
+	Password string `json:"password"`
+	// its AIs: commands or remote endpoints
+	AIs []string `json:"ais"`
+}
+
+// A Config describes a tournament
+type Config struct {
+	// "round-robin" (the default) or "swiss"
+	Format string `json:"format"`
+	// the number of rounds of a Swiss tournament. It defaults to the number
+	// of entries minus one.
+	Rounds int `json:"rounds"`
+	// the number of games each pair of entries play in a round-robin
+	// tournament, or in each round of a Swiss one. It defaults to 1.
+	Repetitions int `json:"repetitions"`
+	// the parameters of the games. Fields left to zero are taken from the
+	// command-line. The players are always the two entries of the game.
+	Spec api.GameSpec `json:"spec"`
+	// the file in which we keep the Elo ratings, if any
+	Ratings string `json:"ratings"`
+	// the directory in which we record the journal of each game, if any
+	Journals string `json:"journals"`
+	// the contestants
+	Entries []Entry `json:"entries"`
+}
+
+// ReadConfig reads and checks a tournament configuration
+func ReadConfig(r io.Reader) (*Config, error) {
+	var c Config
+
+	if err := json.NewDecoder(r).Decode(&c); err != nil {
+		return nil, err
+	}
+
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// check sets the default values and checks the configuration
+func (c *Config) check() error {
+	if c.Format == "" {
+		c.Format = RoundRobin
+	}
+
+	if c.Format != RoundRobin && c.Format != Swiss {
+		return fmt.Errorf("unknown tournament format %q", c.Format)
+	}
+
+	if len(c.Entries) < 2 {
+		return fmt.Errorf("a tournament needs at least two entries")
+	}
+
+	if c.Repetitions <= 0 {
+		c.Repetitions = 1
+	}
+
+	if c.Rounds <= 0 {
+		c.Rounds = len(c.Entries) - 1
+	}
+
+	names := make(map[string]bool)
+	logins := make(map[string]bool)
+
+	for i := range c.Entries {
+		e := &c.Entries[i]
+
+		if e.Name == "" {
+			e.Name = e.Login
+		}
+		if e.Login == "" {
+			e.Login = e.Name
+		}
+
+		if e.Name == "" {
+			return fmt.Errorf("entry %d doesn't have any name nor login", i)
+		}
+		if len(e.AIs) == 0 {
+			return fmt.Errorf("entry %s doesn't have any AI", e.Name)
+		}
+		if names[e.Name] || logins[e.Login] {
+			return fmt.Errorf("entry %s is not unique", e.Name)
+		}
+
+		names[e.Name] = true
+		logins[e.Login] = true
+	}
+
+	return nil
+}
+
+// spec returns the spec of the games, using `base` for the fields that are
+// not in the configuration
+func (c *Config) spec(base api.GameSpec) api.GameSpec {
+	gs := c.Spec
+	gs.Inherit(base)
+	return gs
+}
@@ -0,0 +1,64 @@
+package tournament
+
+import (
+	"github.com/bfontaine/antroid/api"
+	"github.com/franela/goblin"
+	o "github.com/onsi/gomega"
+	"strings"
+	"testing"
+)
+
+func TestConfig(t *testing.T) {
+
+	g := goblin.Goblin(t)
+
+	o.RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	read := func(s string) (*Config, error) {
+		return ReadConfig(strings.NewReader(s))
+	}
+
+	g.Describe("ReadConfig", func() {
+		g.It("Should set the default values", func() {
+			c, err := read(`{"entries": [
+				{"name": "a", "ais": ["ai/ant.rb"]},
+				{"login": "b", "ais": ["tcp://localhost:9000"]},
+				{"name": "c", "ais": ["ai/scout.scm"]}
+			]}`)
+
+			o.Expect(err).To(o.BeNil())
+			o.Expect(c.Format).To(o.Equal(RoundRobin))
+			o.Expect(c.Repetitions).To(o.Equal(1))
+			o.Expect(c.Rounds).To(o.Equal(2))
+			o.Expect(c.Entries[0].Login).To(o.Equal("a"))
+			o.Expect(c.Entries[1].Name).To(o.Equal("b"))
+		})
+
+		g.It("Should read the game spec", func() {
+			c, err := read(`{"spec": {"turns": 42, "antsPerPlayer": 3}, "entries": [
+				{"name": "a", "ais": ["x"]}, {"name": "b", "ais": ["y"]}
+			]}`)
+
+			o.Expect(err).To(o.BeNil())
+
+			gs := c.spec(api.GameSpec{Pace: 2, Turns: 10, AntsPerPlayer: 1})
+			o.Expect(gs.Turns).To(o.Equal(42))
+			o.Expect(gs.AntsPerPlayer).To(o.Equal(3))
+			o.Expect(gs.Pace).To(o.Equal(2))
+		})
+
+		g.It("Should reject bad configurations", func() {
+			for _, s := range []string{
+				`{`,
+				`{"entries": [{"name": "a", "ais": ["x"]}]}`,
+				`{"format": "knockout", "entries": [{"name": "a", "ais": ["x"]}, {"name": "b", "ais": ["y"]}]}`,
+				`{"entries": [{"name": "a", "ais": ["x"]}, {"name": "b"}]}`,
+				`{"entries": [{"name": "a", "ais": ["x"]}, {"ais": ["y"]}]}`,
+				`{"entries": [{"name": "a", "ais": ["x"]}, {"name": "a", "ais": ["y"]}]}`,
+			} {
+				_, err := read(s)
+				o.Expect(err).NotTo(o.BeNil())
+			}
+		})
+	})
+}
@@ -0,0 +1,97 @@
+package tournament
+
+// This file describes the Elo ratings we keep across tournaments. They're
+// saved in a JSON file mapping each entry's name to its rating.
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"os"
+)
+
+const (
+	// the rating of new entries
+	initialRating = 1500
+	// the maximum change of a rating after one game
+	kFactor = 32
+)
+
+// A Rating is the Elo rating of an entry
+type Rating struct {
+	Rating float64 `json:"rating"`
+	// the number of games it played since it's rated
+	Games int `json:"games"`
+}
+
+// Ratings are the ratings of all entries, indexed by their name
+type Ratings map[string]*Rating
+
+// LoadRatings reads the ratings saved in a file. It returns empty ratings if
+// the file doesn't exist.
+func LoadRatings(path string) (Ratings, error) {
+	r := make(Ratings)
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err = json.Unmarshal(data, &r); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Save writes the ratings in a file
+func (r Ratings) Save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Get returns the rating of an entry, which is created if it's not rated yet
+func (r Ratings) Get(name string) *Rating {
+	if _, ok := r[name]; !ok {
+		r[name] = &Rating{Rating: initialRating}
+	}
+
+	return r[name]
+}
+
+// Of returns the rating of an entry, or the initial rating if it's not rated
+// yet. Unlike `Get` it doesn't create it.
+func (r Ratings) Of(name string) float64 {
+	if rating, ok := r[name]; ok {
+		return rating.Rating
+	}
+
+	return initialRating
+}
+
+// expected returns the expected score of a player rated `a` against a player
+// rated `b`, between 0 and 1
+func expected(a, b float64) float64 {
+	return 1 / (1 + math.Pow(10, (b-a)/400))
+}
+
+// Update updates the ratings after a game between `a` and `b`. `score` is
+// the score of `a`: 1 if it won, 0.5 for a draw and 0 if it lost.
+func (r Ratings) Update(a, b string, score float64) {
+	ra, rb := r.Get(a), r.Get(b)
+
+	ea := expected(ra.Rating, rb.Rating)
+
+	ra.Rating += kFactor * (score - ea)
+	rb.Rating += kFactor * ((1 - score) - (1 - ea))
+
+	ra.Games++
+	rb.Games++
+}
@@ -0,0 +1,67 @@
+package tournament
+
+import (
+	"github.com/franela/goblin"
+	o "github.com/onsi/gomega"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestElo(t *testing.T) {
+
+	g := goblin.Goblin(t)
+
+	o.RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	g.Describe("Ratings", func() {
+		g.It("Should give the initial rating to new entries", func() {
+			r := make(Ratings)
+			o.Expect(r.Get("a").Rating).To(o.Equal(float64(initialRating)))
+		})
+
+		g.It("Should move the ratings after a game", func() {
+			r := make(Ratings)
+			r.Update("a", "b", 1)
+
+			o.Expect(r["a"].Rating).To(o.Equal(1516.0))
+			o.Expect(r["b"].Rating).To(o.Equal(1484.0))
+			o.Expect(r["a"].Games).To(o.Equal(1))
+		})
+
+		g.It("Should not move equal ratings after a draw", func() {
+			r := make(Ratings)
+			r.Update("a", "b", 0.5)
+
+			o.Expect(r["a"].Rating).To(o.Equal(1500.0))
+		})
+
+		g.It("Should win less points against a weaker entry", func() {
+			r := Ratings{"a": {Rating: 1700}, "b": {Rating: 1500}}
+			r.Update("a", "b", 1)
+
+			o.Expect(r["a"].Rating).To(o.BeNumerically("<", 1716))
+			o.Expect(r["a"].Rating + r["b"].Rating).To(o.BeNumerically("~", 3200, 1e-9))
+		})
+
+		g.It("Should be saved and loaded", func() {
+			dir, err := ioutil.TempDir("", "antroid-elo")
+			o.Expect(err).To(o.BeNil())
+			defer os.RemoveAll(dir)
+
+			path := filepath.Join(dir, "ratings.json")
+
+			r, err := LoadRatings(path)
+			o.Expect(err).To(o.BeNil())
+			o.Expect(r).To(o.BeEmpty())
+
+			r.Update("a", "b", 0)
+			o.Expect(r.Save(path)).To(o.BeNil())
+
+			loaded, err := LoadRatings(path)
+			o.Expect(err).To(o.BeNil())
+			o.Expect(loaded).To(o.Equal(r))
+		})
+	})
+}
@@ -0,0 +1,89 @@
+package tournament
+
+// This file describes how we pair entries, i.e. who plays against who.
+
+// pairKey returns the key of a pair of entries in the count of the games they
+// played against each other, regardless of their order
+func pairKey(a, b int) [2]int {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]int{a, b}
+}
+
+// roundRobin returns all the pairs of `n` entries, `reps` times. Entries
+// take turns being the first player.
+func roundRobin(n, reps int) (pairs [][2]int) {
+	for r := 0; r < reps; r++ {
+		for a := 0; a < n; a++ {
+			for b := a + 1; b < n; b++ {
+				if r%2 == 0 {
+					pairs = append(pairs, [2]int{a, b})
+				} else {
+					pairs = append(pairs, [2]int{b, a})
+				}
+			}
+		}
+	}
+
+	return
+}
+
+// swissPairs returns the pairs of a Swiss round. `order` is the entries
+// sorted by their standing, from the first one. Each entry plays against the
+// next one in the standings it didn't play yet, or the next one if it already
+// played all of them. If there's an odd number of entries, the lowest-ranked
+// one which didn't get a bye yet (see `byes`) gets it, or the last one if
+// they all got one; it's -1 otherwise.
+func swissPairs(order []int, played map[[2]int]int, byes map[int]bool) (pairs [][2]int, bye int) {
+	paired := make(map[int]bool)
+	bye = -1
+
+	// choose the bye before pairing the others
+	if len(order)%2 == 1 {
+		bye = order[len(order)-1]
+
+		for i := len(order) - 1; i >= 0; i-- {
+			if !byes[order[i]] {
+				bye = order[i]
+				break
+			}
+		}
+
+		paired[bye] = true
+	}
+
+	for i, a := range order {
+		if paired[a] {
+			continue
+		}
+
+		opponent := -1
+
+		for _, b := range order[i+1:] {
+			if paired[b] {
+				continue
+			}
+
+			if opponent < 0 {
+				opponent = b
+			}
+
+			if played[pairKey(a, b)] == 0 {
+				opponent = b
+				break
+			}
+		}
+
+		// can't happen: we removed the bye
+		if opponent < 0 {
+			break
+		}
+
+		paired[a] = true
+		paired[opponent] = true
+		pairs = append(pairs, [2]int{a, opponent})
+	}
+
+	return
+}
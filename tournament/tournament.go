@@ -0,0 +1,324 @@
+package tournament
+
+// This file describes the tournament runner. Games are played one after the
+// other; an entry whose AIs or account fail during a game loses it, so a
+// crashed bot doesn't stop the tournament.
+
+import (
+	"fmt"
+	"github.com/bfontaine/antroid/api"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+)
+
+// A Standing is the record of an entry in the tournament
+type Standing struct {
+	Entry string
+	// the number of games it played, won, drew and lost
+	Played, Wins, Draws, Losses int
+	// the number of games it lost because it failed
+	Crashes int
+	// 1 point per win and per bye, 0.5 per draw
+	Points float64
+}
+
+// A Result is the result of a game between two entries
+type Result struct {
+	// their score, from `GameStatus.Score`
+	Scores [2]int
+	// the error each one got, if any
+	Errors [2]error
+}
+
+// A Tournament is a tournament between several entries
+type Tournament struct {
+	config *Config
+	// the spec of all games
+	spec api.GameSpec
+	// the password of entries which don't have one
+	password string
+	debug    bool
+
+	ratings   Ratings
+	standings []*Standing
+	// the number of games played between each pair of entries, see pairKey
+	played map[[2]int]int
+	// the entries which got a bye in a Swiss tournament
+	byes map[int]bool
+	// the number of games we played
+	games int
+
+	// where we print the results
+	out io.Writer
+
+	// plays the game `n` between two entries. It's `.playGame` but can be
+	// replaced in tests.
+	play func(n int, a, b *Entry) Result
+}
+
+// New returns a pointer on a new tournament from its configuration. `base`
+// gives the game parameters which are not in the configuration and
+// `password` the password of entries which don't have one. It loads the
+// ratings and creates the journals directory if there are any.
+func New(c *Config, base api.GameSpec, password string, out io.Writer) (*Tournament, error) {
+	t := &Tournament{
+		config:   c,
+		spec:     c.spec(base),
+		password: password,
+		played:   make(map[[2]int]int),
+		byes:     make(map[int]bool),
+		out:      out,
+	}
+
+	t.play = t.playGame
+
+	for _, e := range c.Entries {
+		t.standings = append(t.standings, &Standing{Entry: e.Name})
+	}
+
+	var err error
+
+	if c.Ratings != "" {
+		if t.ratings, err = LoadRatings(c.Ratings); err != nil {
+			return nil, err
+		}
+	} else {
+		t.ratings = make(Ratings)
+	}
+
+	if c.Journals != "" {
+		if err = os.MkdirAll(c.Journals, 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+// SetDebug enables/disables the debug mode of the players
+func (t *Tournament) SetDebug(debug bool) {
+	t.debug = debug
+}
+
+// Ratings returns the Elo ratings of the entries
+func (t *Tournament) Ratings() Ratings {
+	return t.ratings
+}
+
+// Run plays all the games of the tournament. It only fails if it can't save
+// the ratings.
+func (t *Tournament) Run() error {
+	if t.config.Format == Swiss {
+		for round := 1; round <= t.config.Rounds; round++ {
+			fmt.Fprintf(t.out, "Round %d\n", round)
+
+			pairs, bye := swissPairs(t.order(), t.played, t.byes)
+
+			if bye >= 0 {
+				t.byes[bye] = true
+				t.standings[bye].Points++
+				fmt.Fprintf(t.out, "%s gets a bye\n", t.config.Entries[bye].Name)
+			}
+
+			for r := 0; r < t.config.Repetitions; r++ {
+				for _, pair := range pairs {
+					if err := t.game(pair[0], pair[1]); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		return nil
+	}
+
+	for _, pair := range roundRobin(len(t.config.Entries), t.config.Repetitions) {
+		if err := t.game(pair[0], pair[1]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// game plays a game between two entries and records its result
+func (t *Tournament) game(a, b int) error {
+	t.games++
+
+	ea, eb := &t.config.Entries[a], &t.config.Entries[b]
+	res := t.play(t.games, ea, eb)
+
+	var score float64
+
+	switch {
+	case res.Errors[0] != nil && res.Errors[1] != nil:
+		// we don't know who's responsible, e.g. the remote server is down
+		fmt.Fprintf(t.out, "Game %d: %s vs %s failed: %s\n", t.games,
+			ea.Name, eb.Name, res.Errors[0])
+		return nil
+
+	case res.Errors[0] != nil:
+		t.standings[a].Crashes++
+		fmt.Fprintf(t.out, "Game %d: %s wins, %s failed: %s\n", t.games,
+			eb.Name, ea.Name, res.Errors[0])
+
+	case res.Errors[1] != nil:
+		score = 1
+		t.standings[b].Crashes++
+		fmt.Fprintf(t.out, "Game %d: %s wins, %s failed: %s\n", t.games,
+			ea.Name, eb.Name, res.Errors[1])
+
+	default:
+		if res.Scores[0] > res.Scores[1] {
+			score = 1
+		} else if res.Scores[0] == res.Scores[1] {
+			score = 0.5
+		}
+
+		fmt.Fprintf(t.out, "Game %d: %s %d - %d %s\n", t.games,
+			ea.Name, res.Scores[0], res.Scores[1], eb.Name)
+	}
+
+	// only the games with a result count when we pair Swiss rounds
+	t.played[pairKey(a, b)]++
+
+	t.record(t.standings[a], score)
+	t.record(t.standings[b], 1-score)
+
+	t.ratings.Update(ea.Name, eb.Name, score)
+
+	if t.config.Ratings != "" {
+		return t.ratings.Save(t.config.Ratings)
+	}
+
+	return nil
+}
+
+// record adds a game to a standing, given the score of its entry
+func (t *Tournament) record(s *Standing, score float64) {
+	s.Played++
+	s.Points += score
+
+	switch score {
+	case 1:
+		s.Wins++
+	case 0:
+		s.Losses++
+	default:
+		s.Draws++
+	}
+}
+
+// order returns the index of all entries sorted by their standing: first by
+// points, then by rating
+func (t *Tournament) order() []int {
+	order := make([]int, len(t.standings))
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		si, sj := t.standings[order[i]], t.standings[order[j]]
+		if si.Points != sj.Points {
+			return si.Points > sj.Points
+		}
+		return t.ratings.Of(si.Entry) > t.ratings.Of(sj.Entry)
+	})
+
+	return order
+}
+
+// Standings returns the standings, from the first entry to the last one
+func (t *Tournament) Standings() []Standing {
+	var standings []Standing
+
+	for _, i := range t.order() {
+		standings = append(standings, *t.standings[i])
+	}
+
+	return standings
+}
+
+// PrintStandings prints the standings as a table
+func (t *Tournament) PrintStandings() {
+	w := tabwriter.NewWriter(t.out, 0, 0, 2, ' ', tabwriter.AlignRight)
+
+	fmt.Fprintln(w, "#\tEntry\tPlayed\tW\tD\tL\tCrashes\tPoints\tRating\t")
+
+	for i, s := range t.Standings() {
+		fmt.Fprintf(w, "%d\t%s\t%d\t%d\t%d\t%d\t%d\t%.1f\t%.0f\t\n", i+1, s.Entry,
+			s.Played, s.Wins, s.Draws, s.Losses, s.Crashes, s.Points,
+			t.ratings.Of(s.Entry))
+	}
+
+	w.Flush()
+}
+
+// playGame plays the game `n` between two entries in a private game
+func (t *Tournament) playGame(n int, a, b *Entry) (res Result) {
+	entries := []*Entry{a, b}
+	players := make([]*api.Player, len(entries))
+
+	for i, e := range entries {
+		password := e.Password
+		if password == "" {
+			password = t.password
+		}
+
+		p := api.NewPlayer(e.Login, password)
+		p.SetDebug(t.debug)
+
+		if err := p.AIs.Load(e.AIs...); err != nil {
+			res.Errors[i] = err
+		}
+
+		if t.config.Journals != "" {
+			name := fmt.Sprintf("%03d-%s.jsonl", n, e.Name)
+
+			f, err := os.Create(filepath.Join(t.config.Journals, name))
+			if err != nil {
+				res.Errors[i] = err
+			} else {
+				defer f.Close()
+				p.SetJournal(f)
+			}
+		}
+
+		players[i] = p
+	}
+
+	defer func() {
+		for _, p := range players {
+			p.Quit()
+		}
+	}()
+
+	if res.Errors[0] != nil || res.Errors[1] != nil {
+		return
+	}
+
+	m := api.NewMatch(players...)
+
+	if err := m.Start(&t.spec); err != nil {
+		copy(res.Errors[:], m.Errors())
+
+		// the game couldn't be created, it's nobody's fault
+		if res.Errors[0] == nil && res.Errors[1] == nil {
+			res.Errors = [2]error{err, err}
+		}
+		return
+	}
+
+	m.Play()
+	copy(res.Errors[:], m.Errors())
+
+	scores := m.Scores()
+	for i, e := range entries {
+		res.Scores[i] = scores[e.Login]
+	}
+
+	return
+}
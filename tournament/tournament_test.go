@@ -0,0 +1,198 @@
+package tournament
+
+import (
+	"bytes"
+	"errors"
+	"github.com/bfontaine/antroid/api"
+	"github.com/franela/goblin"
+	o "github.com/onsi/gomega"
+	"strings"
+	"testing"
+)
+
+func TestTournament(t *testing.T) {
+
+	g := goblin.Goblin(t)
+
+	o.RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	entries := func(names ...string) (es []Entry) {
+		for _, n := range names {
+			es = append(es, Entry{Name: n, Login: n, AIs: []string{"ai"}})
+		}
+		return
+	}
+
+	// newTournament returns a tournament in which the scores of the entries
+	// are given by their name's index in `strength`
+	newTournament := func(c *Config, strength map[string]int) (*Tournament, *bytes.Buffer) {
+		var out bytes.Buffer
+
+		o.Expect(c.check()).To(o.BeNil())
+
+		tr, err := New(c, api.GameSpec{}, "", &out)
+		o.Expect(err).To(o.BeNil())
+
+		tr.play = func(n int, a, b *Entry) Result {
+			return Result{Scores: [2]int{strength[a.Name], strength[b.Name]}}
+		}
+
+		return tr, &out
+	}
+
+	g.Describe("roundRobin", func() {
+		g.It("Should pair all entries", func() {
+			o.Expect(roundRobin(3, 1)).To(o.Equal([][2]int{{0, 1}, {0, 2}, {1, 2}}))
+		})
+
+		g.It("Should swap the players on repetitions", func() {
+			o.Expect(roundRobin(2, 2)).To(o.Equal([][2]int{{0, 1}, {1, 0}}))
+		})
+	})
+
+	g.Describe("swissPairs", func() {
+		g.It("Should pair neighbours in the standings", func() {
+			pairs, bye := swissPairs([]int{2, 0, 3, 1}, nil, nil)
+
+			o.Expect(pairs).To(o.Equal([][2]int{{2, 0}, {3, 1}}))
+			o.Expect(bye).To(o.Equal(-1))
+		})
+
+		g.It("Should avoid rematches", func() {
+			played := map[[2]int]int{pairKey(0, 2): 1}
+			pairs, _ := swissPairs([]int{2, 0, 3, 1}, played, nil)
+
+			o.Expect(pairs).To(o.Equal([][2]int{{2, 3}, {0, 1}}))
+		})
+
+		g.It("Should give a bye to the last entry", func() {
+			pairs, bye := swissPairs([]int{0, 1, 2}, nil, nil)
+
+			o.Expect(pairs).To(o.HaveLen(1))
+			o.Expect(bye).To(o.Equal(2))
+		})
+
+		g.It("Should give a bye to the lowest-ranked entry without one", func() {
+			pairs, bye := swissPairs([]int{0, 1, 2}, nil, map[int]bool{2: true})
+
+			o.Expect(pairs).To(o.Equal([][2]int{{0, 2}}))
+			o.Expect(bye).To(o.Equal(1))
+		})
+	})
+
+	g.Describe("Tournament", func() {
+		g.It("Should play a round-robin tournament", func() {
+			tr, _ := newTournament(&Config{Entries: entries("a", "b", "c")},
+				map[string]int{"a": 1, "b": 3, "c": 2})
+
+			o.Expect(tr.Run()).To(o.BeNil())
+
+			standings := tr.Standings()
+			o.Expect(standings[0].Entry).To(o.Equal("b"))
+			o.Expect(standings[0].Wins).To(o.Equal(2))
+			o.Expect(standings[2].Entry).To(o.Equal("a"))
+			o.Expect(standings[2].Losses).To(o.Equal(2))
+
+			o.Expect(tr.Ratings()["b"].Rating).To(o.BeNumerically(">", 1500))
+			o.Expect(tr.Ratings()["a"].Games).To(o.Equal(2))
+		})
+
+		g.It("Should count draws", func() {
+			tr, _ := newTournament(&Config{Entries: entries("a", "b"), Repetitions: 2},
+				map[string]int{})
+
+			o.Expect(tr.Run()).To(o.BeNil())
+			o.Expect(tr.Standings()[0].Draws).To(o.Equal(2))
+			o.Expect(tr.Standings()[0].Points).To(o.Equal(1.0))
+		})
+
+		g.It("Should make crashed entries lose", func() {
+			tr, out := newTournament(&Config{Entries: entries("a", "b")},
+				map[string]int{"a": 10})
+
+			tr.play = func(n int, a, b *Entry) Result {
+				return Result{Scores: [2]int{10, 0}, Errors: [2]error{errors.New("boom"), nil}}
+			}
+
+			o.Expect(tr.Run()).To(o.BeNil())
+
+			standings := tr.Standings()
+			o.Expect(standings[0].Entry).To(o.Equal("b"))
+			o.Expect(standings[1].Crashes).To(o.Equal(1))
+			o.Expect(out.String()).To(o.ContainSubstring("a failed: boom"))
+		})
+
+		g.It("Should not count failed games", func() {
+			tr, _ := newTournament(&Config{Entries: entries("a", "b")}, nil)
+
+			err := errors.New("server down")
+			tr.play = func(n int, a, b *Entry) Result {
+				return Result{Errors: [2]error{err, err}}
+			}
+
+			o.Expect(tr.Run()).To(o.BeNil())
+			o.Expect(tr.Standings()[0].Played).To(o.Equal(0))
+			o.Expect(tr.Ratings()).To(o.BeEmpty())
+		})
+
+		g.It("Should pair again the entries of a failed game", func() {
+			tr, out := newTournament(&Config{Format: Swiss, Rounds: 2,
+				Entries: entries("a", "b")}, map[string]int{"a": 1})
+
+			err := errors.New("server down")
+			tr.play = func(n int, a, b *Entry) Result {
+				if n == 1 {
+					return Result{Errors: [2]error{err, err}}
+				}
+				return Result{Scores: [2]int{1, 0}}
+			}
+
+			o.Expect(tr.Run()).To(o.BeNil())
+			o.Expect(out.String()).To(o.ContainSubstring("failed: server down"))
+			o.Expect(tr.played[pairKey(0, 1)]).To(o.Equal(1))
+			o.Expect(tr.Standings()[0].Played).To(o.Equal(1))
+		})
+
+		g.It("Should play a Swiss tournament", func() {
+			tr, out := newTournament(&Config{Format: Swiss, Rounds: 2,
+				Entries: entries("a", "b", "c", "d")},
+				map[string]int{"a": 4, "b": 3, "c": 2, "d": 1})
+
+			o.Expect(tr.Run()).To(o.BeNil())
+			o.Expect(out.String()).To(o.ContainSubstring("Round 2"))
+
+			standings := tr.Standings()
+			o.Expect(standings[0].Entry).To(o.Equal("a"))
+			o.Expect(standings[0].Played).To(o.Equal(2))
+			o.Expect(standings[0].Points).To(o.Equal(2.0))
+		})
+
+		g.It("Should give each bye to a different entry", func() {
+			tr, out := newTournament(&Config{Format: Swiss, Rounds: 3,
+				Entries: entries("a", "b", "c")},
+				map[string]int{"a": 3, "b": 2, "c": 1})
+
+			o.Expect(tr.Run()).To(o.BeNil())
+
+			for _, name := range []string{"a", "b", "c"} {
+				o.Expect(strings.Count(out.String(), name+" gets a bye")).To(o.Equal(1))
+			}
+
+			for _, s := range tr.Standings() {
+				o.Expect(s.Played).To(o.Equal(2))
+			}
+		})
+
+		g.It("Should print the standings", func() {
+			tr, out := newTournament(&Config{Entries: entries("a", "b")},
+				map[string]int{"a": 1})
+
+			tr.Run()
+			out.Reset()
+			tr.PrintStandings()
+
+			o.Expect(out.String()).To(o.ContainSubstring("Rating"))
+			o.Expect(out.String()).To(o.MatchRegexp(`1\s+a\s+1\s+1\s+0\s+0\s+0\s+1\.0\s+1516`))
+		})
+	})
+}
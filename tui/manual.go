@@ -0,0 +1,402 @@
+package tui
+
+// This file describes the manual mode, in which we control ants from the
+// keyboard. `Manual` is an actor (see `api.ActorInterface`) we add to the AIs
+// pool: at each turn it reads the turn message like any AI, shows our ants
+// and what they see, then asks which action each ant should do. It can be
+// mixed with AIs: the ants it doesn't control are left to them.
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/bfontaine/antroid/api"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// the actions we can give to an ant, indexed by their key
+var actionKeys = map[byte]string{
+	'f': "forward",
+	'l': "left",
+	'r': "right",
+	's': "rest",
+}
+
+// the default action of ants we don't give any action to
+const defaultAction = "rest"
+
+// the number of cells shown around the selected ant
+const visionRadius = 4
+
+const manualHelp = `Keys:
+  0-9   select an ant by its ID
+  f     go forward
+  l, r  turn left or right
+  s     rest
+  u     undo the last action
+  h     show the commands of the previous turns
+  ?     show this help
+Several keys can be typed on the same line, e.g. "0f1l". An empty line
+submits the turn; ants without action rest.
+`
+
+// A Manual lets a human player control some ants from the keyboard. It
+// implements api.ActorInterface.
+type Manual struct {
+	in  *bufio.Reader
+	out io.Writer
+
+	// the IDs of the ants we control. We control all of them if it's empty.
+	ants []int
+
+	input  chan string
+	output chan string
+
+	// the commands we submitted, one per turn
+	history []string
+	// true once we can't read anything on the input. All ants rest from
+	// there.
+	eof bool
+}
+
+// NewManual returns a pointer on a new Manual which reads keys from `in` and
+// writes on `out`, usually os.Stdin and os.Stdout. It controls the ants with
+// the given IDs, or all of them if there are none.
+func NewManual(in io.Reader, out io.Writer, ants []int) *Manual {
+	return &Manual{
+		in:     bufio.NewReader(in),
+		out:    out,
+		ants:   ants,
+		input:  make(chan string),
+		output: make(chan string),
+	}
+}
+
+// Start starts the manual mode in a goroutine. It implements
+// api.ActorInterface.
+func (m *Manual) Start(wg *sync.WaitGroup) {
+	go m.start(wg)
+}
+
+// Send sends a turn message (blocking). It implements api.ActorInterface.
+func (m *Manual) Send(msg string) { m.input <- msg }
+
+// Read returns the command of the turn (blocking). It implements
+// api.ActorInterface.
+func (m *Manual) Read() string { return <-m.output }
+
+// History returns the commands we submitted, one per turn
+func (m *Manual) History() []string {
+	return m.history
+}
+
+func (m *Manual) start(wg *sync.WaitGroup) {
+	if wg != nil {
+		defer wg.Done()
+	}
+
+	for {
+		msg := <-m.input
+
+		// this is the special message the actors pools send to stop
+		if api.IsStop(msg) {
+			break
+		}
+
+		m.output <- m.play(msg)
+	}
+}
+
+// play shows a turn and asks the actions of our ants until we submit them,
+// then returns the command line
+func (m *Manual) play(msg string) string {
+	tm, err := api.ReadTurnMessage(bufio.NewReader(strings.NewReader(msg)))
+	if err != nil {
+		fmt.Fprintf(m.out, "Error: %s\n", err)
+		return "\n"
+	}
+
+	t := newManualTurn(tm, m.ants)
+
+	if len(t.ants) == 0 {
+		return "\n"
+	}
+
+	t.draw(m.out)
+
+	for !m.eof {
+		fmt.Fprint(m.out, "> ")
+
+		line, err := m.in.ReadString('\n')
+		if err != nil {
+			m.eof = true
+			if line == "" {
+				break
+			}
+		}
+
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+
+		if t.keys(line, m) {
+			t.drawAnts(m.out)
+		}
+	}
+
+	cmd := t.command()
+	m.history = append(m.history, fmt.Sprintf("turn %d: %s", tm.Turn, cmd))
+
+	return cmd + "\n"
+}
+
+// an action we gave to an ant, remembered to be able to undo it
+type manualAction struct {
+	ant      int
+	previous string
+}
+
+// manualTurn is the state of a turn in the manual mode
+type manualTurn struct {
+	msg *api.TurnMessage
+	// the ants we control, sorted by ID
+	ants []api.AntStatus
+	// the index of the selected ant in `ants`
+	selected int
+	// the action of each ant, indexed by its ID
+	actions map[int]string
+	// all the actions we gave, in order
+	done []manualAction
+}
+
+func newManualTurn(msg *api.TurnMessage, controlled []int) *manualTurn {
+	t := &manualTurn{msg: msg, actions: make(map[int]string)}
+
+	for _, a := range msg.Ants {
+		if controls(controlled, a.ID) {
+			t.ants = append(t.ants, a)
+		}
+	}
+
+	sort.Slice(t.ants, func(i, j int) bool { return t.ants[i].ID < t.ants[j].ID })
+
+	return t
+}
+
+// controls returns true if the ant with the given ID is in the list, or if
+// the list is empty
+func controls(ants []int, id int) bool {
+	if len(ants) == 0 {
+		return true
+	}
+
+	for _, a := range ants {
+		if a == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// keys handles one line of keys. It returns true if the ants changed and
+// should be redrawn.
+func (t *manualTurn) keys(line string, m *Manual) (changed bool) {
+	for i := 0; i < len(line); i++ {
+		k := line[i]
+
+		if k >= '0' && k <= '9' {
+			j := i
+			for j+1 < len(line) && line[j+1] >= '0' && line[j+1] <= '9' {
+				j++
+			}
+
+			id, _ := strconv.Atoi(line[i : j+1])
+			i = j
+
+			if !t.selectAnt(id) {
+				fmt.Fprintf(m.out, "Unknown ant %d\n", id)
+			}
+			changed = true
+			continue
+		}
+
+		if action, ok := actionKeys[k]; ok {
+			t.setAction(action)
+			changed = true
+			continue
+		}
+
+		switch k {
+		case ' ', ',':
+		case 'u':
+			if !t.undo() {
+				fmt.Fprintln(m.out, "Nothing to undo")
+			}
+			changed = true
+		case 'h':
+			if len(m.history) == 0 {
+				fmt.Fprintln(m.out, "No previous turn")
+			}
+			for _, h := range m.history {
+				fmt.Fprintln(m.out, h)
+			}
+		case '?':
+			fmt.Fprint(m.out, manualHelp)
+		default:
+			fmt.Fprintf(m.out, "Unknown key %q, type ? for help\n", k)
+		}
+	}
+
+	return
+}
+
+// selectAnt selects the ant with the given ID. It returns false if we don't
+// control it.
+func (t *manualTurn) selectAnt(id int) bool {
+	for i, a := range t.ants {
+		if a.ID == id {
+			t.selected = i
+			return true
+		}
+	}
+
+	return false
+}
+
+// setAction gives an action to the selected ant, then selects the next one
+func (t *manualTurn) setAction(action string) {
+	id := t.ants[t.selected].ID
+
+	t.done = append(t.done, manualAction{ant: id, previous: t.actions[id]})
+	t.actions[id] = action
+
+	t.selected = (t.selected + 1) % len(t.ants)
+}
+
+// undo cancels the last action and selects its ant. It returns false if
+// there's nothing to undo.
+func (t *manualTurn) undo() bool {
+	if len(t.done) == 0 {
+		return false
+	}
+
+	last := t.done[len(t.done)-1]
+	t.done = t.done[:len(t.done)-1]
+
+	if last.previous == "" {
+		delete(t.actions, last.ant)
+	} else {
+		t.actions[last.ant] = last.previous
+	}
+
+	t.selectAnt(last.ant)
+
+	return true
+}
+
+// command returns the command line for this turn, e.g. `0:forward,1:rest`
+func (t *manualTurn) command() string {
+	cmds := make([]string, len(t.ants))
+
+	for i, a := range t.ants {
+		action, ok := t.actions[a.ID]
+		if !ok {
+			action = defaultAction
+		}
+		cmds[i] = fmt.Sprintf("%d:%s", a.ID, action)
+	}
+
+	return strings.Join(cmds, ",")
+}
+
+// draw writes the turn: its header, our ants and the vision of the selected
+// one
+func (t *manualTurn) draw(w io.Writer) {
+	state := "playing"
+	if !t.msg.Playing {
+		state = "over"
+	}
+
+	fmt.Fprintf(w, "\n%sTurn %d%s (%s), %d enemies in sight, type ? for help\n",
+		bold, t.msg.Turn, reset, state, len(t.msg.Enemies))
+
+	t.drawAnts(w)
+}
+
+// drawAnts writes our ants with their action and the vision of the selected
+// one
+func (t *manualTurn) drawAnts(w io.Writer) {
+	for i, a := range t.ants {
+		cursor := " "
+		if i == t.selected {
+			cursor = ">"
+		}
+
+		action := t.actions[a.ID]
+		if action == "" {
+			action = "-"
+		}
+
+		fmt.Fprintf(w, "%s %s #%-2d %-10s energy %4d  acid %4d  %s\n",
+			cursor, heading(a.Dir), a.ID, a.Pos, a.Energy, a.Acid, action)
+	}
+
+	for _, line := range t.vision() {
+		fmt.Fprintf(w, "    %s\n", line)
+	}
+}
+
+// vision returns the lines of the map around the selected ant, from the top
+// to the bottom
+func (t *manualTurn) vision() []string {
+	center := t.ants[t.selected].Pos
+
+	ants := make(map[api.Position]string)
+
+	for _, e := range t.msg.Enemies {
+		ants[e.Pos] = bold + foreground(enemyColor) + heading(e.Dir)
+	}
+
+	for _, a := range t.msg.Ants {
+		ants[a.Pos] = bold + foreground(antColor) + heading(a.Dir)
+	}
+
+	var lines []string
+
+	for y := center.Y + visionRadius; y >= center.Y-visionRadius; y-- {
+		var line strings.Builder
+
+		for x := center.X - visionRadius; x <= center.X+visionRadius; x++ {
+			c := t.msg.Map.Cell(x, y)
+
+			if c != nil {
+				visibility := 0
+				if c.Visibility {
+					visibility = 1
+				}
+				line.WriteString(background(cellColors[c.Content][visibility]))
+			}
+
+			if ant, ok := ants[api.Position{X: x, Y: y}]; ok {
+				line.WriteString(ant)
+			} else if c != nil && api.IsFood(c.Content) {
+				line.WriteString(foreground(0) + "*")
+			} else if c == nil {
+				line.WriteString("?")
+			} else {
+				line.WriteString(" ")
+			}
+
+			line.WriteString(reset)
+		}
+
+		lines = append(lines, line.String())
+	}
+
+	return lines
+}
@@ -0,0 +1,117 @@
+package tui
+
+import (
+	"bytes"
+	"github.com/franela/goblin"
+	o "github.com/onsi/gomega"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// a turn message with three ants and a small map
+const manualMessage = "3 3 1 1\n" +
+	"0 1 1 0 1 90 100 1\n" +
+	"1 2 1 1 0 80 100 1\n" +
+	"2 3 1 0 -1 70 100 1\n" +
+	"1\n4 4 0 -1 1 0 1\n" +
+	"2 1 2\n" +
+	"1 1 0 1\n" +
+	"1 2 1 1\n" +
+	"0\n"
+
+func TestManual(t *testing.T) {
+
+	g := goblin.Goblin(t)
+
+	o.RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	// play starts a Manual with the given keys, sends it the message and
+	// returns its command along with what it wrote
+	play := func(keys string, ants ...int) (string, string) {
+		var out bytes.Buffer
+		m := NewManual(strings.NewReader(keys), &out, ants)
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		m.Start(&wg)
+
+		m.Send(manualMessage)
+		cmd := m.Read()
+
+		m.Send("STOP")
+		wg.Wait()
+
+		return cmd, out.String()
+	}
+
+	g.Describe("Manual", func() {
+		g.It("Should make ants rest by default", func() {
+			cmd, _ := play("\n")
+			o.Expect(cmd).To(o.Equal("0:rest,1:rest,2:rest\n"))
+		})
+
+		g.It("Should select the next ant after each action", func() {
+			cmd, _ := play("fl\n\n")
+			o.Expect(cmd).To(o.Equal("0:forward,1:left,2:rest\n"))
+		})
+
+		g.It("Should select ants by their ID", func() {
+			cmd, _ := play("2r\n0 f\n\n")
+			o.Expect(cmd).To(o.Equal("0:forward,1:rest,2:right\n"))
+		})
+
+		g.It("Should undo the last actions", func() {
+			cmd, _ := play("fff\nuu\nr\n\n")
+			o.Expect(cmd).To(o.Equal("0:forward,1:right,2:rest\n"))
+		})
+
+		g.It("Should restore the previous action of an ant on undo", func() {
+			cmd, _ := play("0f0l\nu\n\n")
+			o.Expect(cmd).To(o.Equal("0:forward,1:rest,2:rest\n"))
+		})
+
+		g.It("Should only control the given ants", func() {
+			cmd, out := play("1f\n\n", 2, 1)
+			o.Expect(cmd).To(o.Equal("1:forward,2:rest\n"))
+			o.Expect(out).NotTo(o.ContainSubstring("#0 "))
+		})
+
+		g.It("Should submit the turn at the end of the input", func() {
+			cmd, _ := play("f")
+			o.Expect(cmd).To(o.Equal("0:forward,1:rest,2:rest\n"))
+		})
+
+		g.It("Should show the ants and their action", func() {
+			_, out := play("0s\n\n")
+			o.Expect(out).To(o.ContainSubstring("Turn 3"))
+			o.Expect(out).To(o.ContainSubstring("#0  (1, 1)"))
+			o.Expect(out).To(o.ContainSubstring("energy   90"))
+			o.Expect(out).To(o.ContainSubstring("rest"))
+		})
+
+		g.It("Should complain about unknown ants and keys", func() {
+			_, out := play("7z\n\n")
+			o.Expect(out).To(o.ContainSubstring("Unknown ant 7"))
+			o.Expect(out).To(o.ContainSubstring("Unknown key 'z'"))
+		})
+
+		g.It("Should remember the commands of the previous turns", func() {
+			var out bytes.Buffer
+			m := NewManual(strings.NewReader("f\n\nh\n\n"), &out, nil)
+
+			m.Start(nil)
+			m.Send(manualMessage)
+			m.Read()
+			m.Send(manualMessage)
+			m.Read()
+			m.Send("STOP")
+
+			o.Expect(m.History()).To(o.Equal([]string{
+				"turn 3: 0:forward,1:rest,2:rest",
+				"turn 3: 0:rest,1:rest,2:rest",
+			}))
+			o.Expect(out.String()).To(o.ContainSubstring("turn 3: 0:forward"))
+		})
+	})
+}
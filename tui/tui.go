@@ -0,0 +1,229 @@
+// Package tui shows a game in a terminal. It's a built-in listener for the
+// local game server (see `api.TurnObserver`) which redraws the map as we know
+// it at each turn, along with our ants, the enemy ones, the scoreboard and the
+// AIs' annotations. It only uses ANSI escape codes so it doesn't need any
+// external program.
+package tui
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/bfontaine/antroid/api"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ANSI escape codes
+const (
+	clearScreen = "\x1b[H\x1b[2J"
+	reset       = "\x1b[0m"
+	bold        = "\x1b[1m"
+)
+
+// 256-colors backgrounds of cells, for the cells we remember and the ones we
+// see at this turn. They're close to the colors used in `gui/antroidGUI.py`.
+var cellColors = map[string][2]int{
+	"grass": {22, 46},
+	"sugar": {250, 255},
+	"rock":  {242, 250},
+	"mill":  {208, 226},
+	"water": {17, 21},
+	"meat":  {130, 203},
+}
+
+// colors of the ants
+const (
+	antColor   = 15  // white
+	enemyColor = 196 // red
+	markColor  = 201 // magenta
+)
+
+// the glyphs we use for the ants' headings, indexed by their direction
+var headings = map[api.Direction]string{
+	{X: 0, Y: 1}:   "↑",
+	{X: 1, Y: 1}:   "↗",
+	{X: 1, Y: 0}:   "→",
+	{X: 1, Y: -1}:  "↘",
+	{X: 0, Y: -1}:  "↓",
+	{X: -1, Y: -1}: "↙",
+	{X: -1, Y: 0}:  "←",
+	{X: -1, Y: 1}:  "↖",
+}
+
+// heading returns the glyph of an ant's heading
+func heading(d api.Direction) string {
+	if h, ok := headings[d]; ok {
+		return h
+	}
+	return "o"
+}
+
+func background(c int) string { return fmt.Sprintf("\x1b[48;5;%dm", c) }
+func foreground(c int) string { return fmt.Sprintf("\x1b[38;5;%dm", c) }
+
+// A TUI draws each turn on a terminal
+type TUI struct {
+	w io.Writer
+}
+
+// New returns a pointer on a new TUI which draws on the given writer, usually
+// os.Stdout.
+func New(w io.Writer) *TUI {
+	return &TUI{w: w}
+}
+
+// Observe draws a turn. It implements api.TurnObserver.
+func (t *TUI) Observe(e *api.JournalEntry, m *api.PartialMap) {
+	buf := bufio.NewWriter(t.w)
+
+	buf.WriteString(clearScreen)
+
+	mapLines := drawMap(e, m)
+	panelLines := drawPanel(e)
+
+	for i := 0; i < len(mapLines) || i < len(panelLines); i++ {
+		line := strings.Repeat(" ", m.Width())
+		if i < len(mapLines) {
+			line = mapLines[i]
+		}
+
+		buf.WriteString(line)
+
+		if i < len(panelLines) {
+			buf.WriteString("  ")
+			buf.WriteString(panelLines[i])
+		}
+
+		buf.WriteString("\n")
+	}
+
+	buf.Flush()
+}
+
+// drawMap returns the lines of the map, from the top (the highest Y) to the
+// bottom. Each cell is one character.
+func drawMap(e *api.JournalEntry, m *api.PartialMap) []string {
+	ants := make(map[api.Position]string)
+
+	// the AIs' paths and marks are drawn below the ants
+	for _, a := range e.Annotations {
+		switch a.Kind {
+		case "path":
+			for _, p := range a.Positions {
+				ants[p] = foreground(markColor) + "·"
+			}
+		case "mark":
+			for _, p := range a.Positions {
+				ants[p] = bold + foreground(markColor) + "x"
+			}
+		}
+	}
+
+	for _, a := range e.Enemies {
+		if a.Visible {
+			ants[a.Pos] = bold + foreground(enemyColor) + heading(a.Dir)
+		} else {
+			// enemies we lost sight of are shown at their last known position
+			ants[a.Pos] = foreground(enemyColor) + "?"
+		}
+	}
+
+	// our ants are drawn over the enemy ones
+	for _, a := range e.Ants {
+		ants[a.Pos] = bold + foreground(antColor) + heading(a.Dir)
+	}
+
+	width, height := m.Width(), m.Height()
+	lines := make([]string, 0, height)
+
+	for y := height - 1; y >= 0; y-- {
+		var line strings.Builder
+
+		for x := 0; x < width; x++ {
+			p := api.Position{X: x, Y: y}
+			c := m.Cell(x, y)
+
+			if c != nil {
+				visibility := 0
+				if c.Visibility {
+					visibility = 1
+				}
+				line.WriteString(background(cellColors[c.Content][visibility]))
+			}
+
+			if ant, ok := ants[p]; ok {
+				line.WriteString(ant)
+			} else if c != nil && api.IsFood(c.Content) {
+				line.WriteString(foreground(0) + "*")
+			} else {
+				line.WriteString(" ")
+			}
+
+			line.WriteString(reset)
+		}
+
+		lines = append(lines, line.String())
+	}
+
+	return lines
+}
+
+// drawPanel returns the lines of the side panel: the turn number, our ants'
+// energy and acid levels, the scoreboard and the AIs' logs.
+func drawPanel(e *api.JournalEntry) []string {
+	lines := []string{
+		fmt.Sprintf("%sTurn %d%s (%s)", bold, e.Turn, reset, e.Status),
+		"",
+		bold + "Ants" + reset,
+	}
+
+	for _, a := range e.Ants {
+		lines = append(lines, fmt.Sprintf("%s #%-2d %-10s energy %4d  acid %4d",
+			heading(a.Dir), a.ID, a.Pos, a.Energy, a.Acid))
+	}
+
+	visible := 0
+	for _, a := range e.Enemies {
+		if a.Visible {
+			visible++
+		}
+	}
+
+	lines = append(lines, "",
+		fmt.Sprintf("%sEnemies%s %d visible, %d known", bold, reset,
+			visible, len(e.Enemies)),
+		"",
+		bold+"Scores"+reset)
+
+	var players []string
+	for username := range e.Score {
+		players = append(players, username)
+	}
+
+	sort.Slice(players, func(i, j int) bool {
+		si, sj := e.Score[players[i]], e.Score[players[j]]
+		if si != sj {
+			return si > sj
+		}
+		return players[i] < players[j]
+	})
+
+	for _, username := range players {
+		lines = append(lines, fmt.Sprintf("%-16s %5d", username, e.Score[username]))
+	}
+
+	var logs []string
+	for _, a := range e.Annotations {
+		if a.Kind == "log" {
+			logs = append(logs, fmt.Sprintf("AI %d: %s", a.AI, a.Text))
+		}
+	}
+
+	if len(logs) > 0 {
+		lines = append(lines, "", bold+"Logs"+reset)
+		lines = append(lines, logs...)
+	}
+
+	return lines
+}
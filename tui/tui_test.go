@@ -0,0 +1,101 @@
+package tui
+
+import (
+	"bytes"
+	"github.com/bfontaine/antroid/api"
+	"github.com/franela/goblin"
+	o "github.com/onsi/gomega"
+	"strings"
+	"testing"
+)
+
+func TestTUI(t *testing.T) {
+
+	g := goblin.Goblin(t)
+
+	o.RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	g.Describe("heading", func() {
+		g.It("Should return an arrow for known directions", func() {
+			o.Expect(heading(api.Direction{X: 1, Y: 0})).To(o.Equal("→"))
+			o.Expect(heading(api.Direction{X: -1, Y: 1})).To(o.Equal("↖"))
+		})
+
+		g.It("Should return a default glyph for unknown directions", func() {
+			o.Expect(heading(api.Direction{})).To(o.Equal("o"))
+		})
+	})
+
+	g.Describe("TUI", func() {
+		var buf *bytes.Buffer
+		var entry *api.JournalEntry
+		var m *api.PartialMap
+
+		g.BeforeEach(func() {
+			buf = &bytes.Buffer{}
+
+			m = api.NewPartialMap()
+			m.SetCell(&api.Cell{Pos: api.Position{X: 0, Y: 0}, Content: "grass"})
+			m.SetCell(&api.Cell{Pos: api.Position{X: 1, Y: 1}, Content: "sugar"})
+
+			entry = &api.JournalEntry{
+				Turn:   4,
+				Status: "playing",
+				Score:  map[string]int{"foo": 3, "bar": 7},
+				Ants: []api.AntStatus{{
+					BasicAntStatus: api.BasicAntStatus{
+						Dir: api.Direction{X: 0, Y: 1},
+					},
+					ID:     2,
+					Energy: 42,
+					Acid:   17,
+				}},
+				Enemies: []api.TrackedAnt{{
+					BasicAntStatus: api.BasicAntStatus{
+						Pos: api.Position{X: 1, Y: 0},
+						Dir: api.Direction{X: -1, Y: 0},
+					},
+					Visible: true,
+				}},
+			}
+		})
+
+		g.It("Should clear the screen before drawing", func() {
+			New(buf).Observe(entry, m)
+			o.Expect(strings.HasPrefix(buf.String(), clearScreen)).To(o.BeTrue())
+		})
+
+		g.It("Should draw our ants and the enemy ones", func() {
+			New(buf).Observe(entry, m)
+			o.Expect(buf.String()).To(o.ContainSubstring("↑"))
+			o.Expect(buf.String()).To(o.ContainSubstring(foreground(enemyColor) + "←"))
+		})
+
+		g.It("Should show the energy and acid levels", func() {
+			New(buf).Observe(entry, m)
+			o.Expect(buf.String()).To(o.ContainSubstring("energy   42  acid   17"))
+		})
+
+		g.It("Should sort the scoreboard", func() {
+			New(buf).Observe(entry, m)
+			out := buf.String()
+			o.Expect(strings.Index(out, "bar")).To(o.BeNumerically("<", strings.Index(out, "foo")))
+		})
+
+		g.It("Should draw the AIs' marks and show their logs", func() {
+			entry.Annotations = []api.Annotation{
+				{AI: 0, Kind: "mark", Positions: []api.Position{{X: 1, Y: 1}}},
+				{AI: 1, Kind: "log", Text: "looking for food"},
+			}
+
+			New(buf).Observe(entry, m)
+			o.Expect(buf.String()).To(o.ContainSubstring(foreground(markColor) + "x"))
+			o.Expect(buf.String()).To(o.ContainSubstring("AI 1: looking for food"))
+		})
+
+		g.It("Should draw one line per map row", func() {
+			lines := drawMap(entry, m)
+			o.Expect(lines).To(o.HaveLen(2))
+		})
+	})
+}
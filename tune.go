@@ -0,0 +1,44 @@
+package main
+
+// This file implements the `tune` subcommand, which runs a tuning session
+// described in a JSON file (see `tune/config.go`) and prints the best
+// settings:
+//
+//     antroid tune tune.json
+
+import (
+	"github.com/bfontaine/antroid/api"
+	"github.com/bfontaine/antroid/tune"
+	"os"
+)
+
+// runTuning runs the tuning session described in a configuration file and
+// prints its `best` best trials
+func runTuning(path, defaultPassword string, gs api.GameSpec, best int, debug bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+
+	c, err := tune.ReadConfig(f)
+	f.Close()
+
+	if err != nil {
+		return err
+	}
+
+	t, err := tune.New(c, gs, defaultPassword, os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	t.SetDebug(debug)
+
+	err = t.Run()
+
+	// print the best settings even if we can't save the results
+	os.Stdout.WriteString("\nBest settings:\n")
+	t.PrintBest(best)
+
+	return err
+}
@@ -0,0 +1,251 @@
+// Package tune searches the parameters of AIs which give the best scores. We
+// run batches of single-player games with different values substituted in
+// the AIs' command lines; each set of values is a trial, and we report the
+// trials with the best mean score along with a confidence interval.
+package tune
+
+// This file describes the tuning configuration, which is a JSON file like
+// this one:
+//
+//     {
+//       "search": "random",
+//       "trials": 20,
+//       "games": 5,
+//       "parallel": 4,
+//       "spec": {"turns": 100, "antsPerPlayer": 2},
+//       "login": "tuner",
+//       "results": "tuning",
+//       "ais": ["ai/ant.rb --explore {explore} --radius {radius}"],
+//       "params": {
+//         "explore": {"min": 0, "max": 1, "step": 0.1},
+//         "radius": {"values": ["2", "4", "8"]}
+//       }
+//     }
+//
+// Each `{name}` in the AIs is replaced by the value of the parameter `name`.
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/bfontaine/antroid/api"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// search strategies
+const (
+	// try all combinations of values
+	Grid = "grid"
+	// try random combinations of values
+	Random = "random"
+	// start from random combinations and breed the best ones
+	Evolution = "evolution"
+)
+
+// A Param is a parameter we tune. It's either a list of values or a range.
+type Param struct {
+	// the values it can take, as they're written in the command lines
+	Values []string `json:"values"`
+
+	// the range of values it can take, from Min to Max included. If Step is
+	// set the values are multiples of it from Min, otherwise they're
+	// continuous, which doesn't work with a grid search.
+	Min  float64 `json:"min"`
+	Max  float64 `json:"max"`
+	Step float64 `json:"step"`
+}
+
+// A Config describes a tuning session
+type Config struct {
+	// "grid" (the default), "random" or "evolution"
+	Search string `json:"search"`
+	// the number of trials of a random search. It defaults to 10.
+	Trials int `json:"trials"`
+	// the number of trials per generation of an evolutionary search. It
+	// defaults to 8.
+	Population int `json:"population"`
+	// the number of generations of an evolutionary search. It defaults to 5.
+	Generations int `json:"generations"`
+	// the seed of random and evolutionary searches. A zero seed gives a
+	// different search each time.
+	Seed int64 `json:"seed"`
+
+	// the number of games of each trial. It defaults to 5.
+	Games int `json:"games"`
+	// the number of games played at the same time. It defaults to 1.
+	Parallel int `json:"parallel"`
+
+	// the parameters of the games. Fields left to zero are taken from the
+	// command-line. Games are always private single-player games.
+	Spec api.GameSpec `json:"spec"`
+	// the credentials of the accounts. Each parallel game is played with its
+	// own account named after the login: "tuner-1", "tuner-2", etc. The
+	// password defaults to the one given to the session.
+	Login    string `json:"login"`
+	Password string `json:"password"`
+
+	// the directory in which we store the result of each trial, if any
+	Results string `json:"results"`
+
+	// the AIs' command lines or remote endpoints, with placeholders
+	AIs []string `json:"ais"`
+	// the tuned parameters, indexed by their name
+	Params map[string]*Param `json:"params"`
+}
+
+// ReadConfig reads and checks a tuning configuration
+func ReadConfig(r io.Reader) (*Config, error) {
+	var c Config
+
+	if err := json.NewDecoder(r).Decode(&c); err != nil {
+		return nil, err
+	}
+
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// setDefault sets an integer to a default value if it's not positive
+func setDefault(n *int, value int) {
+	if *n <= 0 {
+		*n = value
+	}
+}
+
+// check sets the default values and checks the configuration
+func (c *Config) check() error {
+	if c.Search == "" {
+		c.Search = Grid
+	}
+
+	if c.Search != Grid && c.Search != Random && c.Search != Evolution {
+		return fmt.Errorf("unknown search strategy %q", c.Search)
+	}
+
+	setDefault(&c.Trials, 10)
+	setDefault(&c.Population, 8)
+	setDefault(&c.Generations, 5)
+	setDefault(&c.Games, 5)
+	setDefault(&c.Parallel, 1)
+
+	if c.Login == "" {
+		return fmt.Errorf("the configuration doesn't have any login")
+	}
+
+	if len(c.AIs) == 0 {
+		return fmt.Errorf("the configuration doesn't have any AI")
+	}
+
+	if len(c.Params) == 0 {
+		return fmt.Errorf("the configuration doesn't have any parameter")
+	}
+
+	ais := strings.Join(c.AIs, " ")
+
+	for _, name := range c.paramNames() {
+		p := c.Params[name]
+
+		if p == nil {
+			return fmt.Errorf("parameter %s is empty", name)
+		}
+
+		if !strings.Contains(ais, "{"+name+"}") {
+			return fmt.Errorf("parameter %s is not used by any AI", name)
+		}
+
+		if len(p.Values) > 0 {
+			continue
+		}
+
+		if p.Min > p.Max || p.Step < 0 {
+			return fmt.Errorf("parameter %s has a bad range", name)
+		}
+
+		if p.Step == 0 && c.Search == Grid {
+			return fmt.Errorf("parameter %s needs values or a step for a grid search", name)
+		}
+	}
+
+	return nil
+}
+
+// paramNames returns the names of the parameters, sorted
+func (c *Config) paramNames() []string {
+	var names []string
+
+	for name := range c.Params {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// spec returns the spec of the games, using `base` for the fields that are
+// not in the configuration
+func (c *Config) spec(base api.GameSpec) api.GameSpec {
+	gs := c.Spec
+	gs.Inherit(base)
+	return gs
+}
+
+// grid returns all the values a parameter can take. It's nil for continuous
+// ranges.
+func (p *Param) grid() []string {
+	if len(p.Values) > 0 {
+		return p.Values
+	}
+
+	if p.Step == 0 {
+		return nil
+	}
+
+	var values []string
+
+	// we count steps instead of adding them to avoid rounding errors
+	for i := 0; ; i++ {
+		v := p.Min + float64(i)*p.Step
+		if v > p.Max+p.Step/1e6 {
+			break
+		}
+		values = append(values, formatValue(p.round(v)))
+	}
+
+	return values
+}
+
+// round clamps a value in the range and rounds it to the nearest step
+func (p *Param) round(v float64) float64 {
+	v = math.Max(p.Min, math.Min(p.Max, v))
+
+	if p.Step > 0 {
+		steps := math.Round((v - p.Min) / p.Step)
+		v = p.Min + steps*p.Step
+
+		// so that we don't print 0.30000000000000004
+		v = math.Round(v*1e9) / 1e9
+
+		if v > p.Max {
+			v -= p.Step
+		}
+	}
+
+	return v
+}
+
+// formatValue formats a numeric value as it's written in the command lines
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// parseValue parses a value of a range
+func parseValue(s string) (float64, error) {
+	return strconv.ParseFloat(s, 64)
+}
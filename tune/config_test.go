@@ -0,0 +1,68 @@
+package tune
+
+import (
+	"github.com/franela/goblin"
+	o "github.com/onsi/gomega"
+	"strings"
+	"testing"
+)
+
+func TestConfig(t *testing.T) {
+
+	g := goblin.Goblin(t)
+
+	o.RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	read := func(s string) (*Config, error) {
+		return ReadConfig(strings.NewReader(s))
+	}
+
+	g.Describe("ReadConfig", func() {
+		g.It("Should set the default values", func() {
+			c, err := read(`{"login": "t", "ais": ["ai/ant.rb {a}"],
+				"params": {"a": {"values": ["1", "2"]}}}`)
+
+			o.Expect(err).To(o.BeNil())
+			o.Expect(c.Search).To(o.Equal(Grid))
+			o.Expect(c.Games).To(o.Equal(5))
+			o.Expect(c.Parallel).To(o.Equal(1))
+		})
+
+		g.It("Should reject unknown search strategies", func() {
+			_, err := read(`{"search": "magic", "login": "t", "ais": ["ai {a}"],
+				"params": {"a": {"values": ["1"]}}}`)
+			o.Expect(err).NotTo(o.BeNil())
+		})
+
+		g.It("Should reject unused parameters", func() {
+			_, err := read(`{"login": "t", "ais": ["ai {a}"],
+				"params": {"a": {"values": ["1"]}, "b": {"values": ["2"]}}}`)
+			o.Expect(err).NotTo(o.BeNil())
+		})
+
+		g.It("Should reject continuous ranges in a grid search", func() {
+			_, err := read(`{"login": "t", "ais": ["ai {a}"],
+				"params": {"a": {"min": 0, "max": 1}}}`)
+			o.Expect(err).NotTo(o.BeNil())
+
+			_, err = read(`{"search": "random", "login": "t", "ais": ["ai {a}"],
+				"params": {"a": {"min": 0, "max": 1}}}`)
+			o.Expect(err).To(o.BeNil())
+		})
+	})
+
+	g.Describe("Param", func() {
+		g.It("Should list the values of a range", func() {
+			p := Param{Min: 0, Max: 0.3, Step: 0.1}
+			o.Expect(p.grid()).To(o.Equal([]string{"0", "0.1", "0.2", "0.3"}))
+		})
+
+		g.It("Should round values in the range", func() {
+			p := Param{Min: 1, Max: 10, Step: 2}
+
+			o.Expect(p.round(4.2)).To(o.Equal(5.0))
+			o.Expect(p.round(42)).To(o.Equal(9.0))
+			o.Expect(p.round(-3)).To(o.Equal(1.0))
+		})
+	})
+}
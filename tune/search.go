@@ -0,0 +1,166 @@
+package tune
+
+// This file describes how we choose the settings of the trials. A grid search
+// tries all combinations, a random search tries random ones, and an
+// evolutionary search starts from a random population and builds each
+// generation from the best half of the previous one: we keep it and add
+// children, which take each value from one of two parents and sometimes
+// mutate it.
+
+import (
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// the probability of mutating each value of a child
+const mutationRate = 0.3
+
+// Settings give a value to each parameter
+type Settings map[string]string
+
+// String returns the settings as "name=value" pairs, sorted by name
+func (s Settings) String() string {
+	var pairs []string
+
+	for name, value := range s {
+		pairs = append(pairs, name+"="+value)
+	}
+
+	sort.Strings(pairs)
+
+	return strings.Join(pairs, " ")
+}
+
+// apply returns the AIs with their placeholders replaced by the settings
+func (s Settings) apply(ais []string) []string {
+	var pairs []string
+
+	for name, value := range s {
+		pairs = append(pairs, "{"+name+"}", value)
+	}
+
+	r := strings.NewReplacer(pairs...)
+
+	applied := make([]string, len(ais))
+	for i, ai := range ais {
+		applied[i] = r.Replace(ai)
+	}
+
+	return applied
+}
+
+// gridSettings returns all the combinations of the parameters' values
+func gridSettings(c *Config) []Settings {
+	all := []Settings{{}}
+
+	for _, name := range c.paramNames() {
+		var next []Settings
+
+		for _, s := range all {
+			for _, value := range c.Params[name].grid() {
+				child := Settings{name: value}
+				for k, v := range s {
+					child[k] = v
+				}
+				next = append(next, child)
+			}
+		}
+
+		all = next
+	}
+
+	return all
+}
+
+// randomValue returns a random value of a parameter
+func (p *Param) randomValue(r *rand.Rand) string {
+	if len(p.Values) > 0 {
+		return p.Values[r.Intn(len(p.Values))]
+	}
+
+	return formatValue(p.round(p.Min + r.Float64()*(p.Max-p.Min)))
+}
+
+// mutate returns a value close to another one: a neighbour in the list of
+// values or a value drawn from a normal distribution around it in the range
+func (p *Param) mutate(r *rand.Rand, value string) string {
+	if len(p.Values) > 0 {
+		i := 0
+		for j, v := range p.Values {
+			if v == value {
+				i = j
+			}
+		}
+
+		if r.Intn(2) == 0 {
+			i--
+		} else {
+			i++
+		}
+
+		if i < 0 || i >= len(p.Values) {
+			return value
+		}
+		return p.Values[i]
+	}
+
+	v, err := parseValue(value)
+	if err != nil {
+		return p.randomValue(r)
+	}
+
+	// a tenth of the range, but at least one step so that it can move
+	sd := (p.Max - p.Min) / 10
+	if sd < p.Step {
+		sd = p.Step
+	}
+
+	return formatValue(p.round(v + r.NormFloat64()*sd))
+}
+
+// randomSettings returns random settings
+func randomSettings(c *Config, r *rand.Rand) Settings {
+	s := make(Settings)
+
+	for _, name := range c.paramNames() {
+		s[name] = c.Params[name].randomValue(r)
+	}
+
+	return s
+}
+
+// breed returns a child of two settings
+func breed(c *Config, r *rand.Rand, a, b Settings) Settings {
+	s := make(Settings)
+
+	for _, name := range c.paramNames() {
+		value := a[name]
+		if r.Intn(2) == 0 {
+			value = b[name]
+		}
+
+		if r.Float64() < mutationRate {
+			value = c.Params[name].mutate(r, value)
+		}
+
+		s[name] = value
+	}
+
+	return s
+}
+
+// nextGeneration returns the settings of the next generation given the ones
+// of the previous one, sorted from the best to the worst
+func nextGeneration(c *Config, r *rand.Rand, ranked []Settings) []Settings {
+	parents := ranked[:(len(ranked)+1)/2]
+
+	next := append([]Settings{}, parents...)
+
+	for len(next) < c.Population {
+		a, b := parents[r.Intn(len(parents))], parents[r.Intn(len(parents))]
+		next = append(next, breed(c, r, a, b))
+	}
+
+	return next
+}
@@ -0,0 +1,69 @@
+package tune
+
+// This file describes the statistics of the trials' scores. The confidence
+// interval of the mean uses Student's t-distribution since we usually have
+// only a few games per trial.
+
+import "math"
+
+// the two-sided 95% quantiles of Student's t-distribution, indexed by the
+// degrees of freedom minus one
+var tQuantiles = []float64{
+	12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+// the quantile of the normal distribution, used with more degrees of freedom
+const zQuantile = 1.960
+
+// A Summary summarizes the scores of a trial
+type Summary struct {
+	// the number of games which didn't fail
+	Games int     `json:"games"`
+	Mean  float64 `json:"mean"`
+	// the sample standard deviation
+	StdDev float64 `json:"stddev"`
+	// the bounds of the 95% confidence interval of the mean. They're equal
+	// to it if we have fewer than two scores.
+	Low  float64 `json:"low"`
+	High float64 `json:"high"`
+}
+
+// summarize returns the summary of some scores
+func summarize(scores []int) Summary {
+	s := Summary{Games: len(scores)}
+
+	if s.Games == 0 {
+		return s
+	}
+
+	for _, score := range scores {
+		s.Mean += float64(score)
+	}
+	s.Mean /= float64(s.Games)
+
+	s.Low, s.High = s.Mean, s.Mean
+
+	if s.Games < 2 {
+		return s
+	}
+
+	for _, score := range scores {
+		d := float64(score) - s.Mean
+		s.StdDev += d * d
+	}
+	s.StdDev = math.Sqrt(s.StdDev / float64(s.Games-1))
+
+	q := zQuantile
+	if df := s.Games - 1; df <= len(tQuantiles) {
+		q = tQuantiles[df-1]
+	}
+
+	margin := q * s.StdDev / math.Sqrt(float64(s.Games))
+
+	s.Low -= margin
+	s.High += margin
+
+	return s
+}
@@ -0,0 +1,304 @@
+package tune
+
+// This file describes the tuner, which plays the games of the trials. Games
+// are played by a pool of workers, each one with its own account so that they
+// can play at the same time. A game which fails, e.g. because the AIs
+// crashed, doesn't count in its trial's score.
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/bfontaine/antroid/api"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// A Trial is a set of games played with the same settings
+type Trial struct {
+	// its number, from 1
+	N int `json:"trial"`
+	// its generation in an evolutionary search, from 1
+	Generation int `json:"generation,omitempty"`
+
+	Settings Settings `json:"settings"`
+	// the AIs with the settings applied
+	AIs []string `json:"ais"`
+
+	// the score of each game which didn't fail
+	Scores []int `json:"scores"`
+	// the error of each game which failed
+	Errors  []string `json:"errors,omitempty"`
+	Summary Summary  `json:"summary"`
+}
+
+// A Tuner runs a tuning session
+type Tuner struct {
+	config *Config
+	// the spec of all games
+	spec api.GameSpec
+	// the password used if the configuration doesn't have one
+	password string
+	debug    bool
+
+	rand   *rand.Rand
+	trials []*Trial
+
+	// where we print the results
+	out io.Writer
+
+	// plays a game with the given AIs and returns our score. `worker` is the
+	// index of the worker which plays it. It's `.playGame` but can be
+	// replaced in tests.
+	play func(worker int, ais []string) (int, error)
+}
+
+// New returns a pointer on a new tuner from its configuration. `base` gives
+// the game parameters which are not in the configuration and `password` the
+// password of the accounts if it doesn't have one. It creates the results
+// directory if there's one.
+func New(c *Config, base api.GameSpec, password string, out io.Writer) (*Tuner, error) {
+	seed := c.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	if c.Password != "" {
+		password = c.Password
+	}
+
+	t := &Tuner{
+		config:   c,
+		spec:     c.spec(base),
+		password: password,
+		rand:     rand.New(rand.NewSource(seed)),
+		out:      out,
+	}
+
+	t.play = t.playGame
+
+	if c.Results != "" {
+		if err := os.MkdirAll(c.Results, 0755); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+// SetDebug enables/disables the debug mode of the players
+func (t *Tuner) SetDebug(debug bool) {
+	t.debug = debug
+}
+
+// Trials returns all the trials we ran, in order
+func (t *Tuner) Trials() []*Trial {
+	return t.trials
+}
+
+// Run runs all the trials of the session. It only fails if it can't save
+// their results.
+func (t *Tuner) Run() error {
+	c := t.config
+
+	switch c.Search {
+	case Random:
+		var settings []Settings
+		for i := 0; i < c.Trials; i++ {
+			settings = append(settings, randomSettings(c, t.rand))
+		}
+
+		_, err := t.evaluate(0, settings)
+		return err
+
+	case Evolution:
+		var population []Settings
+		for i := 0; i < c.Population; i++ {
+			population = append(population, randomSettings(c, t.rand))
+		}
+
+		for gen := 1; gen <= c.Generations; gen++ {
+			fmt.Fprintf(t.out, "Generation %d\n", gen)
+
+			trials, err := t.evaluate(gen, population)
+			if err != nil {
+				return err
+			}
+
+			var ranked []Settings
+			for _, tr := range rank(trials) {
+				ranked = append(ranked, tr.Settings)
+			}
+
+			population = nextGeneration(c, t.rand, ranked)
+		}
+
+		return nil
+	}
+
+	_, err := t.evaluate(0, gridSettings(c))
+	return err
+}
+
+// evaluate runs one trial per settings and returns them
+func (t *Tuner) evaluate(gen int, settings []Settings) ([]*Trial, error) {
+	var trials []*Trial
+
+	for _, s := range settings {
+		tr := &Trial{
+			N:          len(t.trials) + 1,
+			Generation: gen,
+			Settings:   s,
+			AIs:        s.apply(t.config.AIs),
+		}
+
+		t.trials = append(t.trials, tr)
+		trials = append(trials, tr)
+	}
+
+	games := make(chan *Trial)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for w := 0; w < t.config.Parallel; w++ {
+		wg.Add(1)
+
+		go func(worker int) {
+			defer wg.Done()
+
+			for tr := range games {
+				score, err := t.play(worker, tr.AIs)
+
+				mu.Lock()
+				if err != nil {
+					tr.Errors = append(tr.Errors, err.Error())
+				} else {
+					tr.Scores = append(tr.Scores, score)
+				}
+				mu.Unlock()
+			}
+		}(w)
+	}
+
+	for _, tr := range trials {
+		for i := 0; i < t.config.Games; i++ {
+			games <- tr
+		}
+	}
+
+	close(games)
+	wg.Wait()
+
+	for _, tr := range trials {
+		tr.Summary = summarize(tr.Scores)
+
+		fmt.Fprintf(t.out, "Trial %d: %s: %.1f ± %.1f (%d games",
+			tr.N, tr.Settings, tr.Summary.Mean,
+			tr.Summary.High-tr.Summary.Mean, tr.Summary.Games)
+
+		if len(tr.Errors) > 0 {
+			fmt.Fprintf(t.out, ", %d failed", len(tr.Errors))
+		}
+
+		fmt.Fprintln(t.out, ")")
+
+		if err := t.save(tr); err != nil {
+			return trials, err
+		}
+	}
+
+	return trials, nil
+}
+
+// save writes the result of a trial in the results directory, if any
+func (t *Tuner) save(tr *Trial) error {
+	if t.config.Results == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(tr, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(t.config.Results, fmt.Sprintf("trial-%03d.json", tr.N))
+
+	return ioutil.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// rank returns trials sorted from the best mean score to the worst one.
+// Trials without any successful game come last.
+func rank(trials []*Trial) []*Trial {
+	ranked := append([]*Trial{}, trials...)
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		si, sj := ranked[i].Summary, ranked[j].Summary
+		if (si.Games == 0) != (sj.Games == 0) {
+			return sj.Games == 0
+		}
+		return si.Mean > sj.Mean
+	})
+
+	return ranked
+}
+
+// Best returns the `n` best trials, from the best one
+func (t *Tuner) Best(n int) []*Trial {
+	ranked := rank(t.trials)
+
+	if n < len(ranked) {
+		ranked = ranked[:n]
+	}
+
+	return ranked
+}
+
+// PrintBest prints the `n` best trials as a table
+func (t *Tuner) PrintBest(n int) {
+	w := tabwriter.NewWriter(t.out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "#\tTrial\tMean\t95% CI\tGames\tSettings")
+
+	for i, tr := range t.Best(n) {
+		s := tr.Summary
+		fmt.Fprintf(w, "%d\t%d\t%.1f\t[%.1f, %.1f]\t%d\t%s\n", i+1, tr.N,
+			s.Mean, s.Low, s.High, s.Games, tr.Settings)
+	}
+
+	w.Flush()
+}
+
+// playGame plays a single-player private game with the given AIs
+func (t *Tuner) playGame(worker int, ais []string) (int, error) {
+	login := fmt.Sprintf("%s-%d", t.config.Login, worker+1)
+
+	p := api.NewPlayer(login, t.password)
+	p.SetDebug(t.debug)
+
+	defer p.Quit()
+
+	if err := p.AIs.Load(ais...); err != nil {
+		return 0, err
+	}
+
+	m := api.NewMatch(p)
+
+	if err := m.Start(&t.spec); err != nil {
+		return 0, err
+	}
+
+	m.Play()
+
+	if err := m.Errors()[0]; err != nil {
+		return 0, err
+	}
+
+	return m.Scores()[login], nil
+}
@@ -0,0 +1,181 @@
+package tune
+
+import (
+	"bytes"
+	"errors"
+	"github.com/bfontaine/antroid/api"
+	"github.com/franela/goblin"
+	o "github.com/onsi/gomega"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestTune(t *testing.T) {
+
+	g := goblin.Goblin(t)
+
+	o.RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	config := func(search string) *Config {
+		c := &Config{
+			Search: search,
+			Seed:   42,
+			Login:  "t",
+			AIs:    []string{"ai --a {a} --b {b}"},
+			Params: map[string]*Param{
+				"a": {Values: []string{"1", "2", "3"}},
+				"b": {Min: 0, Max: 10, Step: 5},
+			},
+		}
+
+		o.Expect(c.check()).To(o.BeNil())
+
+		return c
+	}
+
+	// newTuner returns a tuner whose games score a*b
+	newTuner := func(c *Config) (*Tuner, *bytes.Buffer) {
+		var out bytes.Buffer
+
+		tu, err := New(c, api.GameSpec{}, "", &out)
+		o.Expect(err).To(o.BeNil())
+
+		tu.play = func(worker int, ais []string) (int, error) {
+			var a, b int
+			words := strings.Fields(ais[0])
+			a, _ = strconv.Atoi(words[2])
+			b, _ = strconv.Atoi(words[4])
+			return a * b, nil
+		}
+
+		return tu, &out
+	}
+
+	g.Describe("Settings", func() {
+		g.It("Should replace the placeholders", func() {
+			s := Settings{"a": "1", "b": "x"}
+
+			o.Expect(s.apply([]string{"ai {a} {b}", "{a}{a}"})).To(
+				o.Equal([]string{"ai 1 x", "11"}))
+			o.Expect(s.String()).To(o.Equal("a=1 b=x"))
+		})
+
+		g.It("Should list all combinations in a grid", func() {
+			o.Expect(gridSettings(config(Grid))).To(o.HaveLen(9))
+		})
+
+		g.It("Should only give values of the parameters", func() {
+			c := config(Evolution)
+			r := rand.New(rand.NewSource(1))
+
+			for i := 0; i < 100; i++ {
+				s := breed(c, r, randomSettings(c, r), randomSettings(c, r))
+
+				o.Expect(c.Params["a"].Values).To(o.ContainElement(s["a"]))
+				o.Expect(c.Params["b"].grid()).To(o.ContainElement(s["b"]))
+			}
+		})
+
+		g.It("Should keep the best half of a generation", func() {
+			c := config(Evolution)
+			c.Population = 4
+
+			ranked := []Settings{{"a": "3"}, {"a": "2"}, {"a": "1"}, {"a": "1"}}
+			next := nextGeneration(c, rand.New(rand.NewSource(1)), ranked)
+
+			o.Expect(next).To(o.HaveLen(4))
+			o.Expect(next[:2]).To(o.Equal(ranked[:2]))
+		})
+	})
+
+	g.Describe("summarize", func() {
+		g.It("Should compute a confidence interval", func() {
+			s := summarize([]int{1, 2, 3})
+
+			o.Expect(s.Games).To(o.Equal(3))
+			o.Expect(s.Mean).To(o.Equal(2.0))
+			o.Expect(s.StdDev).To(o.Equal(1.0))
+			o.Expect(s.Low).To(o.BeNumerically("~", 2-4.303/1.732, 1e-3))
+			o.Expect(s.High).To(o.BeNumerically("~", 2+4.303/1.732, 1e-3))
+		})
+
+		g.It("Should work with less than two scores", func() {
+			o.Expect(summarize(nil).Games).To(o.Equal(0))
+			o.Expect(summarize([]int{4})).To(o.Equal(Summary{
+				Games: 1, Mean: 4, Low: 4, High: 4}))
+		})
+	})
+
+	g.Describe("Tuner", func() {
+		g.It("Should find the best settings with a grid search", func() {
+			c := config(Grid)
+			c.Games = 2
+			c.Parallel = 3
+
+			tu, out := newTuner(c)
+			o.Expect(tu.Run()).To(o.BeNil())
+
+			o.Expect(tu.Trials()).To(o.HaveLen(9))
+
+			best := tu.Best(1)[0]
+			o.Expect(best.Settings).To(o.Equal(Settings{"a": "3", "b": "10"}))
+			o.Expect(best.Scores).To(o.Equal([]int{30, 30}))
+
+			tu.PrintBest(3)
+			o.Expect(out.String()).To(o.ContainSubstring("a=3 b=10"))
+		})
+
+		g.It("Should run the given number of random trials", func() {
+			c := config(Random)
+			c.Trials = 4
+
+			tu, _ := newTuner(c)
+			o.Expect(tu.Run()).To(o.BeNil())
+			o.Expect(tu.Trials()).To(o.HaveLen(4))
+		})
+
+		g.It("Should run all generations of an evolutionary search", func() {
+			c := config(Evolution)
+			c.Population = 4
+			c.Generations = 3
+
+			tu, out := newTuner(c)
+			o.Expect(tu.Run()).To(o.BeNil())
+
+			o.Expect(tu.Trials()).To(o.HaveLen(12))
+			o.Expect(tu.Trials()[11].Generation).To(o.Equal(3))
+			o.Expect(out.String()).To(o.ContainSubstring("Generation 3"))
+		})
+
+		g.It("Should not count failed games", func() {
+			c := config(Grid)
+			c.Games = 4
+			c.Parallel = 2
+
+			tu, _ := newTuner(c)
+
+			var mu sync.Mutex
+			n := 0
+
+			tu.play = func(worker int, ais []string) (int, error) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				n++
+				if n%2 == 0 {
+					return 0, errors.New("crash")
+				}
+				return 1, nil
+			}
+
+			o.Expect(tu.Run()).To(o.BeNil())
+
+			for _, tr := range tu.Trials() {
+				o.Expect(tr.Summary.Games + len(tr.Errors)).To(o.Equal(4))
+			}
+		})
+	})
+}
@@ -0,0 +1,74 @@
+package main
+
+// This file implements the `watch` subcommand, which follows a game until
+// it's over, printing its turn, its status changes and its scoreboard:
+//
+//     antroid watch 42 --hook 'notify-send "game $ANTROID_GAME is $ANTROID_STATUS"'
+//
+// The hook is run with `sh -c` each time the status of the game changes, with
+// the game in $ANTROID_GAME, its status in $ANTROID_STATUS, the previous one
+// in $ANTROID_PREVIOUS_STATUS and the turn in $ANTROID_TURN.
+
+import (
+	"fmt"
+	"github.com/bfontaine/antroid/api"
+	"os"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+// runHook runs the hook command of a status change
+func runHook(hook string, e *api.WatchEvent) error {
+	cmd := exec.Command("sh", "-c", hook)
+
+	cmd.Env = append(os.Environ(),
+		"ANTROID_GAME="+string(e.Status.Identifier),
+		"ANTROID_STATUS="+e.Status.Status,
+		"ANTROID_PREVIOUS_STATUS="+e.PreviousStatus,
+		"ANTROID_TURN="+strconv.Itoa(e.Status.Turn),
+	)
+
+	// don't mix its output with ours if we print JSON
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
+
+// watchGame polls a game until it's over and prints its final ranking. The
+// interval between two polls depends on the game's pace if it's 0.
+func watchGame(cl *api.Client, id api.GameID, interval time.Duration, hook string) error {
+	w := api.NewWatcher(cl, id)
+	w.Interval = interval
+
+	var last *api.WatchEvent
+
+	err := w.Watch(func(e *api.WatchEvent) error {
+		if e.Transition() && hook != "" {
+			// a broken hook shouldn't stop us from watching the game
+			if err := runHook(hook, e); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: the hook failed: %v\n", err)
+			}
+		}
+
+		// we print only what's new
+		changed := last == nil || e.Transition() || e.Status.Turn != last.Status.Turn
+		for _, l := range e.Scoreboard {
+			changed = changed || l.Delta != 0
+		}
+
+		last = e
+
+		if !changed {
+			return nil
+		}
+		return printWatchEvent(e)
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return printRanking(last)
+}
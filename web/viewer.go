@@ -0,0 +1,129 @@
+// Package web serves a game viewer over HTTP. It's a built-in listener for the
+// local game server (see `api.TurnObserver`) which keeps all the turns in
+// memory and streams them to browsers using Server-Sent Events, so several
+// people can watch the same game.
+//
+// Turns are sent as journal entries (see `api/journal.go`): they only contain
+// the cells that changed since the previous turn, and the viewer replays them
+// to rebuild the map at any turn.
+package web
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"github.com/bfontaine/antroid/api"
+	"net/http"
+	"sync"
+)
+
+// the HTML/JS viewer
+//
+//go:embed viewer.html
+var viewerHTML []byte
+
+// A Viewer keeps the history of a game and serves it over HTTP. It
+// implements both api.TurnObserver and http.Handler.
+type Viewer struct {
+	mux *http.ServeMux
+
+	// guards everything below
+	mu sync.Mutex
+	// all the turns, as JSON-encoded journal entries
+	history [][]byte
+	// this channel is closed and replaced each time we add a turn, to wake
+	// up the streams.
+	updated chan struct{}
+}
+
+// NewViewer returns a pointer on a new Viewer with an empty history
+func NewViewer() *Viewer {
+	v := &Viewer{
+		mux:     http.NewServeMux(),
+		updated: make(chan struct{}),
+	}
+
+	v.mux.HandleFunc("/", v.serveViewer)
+	v.mux.HandleFunc("/events", v.serveEvents)
+
+	return v
+}
+
+// Observe adds a turn to the history and sends it to all the browsers. It
+// implements api.TurnObserver.
+func (v *Viewer) Observe(e *api.JournalEntry, m *api.PartialMap) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.history = append(v.history, data)
+
+	close(v.updated)
+	v.updated = make(chan struct{})
+}
+
+// Len returns the number of turns in the history
+func (v *Viewer) Len() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	return len(v.history)
+}
+
+// ServeHTTP implements http.Handler
+func (v *Viewer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	v.mux.ServeHTTP(w, r)
+}
+
+// serveViewer serves the HTML/JS viewer
+func (v *Viewer) serveViewer(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(viewerHTML)
+}
+
+// serveEvents streams the turns as Server-Sent Events. It first sends the
+// whole history, then each new turn as it comes, until the browser closes
+// the connection.
+func (v *Viewer) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	sent := 0
+
+	for {
+		v.mu.Lock()
+		turns := v.history[sent:]
+		updated := v.updated
+		v.mu.Unlock()
+
+		for _, data := range turns {
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+				return
+			}
+		}
+
+		sent += len(turns)
+		flusher.Flush()
+
+		select {
+		case <-updated:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
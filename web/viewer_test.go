@@ -0,0 +1,94 @@
+package web
+
+import (
+	"bufio"
+	"context"
+	"github.com/bfontaine/antroid/api"
+	"github.com/franela/goblin"
+	o "github.com/onsi/gomega"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestViewer(t *testing.T) {
+
+	g := goblin.Goblin(t)
+
+	o.RegisterFailHandler(func(m string, _ ...int) { g.Fail(m) })
+
+	g.Describe("Viewer", func() {
+		var v *Viewer
+		var srv *httptest.Server
+
+		g.BeforeEach(func() {
+			v = NewViewer()
+			srv = httptest.NewServer(v)
+		})
+
+		g.AfterEach(func() {
+			srv.Close()
+		})
+
+		g.It("Should start with an empty history", func() {
+			o.Expect(v.Len()).To(o.Equal(0))
+		})
+
+		g.It("Should add each observed turn to the history", func() {
+			v.Observe(&api.JournalEntry{Turn: 1}, api.NewPartialMap())
+			v.Observe(&api.JournalEntry{Turn: 2}, api.NewPartialMap())
+
+			o.Expect(v.Len()).To(o.Equal(2))
+		})
+
+		g.It("Should serve the viewer on /", func() {
+			resp, err := http.Get(srv.URL + "/")
+			o.Expect(err).To(o.BeNil())
+			defer resp.Body.Close()
+
+			o.Expect(resp.StatusCode).To(o.Equal(http.StatusOK))
+			o.Expect(resp.Header.Get("Content-Type")).To(o.HavePrefix("text/html"))
+		})
+
+		g.It("Should return a 404 on unknown paths", func() {
+			resp, err := http.Get(srv.URL + "/foo")
+			o.Expect(err).To(o.BeNil())
+			defer resp.Body.Close()
+
+			o.Expect(resp.StatusCode).To(o.Equal(http.StatusNotFound))
+		})
+
+		g.It("Should stream the history then the new turns", func() {
+			v.Observe(&api.JournalEntry{Turn: 1}, api.NewPartialMap())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			req, _ := http.NewRequest("GET", srv.URL+"/events", nil)
+			resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+			o.Expect(err).To(o.BeNil())
+			defer resp.Body.Close()
+
+			o.Expect(resp.Header.Get("Content-Type")).To(o.Equal("text/event-stream"))
+
+			r := bufio.NewReader(resp.Body)
+
+			line, err := r.ReadString('\n')
+			o.Expect(err).To(o.BeNil())
+			o.Expect(line).To(o.HavePrefix("data: {"))
+			o.Expect(line).To(o.ContainSubstring(`"Turn":1`))
+
+			// the empty line which ends the event
+			line, _ = r.ReadString('\n')
+			o.Expect(line).To(o.Equal("\n"))
+
+			v.Observe(&api.JournalEntry{Turn: 2}, api.NewPartialMap())
+
+			line, err = r.ReadString('\n')
+			o.Expect(err).To(o.BeNil())
+			o.Expect(strings.HasPrefix(line, "data: ")).To(o.BeTrue())
+			o.Expect(line).To(o.ContainSubstring(`"Turn":2`))
+		})
+	})
+}